@@ -0,0 +1,472 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/KEINOS/go-deepl/deepl"
+)
+
+// Output formats of the commands.
+const (
+	formatText = "text"
+	formatJSON = "json"
+)
+
+// ----------------------------------------------------------------------------
+//  translate
+// ----------------------------------------------------------------------------
+
+// runTranslate translates the texts in the args or the stdin.
+func (a *app) runTranslate(args []string) error {
+	flags := a.newFlagSet("translate", "[flags] [text ...]")
+
+	from := flags.String("from", "", "source language. Such as EN. Detected if empty")
+	target := flags.String("to", "", "target language. Such as DE and EN-US (required)")
+	formality := flags.String("formality", "", "formality. Such as more, less, prefer_more and prefer_less")
+	glossaryID := flags.String("glossary", "", "glossary ID to use. Requires --from")
+	format := flags.String("format", formatText, "output format. text or json")
+
+	if done, err := parseFlags(flags, args); done || err != nil {
+		return err
+	}
+
+	if *target == "" {
+		return usageErr("--to is required")
+	}
+
+	if err := validateFormat(*format); err != nil {
+		return err
+	}
+
+	texts, err := a.textsFromArgs(flags.Args())
+	if err != nil {
+		return err
+	}
+
+	cli, err := a.newClient()
+	if err != nil {
+		return err
+	}
+
+	resp, err := cli.Translate(context.Background(), texts, &deepl.TranslateOptions{
+		SourceLang: *from,
+		TargetLang: *target,
+		Formality:  deepl.Formality(*formality),
+		GlossaryID: *glossaryID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if *format == formatJSON {
+		return a.printJSON(resp)
+	}
+
+	for _, trans := range resp.Translations {
+		fmt.Fprintln(a.stdout, trans.Text)
+	}
+
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+//  usage
+// ----------------------------------------------------------------------------
+
+// runUsage prints the usage of the account.
+func (a *app) runUsage(args []string) error {
+	flags := a.newFlagSet("usage", "[flags]")
+
+	format := flags.String("format", formatText, "output format. text or json")
+
+	if done, err := parseFlags(flags, args); done || err != nil {
+		return err
+	}
+
+	if err := validateFormat(*format); err != nil {
+		return err
+	}
+
+	cli, err := a.newClient()
+	if err != nil {
+		return err
+	}
+
+	status, err := cli.GetAccountStatus(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if *format == formatJSON {
+		return a.printJSON(status)
+	}
+
+	writer := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+
+	for _, row := range []struct {
+		usage deepl.Usage
+		name  string
+		show  bool
+	}{
+		{name: "Characters", usage: status.Characters(), show: true},
+		{name: "API key characters", usage: status.APIKeyCharacters(), show: status.APIKeyCharacterCount > 0},
+		{name: "Documents", usage: status.Documents(), show: status.DocumentLimit > 0},
+		{name: "Team documents", usage: status.TeamDocuments(), show: status.TeamDocumentLimit > 0},
+	} {
+		if !row.show {
+			continue
+		}
+
+		if row.usage.Unlimited() {
+			fmt.Fprintf(writer, "%s:\t%d\t(no limit)\n", row.name, row.usage.Count)
+
+			continue
+		}
+
+		fmt.Fprintf(writer, "%s:\t%d / %d\t(%.2f%%)\n",
+			row.name, row.usage.Count, row.usage.Limit, row.usage.PercentUsed())
+	}
+
+	return deepl.WrapIfErr(writer.Flush(), "failed to write output")
+}
+
+// ----------------------------------------------------------------------------
+//  languages
+// ----------------------------------------------------------------------------
+
+// runLanguages prints the supported languages.
+func (a *app) runLanguages(args []string) error {
+	flags := a.newFlagSet("languages", "[flags]")
+
+	langType := flags.String("type", string(deepl.LanguageTypeTarget), "language type. source or target")
+	format := flags.String("format", formatText, "output format. text or json")
+
+	if done, err := parseFlags(flags, args); done || err != nil {
+		return err
+	}
+
+	if err := validateFormat(*format); err != nil {
+		return err
+	}
+
+	if *langType != string(deepl.LanguageTypeSource) && *langType != string(deepl.LanguageTypeTarget) {
+		return usageErr("--type must be source or target: %q", *langType)
+	}
+
+	cli, err := a.newClient()
+	if err != nil {
+		return err
+	}
+
+	languages, err := cli.GetLanguages(context.Background(), deepl.LanguageType(*langType))
+	if err != nil {
+		return err
+	}
+
+	if *format == formatJSON {
+		return a.printJSON(languages)
+	}
+
+	writer := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+
+	for _, language := range languages {
+		formality := ""
+		if language.SupportsFormality {
+			formality = "formality"
+		}
+
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", language.Code, language.Name, formality)
+	}
+
+	return deepl.WrapIfErr(writer.Flush(), "failed to write output")
+}
+
+// ----------------------------------------------------------------------------
+//  glossary
+// ----------------------------------------------------------------------------
+
+// runGlossary runs the glossary subcommands.
+func (a *app) runGlossary(args []string) error {
+	subcommands := map[string]func(args []string) error{
+		"create": a.runGlossaryCreate,
+		"list":   a.runGlossaryList,
+		"show":   a.runGlossaryShow,
+		"delete": a.runGlossaryDelete,
+	}
+
+	if len(args) == 0 {
+		return usageErr("glossary requires a subcommand: create, list, show or delete")
+	}
+
+	run, ok := subcommands[args[0]]
+	if !ok {
+		return usageErr("unknown glossary subcommand: %s", args[0])
+	}
+
+	return run(args[1:])
+}
+
+func (a *app) runGlossaryCreate(args []string) error {
+	flags := a.newFlagSet("glossary create", "[flags] <entries file>")
+
+	name := flags.String("name", "", "name of the glossary (required)")
+	from := flags.String("from", "", "source language (required)")
+	target := flags.String("to", "", "target language (required)")
+	entriesFormat := flags.String("entries-format", "", "format of the entries file. tsv or csv. Detected by the extension if empty")
+
+	if done, err := parseFlags(flags, args); done || err != nil {
+		return err
+	}
+
+	if *name == "" || *from == "" || *target == "" || flags.NArg() != 1 {
+		return usageErr("--name, --from, --to and an entries file are required")
+	}
+
+	pathEntries := flags.Arg(0)
+
+	if *entriesFormat == "" {
+		*entriesFormat = string(deepl.GlossaryEntriesTSV)
+		if strings.EqualFold(filepath.Ext(pathEntries), ".csv") {
+			*entriesFormat = string(deepl.GlossaryEntriesCSV)
+		}
+	}
+
+	data, err := os.ReadFile(pathEntries)
+	if err != nil {
+		return deepl.WrapIfErr(err, "failed to read entries file")
+	}
+
+	cli, err := a.newClient()
+	if err != nil {
+		return err
+	}
+
+	glossary, err := cli.CreateGlossaryFromData(context.Background(), *name, *from, *target,
+		string(data), deepl.GlossaryEntriesFormat(*entriesFormat))
+	if err != nil {
+		return err
+	}
+
+	return a.printJSON(glossary)
+}
+
+func (a *app) runGlossaryList(args []string) error {
+	flags := a.newFlagSet("glossary list", "[flags]")
+
+	format := flags.String("format", formatText, "output format. text or json")
+
+	if done, err := parseFlags(flags, args); done || err != nil {
+		return err
+	}
+
+	if err := validateFormat(*format); err != nil {
+		return err
+	}
+
+	cli, err := a.newClient()
+	if err != nil {
+		return err
+	}
+
+	glossaries, err := cli.ListGlossaries(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if *format == formatJSON {
+		return a.printJSON(glossaries)
+	}
+
+	writer := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+
+	for _, glossary := range glossaries {
+		fmt.Fprintf(writer, "%s\t%s\t%s>%s\t%d entries\n",
+			glossary.GlossaryID, glossary.Name, glossary.SourceLang, glossary.TargetLang, glossary.EntryCount)
+	}
+
+	return deepl.WrapIfErr(writer.Flush(), "failed to write output")
+}
+
+func (a *app) runGlossaryShow(args []string) error {
+	flags := a.newFlagSet("glossary show", "[flags] <glossary ID>")
+
+	entries := flags.Bool("entries", false, "print the entries in TSV instead of the meta information")
+
+	if done, err := parseFlags(flags, args); done || err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return usageErr("a glossary ID is required")
+	}
+
+	cli, err := a.newClient()
+	if err != nil {
+		return err
+	}
+
+	if !*entries {
+		glossary, err := cli.GetGlossary(context.Background(), flags.Arg(0))
+		if err != nil {
+			return err
+		}
+
+		return a.printJSON(glossary)
+	}
+
+	glossaryEntries, err := cli.GetGlossaryEntries(context.Background(), flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	data, err := glossaryEntries.Encode(deepl.GlossaryEntriesTSV)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprint(a.stdout, data)
+
+	return nil
+}
+
+func (a *app) runGlossaryDelete(args []string) error {
+	flags := a.newFlagSet("glossary delete", "<glossary ID>")
+
+	if done, err := parseFlags(flags, args); done || err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return usageErr("a glossary ID is required")
+	}
+
+	cli, err := a.newClient()
+	if err != nil {
+		return err
+	}
+
+	return cli.DeleteGlossary(context.Background(), flags.Arg(0))
+}
+
+// ----------------------------------------------------------------------------
+//  document
+// ----------------------------------------------------------------------------
+
+// runDocument translates a document file.
+func (a *app) runDocument(args []string) error {
+	flags := a.newFlagSet("document", "[flags] <file>")
+
+	from := flags.String("from", "", "source language. Detected if empty")
+	target := flags.String("to", "", "target language (required)")
+	formality := flags.String("formality", "", "formality. Such as more and less")
+	glossaryID := flags.String("glossary", "", "glossary ID to use. Requires --from")
+	output := flags.String("output", "", "path of the translated file. Defaults to <name>.<TO><ext>")
+
+	if done, err := parseFlags(flags, args); done || err != nil {
+		return err
+	}
+
+	if *target == "" || flags.NArg() != 1 {
+		return usageErr("--to and a file are required")
+	}
+
+	pathIn := flags.Arg(0)
+
+	pathOut := *output
+	if pathOut == "" {
+		ext := filepath.Ext(pathIn)
+		pathOut = strings.TrimSuffix(pathIn, ext) + "." + strings.ToUpper(*target) + ext
+	}
+
+	fileIn, err := os.Open(pathIn)
+	if err != nil {
+		return deepl.WrapIfErr(err, "failed to open the document")
+	}
+
+	defer fileIn.Close()
+
+	cli, err := a.newClient()
+	if err != nil {
+		return err
+	}
+
+	fileOut, err := os.Create(pathOut)
+	if err != nil {
+		return deepl.WrapIfErr(err, "failed to create the output file")
+	}
+
+	defer fileOut.Close()
+
+	status, err := cli.TranslateDocument(context.Background(), fileIn, filepath.Base(pathIn), fileOut,
+		&deepl.DocumentOptions{
+			SourceLang: *from,
+			TargetLang: *target,
+			Formality:  deepl.Formality(*formality),
+			GlossaryID: *glossaryID,
+		})
+	if err != nil {
+		fileOut.Close()
+		os.Remove(pathOut)
+
+		return err
+	}
+
+	fmt.Fprintf(a.stdout, "%s (%d characters billed)\n", pathOut, status.BilledCharacters)
+
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+//  Helpers
+// ----------------------------------------------------------------------------
+
+// newFlagSet returns a new flag set of the command that writes to the stderr.
+func (a *app) newFlagSet(name string, argsUsage string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(a.stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(a.stderr, "Usage: deepl %s %s\n\nFlags:\n", name, argsUsage)
+		flags.PrintDefaults()
+	}
+
+	return flags
+}
+
+// parseFlags parses the args. It returns true as done if the help is requested.
+func parseFlags(flags *flag.FlagSet, args []string) (bool, error) {
+	err := flags.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return true, nil
+	}
+
+	if err != nil {
+		return true, usageErr("%v", err)
+	}
+
+	return false, nil
+}
+
+// validateFormat returns an error if the output format is not supported.
+func validateFormat(format string) error {
+	if format != formatText && format != formatJSON {
+		return usageErr("--format must be text or json: %q", format)
+	}
+
+	return nil
+}
+
+// printJSON prints the value as an indented JSON.
+func (a *app) printJSON(value interface{}) error {
+	encoder := json.NewEncoder(a.stdout)
+	encoder.SetIndent("", "  ")
+
+	return deepl.WrapIfErr(encoder.Encode(value), "failed to write output")
+}
@@ -0,0 +1,222 @@
+/*
+Command deepl is a command line tool to use DeepL API.
+
+The API key is read from the environment variable "DEEPL_API_KEY". The API
+type (Free or Pro) is detected from the key.
+
+Usage:
+
+	deepl <command> [flags] [args]
+
+Commands:
+
+	translate  Translate the texts in the args or the stdin
+	usage      Show the usage of the account
+	languages  List the supported languages
+	glossary   Manage the glossaries (create, list, show, delete)
+	document   Translate a document file
+
+Exit codes:
+
+	0  Success
+	1  Error
+	2  Invalid usage
+	3  Authentication failure
+	4  Quota exceeded
+	5  Transient error. Such as too many requests and server errors
+*/
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"runtime/debug"
+	"sort"
+	"strings"
+
+	"github.com/KEINOS/go-deepl/deepl"
+)
+
+// Exit codes of the command.
+const (
+	ExitOK = iota
+	ExitError
+	ExitUsage
+	ExitAuth
+	ExitQuota
+	ExitTransient
+)
+
+// errUsage is the error of the invalid usage of the command.
+var errUsage = errors.New("invalid usage")
+
+// version is the version of the command. It is set by the linker flag on
+// release. Such as "-ldflags '-X main.version=1.2.3'". If empty, the module
+// version of the build info is used. Which is set by "go install ...@version".
+var version = ""
+
+// app holds the I/O of the command and the constructor of the client.
+type app struct {
+	stdin     io.Reader
+	stdout    io.Writer
+	stderr    io.Writer
+	newClient func() (*deepl.Client, error)
+}
+
+// command is a subcommand of the app.
+type command struct {
+	run     func(a *app, args []string) error
+	summary string
+}
+
+// commands are the subcommands of the app.
+var commands = map[string]command{
+	"translate": {run: (*app).runTranslate, summary: "Translate the texts in the args or the stdin"},
+	"usage":     {run: (*app).runUsage, summary: "Show the usage of the account"},
+	"languages": {run: (*app).runLanguages, summary: "List the supported languages"},
+	"glossary":  {run: (*app).runGlossary, summary: "Manage the glossaries (create, list, show, delete)"},
+	"document":  {run: (*app).runDocument, summary: "Translate a document file"},
+}
+
+func main() {
+	// The positions in the source code are noise for the users of the command
+	deepl.AppendErrPos = false
+
+	cli := &app{
+		stdin:     os.Stdin,
+		stdout:    os.Stdout,
+		stderr:    os.Stderr,
+		newClient: newClient,
+	}
+
+	os.Exit(cli.run(os.Args[1:]))
+}
+
+// newClient returns a new client with the API key in the environment variable.
+// The API type is detected from the key.
+func newClient() (*deepl.Client, error) {
+	if os.Getenv(deepl.NameEnvKeyAPI) == "" {
+		return nil, deepl.WrapIfErr(deepl.ErrUnauthorized,
+			"the environment variable %s is not set", deepl.NameEnvKeyAPI)
+	}
+
+	return deepl.New(deepl.APIAuto, log.New(os.Stderr, "", 0),
+		deepl.WithUserAgent(userAgent()),
+		deepl.WithRetryPolicy(deepl.DefaultRetryPolicy()),
+	)
+}
+
+// userAgent returns the user agent of the command. Such as "go-deepl-cli/1.2.3".
+// The version is "devel" if unknown.
+func userAgent() string {
+	ver := version
+
+	if ver == "" {
+		ver = "devel"
+
+		if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+			ver = info.Main.Version
+		}
+	}
+
+	return "go-deepl-cli/" + strings.TrimPrefix(ver, "v")
+}
+
+// run runs the subcommand in the args and returns the exit code.
+func (a *app) run(args []string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		a.printUsage()
+
+		if len(args) == 0 {
+			return ExitUsage
+		}
+
+		return ExitOK
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(a.stderr, "unknown command: %s\n\n", args[0])
+		a.printUsage()
+
+		return ExitUsage
+	}
+
+	err := cmd.run(a, args[1:])
+	if err != nil {
+		fmt.Fprintf(a.stderr, "deepl %s: %v\n", args[0], err)
+	}
+
+	return exitCode(err)
+}
+
+// printUsage prints the usage of the app.
+func (a *app) printUsage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	fmt.Fprintln(a.stderr, "Usage: deepl <command> [flags] [args]")
+	fmt.Fprintln(a.stderr)
+	fmt.Fprintln(a.stderr, "Commands:")
+
+	for _, name := range names {
+		fmt.Fprintf(a.stderr, "  %-10s %s\n", name, commands[name].summary)
+	}
+
+	fmt.Fprintln(a.stderr)
+	fmt.Fprintf(a.stderr, "The API key is read from the environment variable %s.\n", deepl.NameEnvKeyAPI)
+	fmt.Fprintln(a.stderr, "Run 'deepl <command> -h' for the flags of the command.")
+}
+
+// exitCode returns the exit code of the error.
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, errUsage):
+		return ExitUsage
+	case errors.Is(err, deepl.ErrForbidden),
+		errors.Is(err, deepl.ErrUnauthorized),
+		errors.Is(err, deepl.ErrAPIKeyMismatch):
+		return ExitAuth
+	case errors.Is(err, deepl.ErrQuotaExceeded),
+		errors.Is(err, deepl.ErrBudgetExceeded):
+		return ExitQuota
+	case deepl.IsRetryable(err):
+		return ExitTransient
+	}
+
+	return ExitError
+}
+
+// usageErr returns an error of the invalid usage with the message.
+func usageErr(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", errUsage, fmt.Sprintf(format, args...))
+}
+
+// textsFromArgs returns the args as the texts. If no args, the stdin is read as
+// a single text.
+func (a *app) textsFromArgs(args []string) ([]string, error) {
+	if len(args) != 0 {
+		return args, nil
+	}
+
+	data, err := io.ReadAll(a.stdin)
+	if err != nil {
+		return nil, deepl.WrapIfErr(err, "failed to read stdin")
+	}
+
+	text := strings.TrimRight(string(data), "\r\n")
+	if text == "" {
+		return nil, usageErr("no text to translate in the args or the stdin")
+	}
+
+	return []string{text}, nil
+}
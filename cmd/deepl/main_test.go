@@ -0,0 +1,261 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/KEINOS/go-deepl/deepl"
+	"github.com/KEINOS/go-deepl/deepl/deepltest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestApp returns a new app with the client of a fake server, the server and
+// the buffers of the stdout and the stderr.
+func newTestApp(t *testing.T, stdin string) (*app, *deepltest.Server, *bytes.Buffer, *bytes.Buffer) {
+	t.Helper()
+
+	srv := deepltest.NewServer()
+	t.Cleanup(srv.Close)
+
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+
+	return &app{
+		stdin:  strings.NewReader(stdin),
+		stdout: stdout,
+		stderr: stderr,
+		newClient: func() (*deepl.Client, error) {
+			// The key is fixed to test the auth failure by changing the server's key
+			return srv.NewClient(deepl.WithAPIKey(deepltest.AuthKeyDefault))
+		},
+	}, srv, stdout, stderr
+}
+
+// ----------------------------------------------------------------------------
+//  Commands
+// ----------------------------------------------------------------------------
+
+func TestApp_run_translate(t *testing.T) {
+	t.Parallel()
+
+	cli, srv, stdout, stderr := newTestApp(t, "")
+
+	exitCode := cli.run([]string{"translate", "--from", "EN", "--to", "DE", "--formality", "more", "Hello", "World"})
+
+	require.Equal(t, ExitOK, exitCode, stderr.String())
+	require.Equal(t, "[DE] Hello\n[DE] World\n", stdout.String())
+
+	requests := srv.Requests()
+	require.Len(t, requests, 1)
+	assert.Equal(t, "more", requests[0].Params.Get("formality"))
+	assert.Equal(t, "EN", requests[0].Params.Get("source_lang"))
+}
+
+func TestApp_run_translate_stdin_json(t *testing.T) {
+	t.Parallel()
+
+	cli, _, stdout, stderr := newTestApp(t, "Hello\nWorld\n")
+
+	exitCode := cli.run([]string{"translate", "--to", "JA", "--format", "json"})
+	require.Equal(t, ExitOK, exitCode, stderr.String())
+
+	var resp deepl.TranslateResponse
+
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &resp))
+	require.Len(t, resp.Translations, 1, "the stdin should be translated as a single text")
+	require.Equal(t, "[JA] Hello\nWorld", resp.Translations[0].Text)
+}
+
+func TestApp_run_usage(t *testing.T) {
+	t.Parallel()
+
+	cli, srv, stdout, stderr := newTestApp(t, "")
+
+	srv.SetCharacterLimit(1000)
+	srv.SetCharacterCount(250)
+
+	exitCode := cli.run([]string{"usage"})
+
+	require.Equal(t, ExitOK, exitCode, stderr.String())
+	require.Contains(t, stdout.String(), "Characters:")
+	require.Contains(t, stdout.String(), "250 / 1000")
+	require.Contains(t, stdout.String(), "(25.00%)")
+}
+
+func TestApp_run_languages(t *testing.T) {
+	t.Parallel()
+
+	cli, _, stdout, stderr := newTestApp(t, "")
+
+	exitCode := cli.run([]string{"languages", "--type", "source"})
+
+	require.Equal(t, ExitOK, exitCode, stderr.String())
+
+	for _, language := range deepltest.DefaultSourceLanguages {
+		require.Contains(t, stdout.String(), language.Code)
+	}
+}
+
+func TestApp_run_glossary(t *testing.T) {
+	t.Parallel()
+
+	cli, _, stdout, stderr := newTestApp(t, "")
+
+	pathEntries := filepath.Join(t.TempDir(), "entries.csv")
+	require.NoError(t, os.WriteFile(pathEntries, []byte("Hello,Hallo\nWorld,Welt\n"), 0o600))
+
+	// create
+	exitCode := cli.run([]string{"glossary", "create", "--name", "my glossary", "--from", "EN", "--to", "DE", pathEntries})
+	require.Equal(t, ExitOK, exitCode, stderr.String())
+
+	var glossary deepl.Glossary
+
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &glossary))
+	require.Equal(t, "my glossary", glossary.Name)
+	require.Equal(t, 2, glossary.EntryCount)
+
+	// list
+	stdout.Reset()
+	require.Equal(t, ExitOK, cli.run([]string{"glossary", "list"}), stderr.String())
+	require.Contains(t, stdout.String(), glossary.GlossaryID)
+
+	// show entries
+	stdout.Reset()
+	require.Equal(t, ExitOK, cli.run([]string{"glossary", "show", "--entries", glossary.GlossaryID}), stderr.String())
+	require.Equal(t, "Hello\tHallo\nWorld\tWelt", strings.TrimSpace(stdout.String()))
+
+	// delete
+	stdout.Reset()
+	require.Equal(t, ExitOK, cli.run([]string{"glossary", "delete", glossary.GlossaryID}), stderr.String())
+	require.Equal(t, ExitError, cli.run([]string{"glossary", "show", glossary.GlossaryID}),
+		"deleted glossary should not be found")
+}
+
+func TestApp_run_document(t *testing.T) {
+	t.Parallel()
+
+	cli, srv, stdout, stderr := newTestApp(t, "")
+
+	srv.SetDocumentPolls(0)
+
+	pathIn := filepath.Join(t.TempDir(), "sample.txt")
+	require.NoError(t, os.WriteFile(pathIn, []byte("Hello"), 0o600))
+
+	exitCode := cli.run([]string{"document", "--to", "de", pathIn})
+	require.Equal(t, ExitOK, exitCode, stderr.String())
+
+	pathOut := filepath.Join(filepath.Dir(pathIn), "sample.DE.txt")
+	require.Contains(t, stdout.String(), pathOut)
+
+	translated, err := os.ReadFile(pathOut)
+	require.NoError(t, err)
+	require.Equal(t, "[DE] Hello", string(translated))
+}
+
+// ----------------------------------------------------------------------------
+//  Exit codes
+// ----------------------------------------------------------------------------
+
+func TestApp_run_exit_codes(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		setup    func(srv *deepltest.Server)
+		name     string
+		args     []string
+		expected int
+	}{
+		{
+			name:     "no command",
+			args:     []string{},
+			expected: ExitUsage,
+		},
+		{
+			name:     "help",
+			args:     []string{"help"},
+			expected: ExitOK,
+		},
+		{
+			name:     "unknown command",
+			args:     []string{"unknown"},
+			expected: ExitUsage,
+		},
+		{
+			name:     "missing target language",
+			args:     []string{"translate", "Hello"},
+			expected: ExitUsage,
+		},
+		{
+			name:     "unknown flag",
+			args:     []string{"usage", "--unknown"},
+			expected: ExitUsage,
+		},
+		{
+			name:     "flag help",
+			args:     []string{"usage", "-h"},
+			expected: ExitOK,
+		},
+		{
+			name:     "auth failure",
+			args:     []string{"usage"},
+			setup:    func(srv *deepltest.Server) { srv.AuthKey = "wrong key" },
+			expected: ExitAuth,
+		},
+		{
+			name:     "quota exceeded",
+			args:     []string{"translate", "--to", "DE", "Hello"},
+			setup:    func(srv *deepltest.Server) { srv.SetCharacterLimit(1) },
+			expected: ExitQuota,
+		},
+		{
+			name: "transient error",
+			args: []string{"languages"},
+			setup: func(srv *deepltest.Server) {
+				srv.InjectFailure(deepltest.Failure{Status: http.StatusServiceUnavailable})
+			},
+			expected: ExitTransient,
+		},
+	} {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			cli, srv, _, stderr := newTestApp(t, "")
+			if test.setup != nil {
+				test.setup(srv)
+			}
+
+			require.Equal(t, test.expected, cli.run(test.args), stderr.String())
+		})
+	}
+}
+
+//nolint:paralleltest // t.Setenv does not allow parallel tests
+func Test_newClient_no_api_key(t *testing.T) {
+	t.Setenv(deepl.NameEnvKeyAPI, "")
+
+	_, err := newClient()
+
+	require.Error(t, err)
+	require.Equal(t, ExitAuth, exitCode(err))
+}
+
+//nolint:paralleltest // do not parallelize due to global variable change
+func Test_userAgent(t *testing.T) {
+	oldVersion := version
+	defer func() { version = oldVersion }()
+
+	version = "v1.2.3"
+	require.Equal(t, "go-deepl-cli/1.2.3", userAgent(), "version set by the linker flag should be used")
+
+	// No module version in the build info of the tests
+	version = ""
+	require.Equal(t, "go-deepl-cli/devel", userAgent())
+}
@@ -0,0 +1,186 @@
+package deepl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// Sentinel errors of the API responses. Use errors.Is to check the error kind.
+//
+//	if errors.Is(err, deepl.ErrQuotaExceeded) {
+//	    // page someone
+//	}
+var (
+	// ErrBadRequest is the error of status 400.
+	ErrBadRequest = errors.New("bad request")
+	// ErrUnauthorized is the error of status 401.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden is the error of status 403. Usually the API key is invalid.
+	ErrForbidden = errors.New("authorization failed")
+	// ErrNotFound is the error of status 404.
+	ErrNotFound = errors.New("not found")
+	// ErrRequestTooLarge is the error of status 413.
+	ErrRequestTooLarge = errors.New("request entity too large")
+	// ErrTooManyRequests is the error of status 429 and 529.
+	ErrTooManyRequests = errors.New("too many requests")
+	// ErrQuotaExceeded is the error of status 456.
+	ErrQuotaExceeded = errors.New("quota exceeded")
+	// ErrServiceUnavailable is the error of status 503.
+	ErrServiceUnavailable = errors.New("service unavailable")
+	// ErrServerError is the error of status 5xx other than 503 and 529.
+	ErrServerError = errors.New("internal server error")
+	// ErrAPIKeyMismatch is the error when the API key does not match the host of
+	// the API. Such as a key of the free account used with APIPro.
+	ErrAPIKeyMismatch = errors.New("API key mismatch")
+)
+
+// StatusTooManyRequestsAlt is the alternative status code of DeepL API for too
+// many requests. Which is returned on high load.
+const StatusTooManyRequestsAlt = 529
+
+// ----------------------------------------------------------------------------
+//  Type: APIError
+// ----------------------------------------------------------------------------
+
+// APIError is the error returned when the API responds with a non-2xx status.
+//
+// Use errors.As to get the details and errors.Is with the sentinel errors such
+// as ErrQuotaExceeded to check the kind.
+type APIError struct {
+	// Err is the underlying error if the error response could not be decoded.
+	Err error
+	// Method and Endpoint are the HTTP method and path of the request. Such as
+	// "POST" and "/v2/translate".
+	Method   string
+	Endpoint string
+	// Message and Detail are the decoded ErrorResponse of the API.
+	Message string
+	Detail  string
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+}
+
+// Error is the implementation of the error interface.
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s Status code: %d, Returned message: %s",
+		statusSummary(e.StatusCode), e.StatusCode, e.Message)
+
+	if e.Detail != "" {
+		msg += ", Detail: " + e.Detail
+	}
+
+	if e.Endpoint != "" {
+		msg += fmt.Sprintf(" (endpoint: %s %s)", e.Method, e.Endpoint)
+	}
+
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+
+	return msg
+}
+
+// Is returns true if the target is the sentinel error of the status code. It
+// is used by errors.Is.
+func (e *APIError) Is(target error) bool {
+	return target != nil && errors.Is(sentinelFromStatus(e.StatusCode), target)
+}
+
+// Unwrap returns the underlying error if any.
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// Retryable returns true if the request may succeed by resending it later.
+// Which are "too many requests" (429, 529) and the server errors (5xx).
+func (e *APIError) Retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests ||
+		(e.StatusCode >= http.StatusInternalServerError && e.StatusCode <= 599)
+}
+
+// IsRetryable returns true if the error is worth retrying later. Which are the
+// retryable APIError and the network errors. The errors of the canceled or
+// expired context are not retryable.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable()
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr)
+}
+
+// ----------------------------------------------------------------------------
+//  Private Functions
+// ----------------------------------------------------------------------------
+
+// sentinelFromStatus returns the sentinel error of the given status code. It
+// returns nil if there is no sentinel for the status.
+//
+//nolint:cyclop // due to switch statement
+func sentinelFromStatus(status int) error {
+	switch status {
+	case http.StatusBadRequest:
+		return ErrBadRequest
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusRequestEntityTooLarge:
+		return ErrRequestTooLarge
+	case http.StatusTooManyRequests, StatusTooManyRequestsAlt:
+		return ErrTooManyRequests
+	case StatusQuotaExceeded:
+		return ErrQuotaExceeded
+	case http.StatusServiceUnavailable:
+		return ErrServiceUnavailable
+	}
+
+	if status >= http.StatusInternalServerError && status <= 599 {
+		return ErrServerError
+	}
+
+	return nil
+}
+
+// statusSummary returns the human readable summary of the given status code.
+//
+//nolint:cyclop // due to switch statement
+func statusSummary(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "Bad request. Please check the error message and your parameters."
+	case http.StatusUnauthorized:
+		return "Unauthorized. Please check your API key."
+	case http.StatusForbidden:
+		return "Authorization failed. Please supply a valid API key."
+	case http.StatusNotFound:
+		return "Not found. The requested resource clould not be found."
+	case http.StatusRequestEntityTooLarge:
+		return "Request entity too large. The entity size exceeds the limit of each request."
+	case http.StatusTooManyRequests, StatusTooManyRequestsAlt:
+		return "Too many requests. Please wait and resend your request."
+	case StatusQuotaExceeded:
+		return "Quota exceeded. The character limit has been reached."
+	case http.StatusServiceUnavailable:
+		return "Service currently unavailable. Try again later."
+	}
+
+	// Internal error (5**) other than "http.StatusServiceUnavailable"(503)
+	if status >= http.StatusInternalServerError && status <= 599 {
+		return "Internal server error. Please try again later."
+	}
+
+	return "Unexpected error."
+}
@@ -0,0 +1,148 @@
+package deepl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:varnamelen // tt is a test case by habit
+func TestAPIError_Is_and_Retryable(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		expectSentinel  error
+		status          int
+		expectRetryable bool
+	}{
+		{ErrBadRequest, http.StatusBadRequest, false},
+		{ErrUnauthorized, http.StatusUnauthorized, false},
+		{ErrForbidden, http.StatusForbidden, false},
+		{ErrNotFound, http.StatusNotFound, false},
+		{ErrRequestTooLarge, http.StatusRequestEntityTooLarge, false},
+		{ErrTooManyRequests, http.StatusTooManyRequests, true},
+		{ErrTooManyRequests, StatusTooManyRequestsAlt, true},
+		{ErrQuotaExceeded, StatusQuotaExceeded, false},
+		{ErrServiceUnavailable, http.StatusServiceUnavailable, true},
+		{ErrServerError, http.StatusInternalServerError, true},
+		{ErrServerError, http.StatusBadGateway, true},
+		{nil, 999, false},
+	} {
+		apiErr := &APIError{StatusCode: tt.status}
+
+		// Wrap it as the client does
+		err := WrapIfErr(apiErr, "failed to do something")
+
+		require.Equal(t, tt.expectRetryable, apiErr.Retryable(), "status: %d", tt.status)
+
+		if tt.expectSentinel != nil {
+			require.ErrorIs(t, err, tt.expectSentinel, "status: %d", tt.status)
+		}
+
+		for _, other := range []error{ErrBadRequest, ErrQuotaExceeded, ErrServiceUnavailable} {
+			if other != tt.expectSentinel {
+				require.False(t, errors.Is(err, other),
+					"status %d should not match %v", tt.status, other)
+			}
+		}
+	}
+}
+
+func TestAPIError_Error(t *testing.T) {
+	t.Parallel()
+
+	apiErr := &APIError{
+		Err:        nil,
+		Method:     http.MethodPost,
+		Endpoint:   "/v2/glossaries",
+		Message:    "Invalid glossary entries provided",
+		Detail:     "Key with the index 1 (starting at position 13) duplicates key with the index 0",
+		StatusCode: http.StatusBadRequest,
+	}
+
+	require.Equal(t,
+		"Bad request. Please check the error message and your parameters. Status code: 400, "+
+			"Returned message: Invalid glossary entries provided, "+
+			"Detail: Key with the index 1 (starting at position 13) duplicates key with the index 0 "+
+			"(endpoint: POST /v2/glossaries)",
+		apiErr.Error(),
+	)
+	require.NoError(t, apiErr.Unwrap())
+}
+
+func TestClient_APIError(t *testing.T) {
+	t.Parallel()
+
+	cli, teardown := spawnTestServer(
+		t,
+		"testdata/TranslateText/wrong-apikey-header",
+		"testdata/TranslateText/wrong-apikey-body",
+		http.MethodPost,
+		"/v2/translate",
+		"source_lang=EN&target_lang=JA&text=hello",
+	)
+	defer teardown()
+
+	_, err := cli.TranslateSentence(context.TODO(), "hello", "EN", "JA")
+
+	require.ErrorIs(t, err, ErrForbidden,
+		"the error should be checkable with the sentinel error")
+
+	var apiErr *APIError
+
+	require.True(t, errors.As(err, &apiErr),
+		"the error should be convertible to *APIError")
+	require.Equal(t, http.StatusForbidden, apiErr.StatusCode)
+	require.Equal(t, http.MethodPost, apiErr.Method)
+	require.Equal(t, "/v2/translate", apiErr.Endpoint)
+	require.False(t, apiErr.Retryable())
+}
+
+func Test_treatBodyAsErr_not_json(t *testing.T) {
+	t.Parallel()
+
+	err := treatBodyAsErr(http.StatusBadGateway, []byte("<html>Bad Gateway</html>"), nil)
+
+	var apiErr *APIError
+
+	require.True(t, errors.As(err, &apiErr),
+		"non-JSON error body should still be *APIError")
+	require.Equal(t, "<html>Bad Gateway</html>", apiErr.Message,
+		"the raw body should be the message")
+	require.ErrorIs(t, err, ErrServerError)
+	assert.Contains(t, err.Error(), "failed to decode error response",
+		"it should contain the decode error")
+	assert.Contains(t, fmt.Sprint(apiErr.Unwrap()), "failed to decode json",
+		"the decode error should be the underlying error")
+}
+
+func Test_treatBodyAsErr_success(t *testing.T) {
+	t.Parallel()
+
+	for _, status := range []int{http.StatusOK, http.StatusCreated, http.StatusAccepted} {
+		var handle DocumentHandle
+
+		err := treatBodyAsErr(status, []byte(`{"document_id":"ID","document_key":"KEY"}`), &handle)
+
+		require.NoError(t, err, "status %d should not be an error", status)
+		require.Equal(t, "ID", handle.DocumentID, "body of status %d should be decoded", status)
+	}
+
+	require.NoError(t, treatBodyAsErr(http.StatusNoContent, nil, nil), "no content should not be decoded")
+}
+
+func TestClient_key_host_mismatch_sentinel(t *testing.T) {
+	t.Parallel()
+
+	cli, err := New(APIPro, nil, WithAPIKey("key:fx"))
+	require.NoError(t, err)
+
+	_, err = cli.GetAccountStatus(context.TODO())
+
+	require.ErrorIs(t, err, ErrAPIKeyMismatch)
+}
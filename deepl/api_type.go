@@ -1,12 +1,23 @@
 package deepl
 
+import "strings"
+
+const (
+	// hostFree is the host name of the DeepL API for free account.
+	hostFree = "api-free.deepl.com"
+	// hostPro is the host name of the DeepL API for pro/paid account.
+	hostPro = "api.deepl.com"
+	// suffixFreeKey is the suffix of the API keys for free account.
+	suffixFreeKey = ":fx"
+)
+
 // APIType is an enum type for the API version.
 type APIType int
 
 const (
 	// APICustom represents the test API in the local server.
 	// To use this, you need to set the base URL of the local server
-	// via SetCustomURL() method.
+	// via WithBaseURL option of New.
 	APICustom APIType = iota
 	// APIFree represents the DeepL API for free account. It has a limitation
 	// of 500,000 characters per month.
@@ -14,9 +25,15 @@ const (
 	// APIPro represents the DeepL API for pro/paid account. It has no limitation
 	// of characters per month.
 	APIPro
+	// APIAuto detects the API type from the API key on each request. The keys
+	// of the free account end with ":fx" and use APIFree, otherwise APIPro.
+	APIAuto
 )
 
 // SetCustomURL sets the base URL to be forced to use.
+//
+// Deprecated: It affects all the clients in the process including APIFree and
+// APIPro. Use WithBaseURL option of New to set it per client.
 func SetCustomURL(url string) {
 	baseURLCustom = url
 }
@@ -24,22 +41,44 @@ func SetCustomURL(url string) {
 // BaseURLCustom is the base URL of the test API in the local server.
 var baseURLCustom string
 
+// APITypeFromKey returns APIFree if the given API key is of the free account,
+// otherwise APIPro.
+func APITypeFromKey(apiKey string) APIType {
+	if IsFreeKey(apiKey) {
+		return APIFree
+	}
+
+	return APIPro
+}
+
+// IsFreeKey returns true if the given API key is of the free account. Which ends
+// with ":fx".
+func IsFreeKey(apiKey string) bool {
+	return strings.HasSuffix(apiKey, suffixFreeKey)
+}
+
 // String is a Stringer implementation for APIType. Which returns the base URL
-// of the API as it's string representation.
+// of the API as it's string representation. For APIAuto with no custom URL set,
+// it returns "auto" since the URL is unknown until the API key is given.
 func (a APIType) String() string {
-	return a.BaseURL()
+	if baseURL := a.BaseURL(); baseURL != "" || a != APIAuto {
+		return baseURL
+	}
+
+	return "auto"
 }
 
-// BaseURL returns the base URL of the API.
+// BaseURL returns the base URL of the API. It returns an empty string for
+// APIAuto if no custom URL is set.
 func (a APIType) BaseURL() string {
 	baseURL := baseURLCustom
 
 	switch a {
 	case APIPro:
-		baseURL = "https://api.deepl.com"
+		baseURL = "https://" + hostPro
 	case APIFree:
-		baseURL = "https://api-free.deepl.com"
-	case APICustom:
+		baseURL = "https://" + hostFree
+	case APICustom, APIAuto:
 	default:
 	}
 
@@ -0,0 +1,115 @@
+package deepl
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPITypeFromKey(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, APIFree, APITypeFromKey("12345678-1234-abcd-abcd-123456789abc:fx"))
+	require.Equal(t, APIPro, APITypeFromKey("12345678-1234-abcd-abcd-123456789abc"))
+	require.True(t, IsFreeKey("key:fx"))
+	require.False(t, IsFreeKey("key:fx "), "the suffix must be at the end")
+}
+
+func TestAPIType_String_auto(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "auto", APIAuto.String(),
+		"APIAuto has no base URL until the API key is given")
+	require.Empty(t, APIAuto.BaseURL())
+}
+
+func TestClient_APIAuto(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		apiKey     string
+		expectHost string
+	}{
+		{dummyAuthKey + ":fx", hostFree},
+		{dummyAuthKey, hostPro},
+	} {
+		var actualHost string
+
+		httpClient := &http.Client{
+			Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				actualHost = req.URL.Host
+
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader(`{"character_count":1,"character_limit":2}`)),
+					Header:     http.Header{},
+				}, nil
+			}),
+		}
+
+		cli, err := New(APIAuto, nil, WithHTTPClient(httpClient), WithAPIKey(tt.apiKey))
+		require.NoError(t, err)
+
+		_, err = cli.GetAccountStatus(context.TODO())
+
+		require.NoError(t, err)
+		require.Equal(t, tt.expectHost, actualHost,
+			"the host should be detected from the API key: %s", tt.apiKey)
+	}
+}
+
+func TestClient_APIAuto_with_base_url(t *testing.T) {
+	t.Parallel()
+
+	cli, err := New(APIAuto, nil, WithBaseURL("http://localhost:8080"), WithAPIKey("key:fx"))
+	require.NoError(t, err)
+
+	baseURL, err := cli.resolveBaseURL("key:fx")
+
+	require.NoError(t, err)
+	require.Equal(t, "http://localhost:8080", baseURL.String(),
+		"the given base URL should take precedence over the detection")
+}
+
+func TestClient_key_host_mismatch(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		apiType          APIType
+		apiKey           string
+		expectErrMessage string
+	}{
+		{APIPro, "key:fx", "The key of the free account (ending with \":fx\") can not be used with api.deepl.com"},
+		{APIFree, "key", "The key of the pro account can not be used with api-free.deepl.com"},
+	} {
+		cli, err := New(tt.apiType, nil, WithAPIKey(tt.apiKey))
+		require.NoError(t, err)
+
+		status, err := cli.GetAccountStatus(context.TODO())
+
+		require.Error(t, err, "key and host mismatch should return an error")
+		require.Nil(t, status, "returned value should be nil on error")
+		assert.Contains(t, err.Error(), "API key mismatch",
+			"it should contain the error reason")
+		assert.Contains(t, err.Error(), tt.expectErrMessage,
+			"it should contain the error detail")
+	}
+}
+
+func TestClient_nil_base_url(t *testing.T) {
+	t.Parallel()
+
+	cli := &Client{KeyProvider: StaticKey(dummyAuthKey)}
+
+	status, err := cli.GetAccountStatus(context.TODO())
+
+	require.Error(t, err, "nil base URL should return an error")
+	require.Nil(t, status, "returned value should be nil on error")
+	assert.Contains(t, err.Error(), "base URL is not set",
+		"it should contain the error reason")
+}
@@ -0,0 +1,380 @@
+package deepl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrBudgetExceeded is the error when the request would exceed the character
+// budget set by WithBudget. Use errors.As with *BudgetError for the details.
+var ErrBudgetExceeded = errors.New("character budget exceeded")
+
+// DefaultBudgetRefreshInterval is the default interval to refresh the character
+// usage from the API.
+const DefaultBudgetRefreshInterval = 5 * time.Minute
+
+// ----------------------------------------------------------------------------
+//  Type: Budget
+// ----------------------------------------------------------------------------
+
+// Budget configures the guard of the monthly character quota.
+//
+// The guard tracks the characters billed by the translations locally and
+// refreshes the usage from the API periodically. The translation requests that
+// would exceed the budget are rejected with *BudgetError before being sent.
+//
+// Document translations are not counted locally since the billed characters are
+// unknown until translated. They are checked against the current usage and
+// reflected on the next refresh.
+type Budget struct {
+	// SoftRatio is the ratio (0.0 to 1.0) of the character limit over which the
+	// requests are rejected unless the context is marked by WithCritical. Zero
+	// disables the soft budget.
+	SoftRatio float64
+	// HardRatio is the ratio (0.0 to 1.0) of the character limit over which all
+	// the requests are rejected. Zero disables the hard budget.
+	HardRatio float64
+	// RefreshInterval is the interval to refresh the usage from the API. Zero
+	// uses DefaultBudgetRefreshInterval.
+	RefreshInterval time.Duration
+}
+
+// WithBudget sets the character budget guard of the client.
+//
+// For example, to stop the batch jobs at 90% of the limit:
+//
+//	cli, err := deepl.New(deepl.APIFree, nil, deepl.WithBudget(deepl.Budget{HardRatio: 0.9}))
+func WithBudget(budget Budget) Option {
+	return func(c *Client) error {
+		if budget.SoftRatio < 0 || budget.SoftRatio > 1 || budget.HardRatio < 0 || budget.HardRatio > 1 {
+			return NewErr("budget ratios must be between 0 and 1. Soft: %v, Hard: %v",
+				budget.SoftRatio, budget.HardRatio)
+		}
+
+		if budget.RefreshInterval < 0 {
+			return NewErr("refresh interval must not be negative: %v", budget.RefreshInterval)
+		}
+
+		if budget.RefreshInterval == 0 {
+			budget.RefreshInterval = DefaultBudgetRefreshInterval
+		}
+
+		c.budget = &budgetGuard{budget: budget, refreshing: make(chan struct{}, 1)}
+
+		return nil
+	}
+}
+
+// WithCritical returns a context that marks the requests as critical. Critical
+// requests ignore the soft budget but not the hard one.
+func WithCritical(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ctxKeyCritical{}, true)
+}
+
+// ----------------------------------------------------------------------------
+//  Type: BudgetLevel
+// ----------------------------------------------------------------------------
+
+// BudgetLevel is the level of the exceeded budget.
+type BudgetLevel int
+
+const (
+	// BudgetSoft is the soft budget which can be bypassed by WithCritical.
+	BudgetSoft BudgetLevel = iota
+	// BudgetHard is the hard budget which can not be bypassed.
+	BudgetHard
+)
+
+// String returns the name of the budget level.
+func (l BudgetLevel) String() string {
+	if l == BudgetHard {
+		return "hard"
+	}
+
+	return "soft"
+}
+
+// ----------------------------------------------------------------------------
+//  Type: BudgetError
+// ----------------------------------------------------------------------------
+
+// BudgetError is the error returned when the request would exceed the budget.
+// It matches ErrBudgetExceeded with errors.Is.
+type BudgetError struct {
+	// Level is the level of the exceeded budget.
+	Level BudgetLevel
+	// Used is the number of characters used including the local count.
+	Used int64
+	// Requested is the number of characters of the rejected request.
+	Requested int
+	// Threshold is the number of characters allowed by the budget.
+	Threshold int64
+	// Limit is the character limit of the account.
+	Limit int64
+}
+
+// Error is the implementation of the error interface.
+func (e *BudgetError) Error() string {
+	return fmt.Sprintf("%s: %s limit of %d characters (account limit: %d). Used: %d, Requested: %d",
+		ErrBudgetExceeded, e.Level, e.Threshold, e.Limit, e.Used, e.Requested)
+}
+
+// Is returns true if the target is ErrBudgetExceeded. It is used by errors.Is.
+func (e *BudgetError) Is(target error) bool {
+	return target == ErrBudgetExceeded
+}
+
+// ----------------------------------------------------------------------------
+//  Type: BudgetStatus
+// ----------------------------------------------------------------------------
+
+// BudgetStatus is the snapshot of the character usage tracked by the budget
+// guard.
+type BudgetStatus struct {
+	// RefreshedAt is the time of the last refresh from the API. Zero if never.
+	RefreshedAt time.Time
+	// CharacterCount and CharacterLimit are the usage of the last refresh.
+	CharacterCount int64
+	CharacterLimit int64
+	// Billed is the number of characters billed since the last refresh started.
+	Billed int64
+	// Reserved is the number of characters of the requests in flight.
+	Reserved int64
+}
+
+// Used returns the estimated number of the characters used.
+func (s BudgetStatus) Used() int64 {
+	return s.CharacterCount + s.Billed + s.Reserved
+}
+
+// BudgetStatus returns the current state of the budget guard. It returns zero
+// values if no budget is set.
+func (c *Client) BudgetStatus() BudgetStatus {
+	if c.budget == nil {
+		return BudgetStatus{}
+	}
+
+	c.budget.mutex.Lock()
+	defer c.budget.mutex.Unlock()
+
+	return c.budget.status
+}
+
+// RefreshBudget refreshes the character usage of the budget guard from the API
+// regardless of the refresh interval. It does nothing if no budget is set.
+func (c *Client) RefreshBudget(ctx context.Context) error {
+	if c.budget == nil {
+		return nil
+	}
+
+	return c.refreshBudget(ctx, true)
+}
+
+// ----------------------------------------------------------------------------
+//  Type: budgetGuard
+// ----------------------------------------------------------------------------
+
+// budgetGuard tracks the character usage against the budget.
+type budgetGuard struct {
+	// refreshing is the semaphore to refresh the usage once at a time.
+	refreshing chan struct{}
+	status     BudgetStatus
+	budget     Budget
+	mutex      sync.Mutex
+}
+
+// billed returns the number of characters billed since the last refresh.
+func (g *budgetGuard) billed() int64 {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	return g.status.Billed
+}
+
+// needsRefresh returns true if the usage is older than the refresh interval.
+func (g *budgetGuard) needsRefresh(now time.Time) bool {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	return g.status.RefreshedAt.IsZero() || now.Sub(g.status.RefreshedAt) >= g.budget.RefreshInterval
+}
+
+// refreshed returns true if the usage was ever refreshed.
+func (g *budgetGuard) refreshed() bool {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	return !g.status.RefreshedAt.IsZero()
+}
+
+// update sets the usage from the API. The billedBefore is the number of the
+// billed characters when the refresh started. Only they are cleared since they
+// are included in the count of the API. The characters settled during the
+// refresh are kept to be counted until the next refresh.
+//
+// The usage of the API key is used instead of the account if it is tighter.
+func (g *budgetGuard) update(accountStatus *AccountStatus, billedBefore int64, now time.Time) {
+	usage := accountStatus.Characters()
+
+	keyUsage := accountStatus.APIKeyCharacters()
+	if !keyUsage.Unlimited() && (usage.Unlimited() || keyUsage.Remaining() < usage.Remaining()) {
+		usage = keyUsage
+	}
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.status.CharacterCount = usage.Count
+	g.status.CharacterLimit = usage.Limit
+	g.status.Billed -= billedBefore
+	g.status.RefreshedAt = now
+}
+
+// reserve reserves the given characters if they are within the budget.
+func (g *budgetGuard) reserve(characters int, critical bool) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	limit := g.status.CharacterLimit
+	if (Usage{Limit: limit}).Unlimited() {
+		// Reserve anyway to be settled consistently if the limit is set later
+		g.status.Reserved += int64(characters)
+
+		return nil
+	}
+
+	used := g.status.Used()
+
+	for _, check := range []struct {
+		level BudgetLevel
+		ratio float64
+	}{
+		{level: BudgetHard, ratio: g.budget.HardRatio},
+		{level: BudgetSoft, ratio: g.budget.SoftRatio},
+	} {
+		if check.ratio == 0 || (check.level == BudgetSoft && critical) {
+			continue
+		}
+
+		threshold := int64(check.ratio * float64(limit))
+		if used+int64(characters) > threshold {
+			return &BudgetError{
+				Level:     check.level,
+				Used:      used,
+				Requested: characters,
+				Threshold: threshold,
+				Limit:     limit,
+			}
+		}
+	}
+
+	g.status.Reserved += int64(characters)
+
+	return nil
+}
+
+// settle replaces the reserved characters with the billed ones.
+func (g *budgetGuard) settle(reserved, billed int) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.status.Reserved -= int64(reserved)
+	g.status.Billed += int64(billed)
+}
+
+// ----------------------------------------------------------------------------
+//  Private Methods
+// ----------------------------------------------------------------------------
+
+// reserveBudget checks the given characters against the budget, refreshing the
+// usage if needed, and returns the function to settle the billed characters
+// after the request. It is a no-op if no budget is set.
+func (c *Client) reserveBudget(ctx context.Context, characters int) (func(billed int), error) {
+	guard := c.budget
+	if guard == nil {
+		return func(int) {}, nil
+	}
+
+	if guard.needsRefresh(time.Now()) {
+		if err := c.refreshBudget(ctx, false); err != nil {
+			if !guard.refreshed() {
+				return nil, err
+			}
+
+			if c.Logger != nil {
+				c.Logger.Printf("%v. Using the last known usage", err)
+			}
+		}
+	}
+
+	if err := guard.reserve(characters, isCritical(ctx)); err != nil {
+		return nil, err
+	}
+
+	return func(billed int) {
+		guard.settle(characters, billed)
+	}, nil
+}
+
+// refreshBudget refreshes the character usage from the API once at a time. The
+// concurrent callers wait for the running refresh. Unless forced, the refresh is
+// skipped if the usage was refreshed while waiting.
+func (c *Client) refreshBudget(ctx context.Context, force bool) error {
+	guard := c.budget
+
+	select {
+	case <-ctx.Done():
+		return WrapIfErr(ctx.Err(), "canceled while waiting for the usage refresh")
+	case guard.refreshing <- struct{}{}:
+	}
+
+	defer func() { <-guard.refreshing }()
+
+	if !force && !guard.needsRefresh(time.Now()) {
+		return nil
+	}
+
+	billedBefore := guard.billed()
+
+	accountStatus, err := c.GetAccountStatus(ctx)
+	if err != nil {
+		return WrapIfErr(err, "failed to refresh the character usage")
+	}
+
+	guard.update(accountStatus, billedBefore, time.Now())
+
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+//  Private Functions
+// ----------------------------------------------------------------------------
+
+// ctxKeyCritical is the context key to mark the requests as critical.
+type ctxKeyCritical struct{}
+
+// isCritical returns true if the context is marked by WithCritical.
+func isCritical(ctx context.Context) bool {
+	critical, _ := ctx.Value(ctxKeyCritical{}).(bool)
+
+	return critical
+}
+
+// billedCharacters returns the characters billed for the translations. If the
+// API did not return them (ShowBilledCharacters is not set), the characters of
+// the source texts are used instead.
+func billedCharacters(transResp *TranslateResponse, texts []string) int {
+	billed := 0
+	for _, trans := range transResp.Translations {
+		billed += trans.BilledCharacters
+	}
+
+	if billed == 0 {
+		return countCharacters(texts)
+	}
+
+	return billed
+}
@@ -0,0 +1,305 @@
+package deepl
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  WithBudget
+// ----------------------------------------------------------------------------
+
+func TestWithBudget_bad_budget(t *testing.T) {
+	t.Parallel()
+
+	for _, budget := range []Budget{
+		{SoftRatio: -0.1},
+		{HardRatio: 1.1},
+	} {
+		_, err := New(APIFree, nil, WithBudget(budget))
+
+		require.Error(t, err, "out of range ratio should be an error: %+v", budget)
+		assert.Contains(t, err.Error(), "budget ratios must be between 0 and 1")
+	}
+
+	_, err := New(APIFree, nil, WithBudget(Budget{RefreshInterval: -1}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "refresh interval must not be negative")
+
+	cli, err := New(APIFree, nil, WithBudget(Budget{HardRatio: 0.9}))
+	require.NoError(t, err)
+	require.Equal(t, DefaultBudgetRefreshInterval, cli.budget.budget.RefreshInterval)
+}
+
+func TestClient_BudgetStatus_no_budget(t *testing.T) {
+	t.Parallel()
+
+	cli, err := New(APIFree, nil)
+	require.NoError(t, err)
+
+	require.Equal(t, BudgetStatus{}, cli.BudgetStatus())
+	require.NoError(t, cli.RefreshBudget(context.Background()), "it should do nothing")
+}
+
+// ----------------------------------------------------------------------------
+//  Budget guard
+// ----------------------------------------------------------------------------
+
+func TestClient_budget_hard_stop(t *testing.T) {
+	t.Parallel()
+
+	cli, teardown, countUsage := spawnQuotaServer(t, 80, 100)
+	defer teardown()
+
+	require.NoError(t, WithBudget(Budget{HardRatio: 0.9})(cli))
+
+	// 80 + 5 is within 90
+	_, err := cli.TranslateSentence(context.Background(), "Hello", "EN", "DE")
+	require.NoError(t, err)
+
+	status := cli.BudgetStatus()
+	require.Equal(t, int64(80), status.CharacterCount, "usage should be fetched before the first request")
+	require.Equal(t, int64(5), status.Billed, "billed characters should be tracked locally")
+	require.Zero(t, status.Reserved)
+	require.Equal(t, int64(85), status.Used())
+
+	// 85 + 6 exceeds 90 even if critical
+	_, err = cli.TranslateSentence(WithCritical(context.Background()), "World!", "EN", "DE")
+	require.Error(t, err)
+	require.ErrorIs(t, err, ErrBudgetExceeded)
+
+	var budgetErr *BudgetError
+
+	require.True(t, errors.As(err, &budgetErr))
+	require.Equal(t, BudgetHard, budgetErr.Level)
+	require.Equal(t, int64(85), budgetErr.Used)
+	require.Equal(t, 6, budgetErr.Requested)
+	require.Equal(t, int64(90), budgetErr.Threshold)
+	require.Equal(t, int64(100), budgetErr.Limit)
+	assert.Contains(t, err.Error(), "hard limit of 90 characters (account limit: 100). Used: 85, Requested: 6")
+
+	require.Equal(t, int32(1), atomic.LoadInt32(countUsage), "usage should not be refreshed within the interval")
+	require.Equal(t, int64(5), cli.BudgetStatus().Billed, "rejected request should not be counted")
+}
+
+func TestClient_budget_soft_limit(t *testing.T) {
+	t.Parallel()
+
+	cli, teardown, _ := spawnQuotaServer(t, 50, 100)
+	defer teardown()
+
+	require.NoError(t, WithBudget(Budget{SoftRatio: 0.5, HardRatio: 0.9})(cli))
+
+	_, err := cli.TranslateSentence(context.Background(), "Hello", "EN", "DE")
+	require.Error(t, err)
+	require.ErrorIs(t, err, ErrBudgetExceeded)
+
+	var budgetErr *BudgetError
+
+	require.True(t, errors.As(err, &budgetErr))
+	require.Equal(t, BudgetSoft, budgetErr.Level)
+	assert.Contains(t, err.Error(), "soft limit")
+
+	resp, err := cli.TranslateSentence(WithCritical(context.Background()), "Hello", "EN", "DE")
+	require.NoError(t, err, "critical request should bypass the soft limit")
+	require.Equal(t, "DE:Hello", resp.Translations[0].Text)
+}
+
+func TestClient_budget_billed_characters(t *testing.T) {
+	t.Parallel()
+
+	cli, teardown, _ := spawnQuotaServer(t, 0, 1000)
+	defer teardown()
+
+	require.NoError(t, WithBudget(Budget{HardRatio: 1})(cli))
+
+	_, err := cli.Translate(context.Background(), []string{"Hello", "World"}, &TranslateOptions{
+		TargetLang:           "DE",
+		ShowBilledCharacters: true,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, int64(10), cli.BudgetStatus().Billed)
+}
+
+func TestClient_budget_refresh(t *testing.T) {
+	t.Parallel()
+
+	cli, teardown, countUsage := spawnQuotaServer(t, 0, 1000)
+	defer teardown()
+
+	require.NoError(t, WithBudget(Budget{HardRatio: 0.9, RefreshInterval: time.Nanosecond})(cli))
+
+	for i := 0; i < 3; i++ {
+		_, err := cli.TranslateSentence(context.Background(), "Hello", "EN", "DE")
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, int32(3), atomic.LoadInt32(countUsage), "usage should be refreshed on each request")
+
+	status := cli.BudgetStatus()
+	require.Equal(t, int64(10), status.CharacterCount, "usage of the API should include the previous requests")
+	require.Equal(t, int64(5), status.Billed, "local count should be reset on refresh")
+	require.False(t, status.RefreshedAt.IsZero())
+}
+
+func TestClient_budget_refresh_fail(t *testing.T) {
+	t.Parallel()
+
+	cli, teardown, _ := spawnScriptedServer(t, scriptedResponse{status: 403, body: bodyErr})
+	defer teardown()
+
+	require.NoError(t, WithBudget(Budget{HardRatio: 0.9})(cli))
+
+	_, err := cli.TranslateSentence(context.Background(), "Hello", "EN", "DE")
+	require.Error(t, err, "it should fail if the usage was never fetched")
+	require.ErrorIs(t, err, ErrForbidden)
+	assert.Contains(t, err.Error(), "failed to refresh the character usage")
+}
+
+func TestClient_budget_refresh_fail_uses_last_usage(t *testing.T) {
+	t.Parallel()
+
+	cli, teardown, _ := spawnScriptedServer(t,
+		scriptedResponse{status: 200, body: `{"character_count":0,"character_limit":1000}`},
+		scriptedResponse{status: 200, body: bodyTranslate},
+		scriptedResponse{status: 503, body: bodyErr},
+		scriptedResponse{status: 200, body: bodyTranslate},
+	)
+	defer teardown()
+
+	var logOut strings.Builder
+
+	cli.Logger.SetOutput(&logOut)
+
+	require.NoError(t, WithBudget(Budget{HardRatio: 0.9, RefreshInterval: time.Nanosecond})(cli))
+
+	for i := 0; i < 2; i++ {
+		_, err := cli.TranslateSentence(context.Background(), "Hello", "EN", "DE")
+		require.NoError(t, err)
+	}
+
+	assert.Contains(t, logOut.String(), "Using the last known usage")
+	require.Equal(t, int64(10), cli.BudgetStatus().Billed)
+}
+
+func TestClient_budget_refresh_fail_nil_logger(t *testing.T) {
+	t.Parallel()
+
+	cli, teardown, _ := spawnScriptedServer(t,
+		scriptedResponse{status: 200, body: `{"character_count":0,"character_limit":1000}`},
+		scriptedResponse{status: 200, body: bodyTranslate},
+		scriptedResponse{status: 503, body: bodyErr},
+		scriptedResponse{status: 200, body: bodyTranslate},
+	)
+	defer teardown()
+
+	cli.Logger = nil
+
+	require.NoError(t, WithBudget(Budget{HardRatio: 0.9, RefreshInterval: time.Nanosecond})(cli))
+
+	for i := 0; i < 2; i++ {
+		require.NotPanics(t, func() {
+			_, err := cli.TranslateSentence(context.Background(), "Hello", "EN", "DE")
+			require.NoError(t, err)
+		}, "failed refresh should not panic without the logger")
+	}
+}
+
+func TestClient_budget_refresh_once_at_a_time(t *testing.T) {
+	t.Parallel()
+
+	cli, teardown, countUsage := spawnQuotaServer(t, 0, 1000)
+	defer teardown()
+
+	require.NoError(t, WithBudget(Budget{HardRatio: 0.9})(cli))
+
+	var waitGroup sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		waitGroup.Add(1)
+
+		go func() {
+			defer waitGroup.Done()
+
+			_, err := cli.TranslateSentence(context.Background(), "Hello", "EN", "DE")
+			assert.NoError(t, err)
+		}()
+	}
+
+	waitGroup.Wait()
+
+	require.Equal(t, int32(1), atomic.LoadInt32(countUsage), "concurrent requests should share the refresh")
+	require.Equal(t, int64(50), cli.BudgetStatus().Used())
+}
+
+func TestClient_UploadDocument_budget_exceeded(t *testing.T) {
+	t.Parallel()
+
+	cli, teardown, _ := spawnQuotaServer(t, 95, 100)
+	defer teardown()
+
+	require.NoError(t, WithBudget(Budget{HardRatio: 0.9})(cli))
+
+	_, err := cli.UploadDocument(context.Background(), strings.NewReader("Hello"), "hello.txt",
+		&DocumentOptions{TargetLang: "DE"})
+	require.Error(t, err)
+	require.ErrorIs(t, err, ErrBudgetExceeded)
+}
+
+func TestBudgetLevel_String(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "soft", BudgetSoft.String())
+	require.Equal(t, "hard", BudgetHard.String())
+}
+
+func Test_budgetGuard_update_api_key_limit(t *testing.T) {
+	t.Parallel()
+
+	guard := &budgetGuard{budget: Budget{HardRatio: 0.9}}
+
+	guard.update(&AccountStatus{
+		CharacterCount:       5947223,
+		CharacterLimit:       UnlimitedThreshold,
+		APIKeyCharacterCount: 800,
+		APIKeyCharacterLimit: 1000,
+	}, 0, time.Now())
+
+	require.Equal(t, int64(800), guard.status.CharacterCount, "tighter API key usage should be used")
+	require.Equal(t, int64(1000), guard.status.CharacterLimit)
+	require.ErrorIs(t, guard.reserve(101, false), ErrBudgetExceeded)
+
+	guard.update(&AccountStatus{CharacterCount: 5947223, CharacterLimit: UnlimitedThreshold}, 0, time.Now())
+
+	require.NoError(t, guard.reserve(1000000, false), "unlimited account should not be limited")
+}
+
+func Test_budgetGuard_update_settled_during_refresh(t *testing.T) {
+	t.Parallel()
+
+	guard := &budgetGuard{budget: Budget{HardRatio: 0.9}}
+
+	require.NoError(t, guard.reserve(5, false))
+	guard.settle(5, 5)
+
+	// Refresh starts
+	billedBefore := guard.billed()
+
+	require.NoError(t, guard.reserve(3, false))
+	guard.settle(3, 3)
+
+	// Refresh ends with the usage including only the characters settled before
+	guard.update(&AccountStatus{CharacterCount: 105, CharacterLimit: 1000}, billedBefore, time.Now())
+
+	require.Equal(t, int64(3), guard.status.Billed, "characters settled during the refresh should be kept")
+	require.Equal(t, int64(108), guard.status.Used())
+}
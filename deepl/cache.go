@@ -0,0 +1,344 @@
+package deepl
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+//  Type: CacheBackend
+// ----------------------------------------------------------------------------
+
+// CacheBackend is the storage of the translation cache set by WithCache.
+//
+// The keys are hex strings safe to use as file names. The implementations must
+// be safe for concurrent use. Get must return ok as false without an error if
+// the key is not found or expired.
+type CacheBackend interface {
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	Set(ctx context.Context, key string, value []byte) error
+}
+
+// CacheStats is the hit and miss counts of the translation cache.
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// HitRatio returns the ratio (0.0 to 1.0) of the hits. It returns zero if the
+// cache was never used.
+func (s CacheStats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// WithCache sets the translation cache of the client.
+//
+// The translations are cached per text with the source and target languages and
+// all the other translate options, including the glossary ID. Only the texts not
+// in the cache are sent to the API. The billed characters of the cached
+// translations are zero since they are not billed.
+//
+// The errors of the backend are logged and treated as cache misses so that the
+// translation continues.
+func WithCache(backend CacheBackend) Option {
+	return func(c *Client) error {
+		if backend == nil {
+			return NewErr("cache backend is nil")
+		}
+
+		c.cache = &translationCache{backend: backend}
+
+		return nil
+	}
+}
+
+// CacheStats returns the hit and miss counts of the translation cache. It
+// returns zero values if no cache is set.
+func (c *Client) CacheStats() CacheStats {
+	if c.cache == nil {
+		return CacheStats{}
+	}
+
+	return c.cache.stats()
+}
+
+// ----------------------------------------------------------------------------
+//  Type: MemoryCache
+// ----------------------------------------------------------------------------
+
+// MemoryCache is an in-memory CacheBackend with the LRU eviction and the TTL.
+type MemoryCache struct {
+	entries  map[string]*list.Element
+	order    *list.List
+	ttl      time.Duration
+	capacity int
+	mutex    sync.Mutex
+}
+
+// memoryEntry is an element of MemoryCache.order.
+type memoryEntry struct {
+	expires time.Time
+	key     string
+	value   []byte
+}
+
+// NewMemoryCache returns a new MemoryCache that holds up to capacity entries
+// for the ttl. Zero capacity or ttl means unlimited.
+func NewMemoryCache(capacity int, ttl time.Duration) *MemoryCache {
+	return &MemoryCache{
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+		ttl:      ttl,
+		capacity: capacity,
+	}
+}
+
+// Get is the implementation of CacheBackend.
+func (m *MemoryCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	elem, ok := m.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	entry, _ := elem.Value.(*memoryEntry)
+
+	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+		m.order.Remove(elem)
+		delete(m.entries, key)
+
+		return nil, false, nil
+	}
+
+	m.order.MoveToFront(elem)
+
+	return entry.value, true, nil
+}
+
+// Set is the implementation of CacheBackend.
+func (m *MemoryCache) Set(_ context.Context, key string, value []byte) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	entry := &memoryEntry{key: key, value: value}
+	if m.ttl > 0 {
+		entry.expires = time.Now().Add(m.ttl)
+	}
+
+	if elem, ok := m.entries[key]; ok {
+		elem.Value = entry
+		m.order.MoveToFront(elem)
+
+		return nil
+	}
+
+	m.entries[key] = m.order.PushFront(entry)
+
+	if m.capacity > 0 && m.order.Len() > m.capacity {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+
+		if oldestEntry, ok := oldest.Value.(*memoryEntry); ok {
+			delete(m.entries, oldestEntry.key)
+		}
+	}
+
+	return nil
+}
+
+// Len returns the number of the entries including the expired ones not yet
+// evicted.
+func (m *MemoryCache) Len() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.order.Len()
+}
+
+// ----------------------------------------------------------------------------
+//  Type: FileCache
+// ----------------------------------------------------------------------------
+
+// FileCache is an on-disk CacheBackend that stores each entry as a file in the
+// directory. It persists across the processes, such as builds and deployments.
+// The entries older than the TTL are treated as missing.
+type FileCache struct {
+	dir string
+	ttl time.Duration
+}
+
+// NewFileCache returns a new FileCache in the given directory with the ttl.
+// The directory is created if not exists. Zero ttl means no expiration.
+func NewFileCache(dir string, ttl time.Duration) (*FileCache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, WrapIfErr(err, "failed to create cache directory")
+	}
+
+	return &FileCache{dir: dir, ttl: ttl}, nil
+}
+
+// Get is the implementation of CacheBackend.
+func (f *FileCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	pathFile := filepath.Join(f.dir, key)
+
+	info, err := os.Stat(pathFile)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, false, nil
+		}
+
+		return nil, false, WrapIfErr(err, "failed to stat cache file")
+	}
+
+	if f.ttl > 0 && time.Since(info.ModTime()) > f.ttl {
+		return nil, false, nil
+	}
+
+	value, err := os.ReadFile(pathFile)
+	if err != nil {
+		return nil, false, WrapIfErr(err, "failed to read cache file")
+	}
+
+	return value, true, nil
+}
+
+// Set is the implementation of CacheBackend. The file is written atomically by
+// renaming a temporary file.
+func (f *FileCache) Set(_ context.Context, key string, value []byte) error {
+	tmpFile, err := os.CreateTemp(f.dir, key+".*.tmp")
+	if err != nil {
+		return WrapIfErr(err, "failed to create cache file")
+	}
+
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(value); err != nil {
+		tmpFile.Close()
+
+		return WrapIfErr(err, "failed to write cache file")
+	}
+
+	if err := tmpFile.Close(); err != nil {
+		return WrapIfErr(err, "failed to close cache file")
+	}
+
+	return WrapIfErr(os.Rename(tmpFile.Name(), filepath.Join(f.dir, key)), "failed to rename cache file")
+}
+
+// ----------------------------------------------------------------------------
+//  Type: translationCache
+// ----------------------------------------------------------------------------
+
+// translationCache is the cache of the translations with the hit/miss counts.
+type translationCache struct {
+	backend CacheBackend
+	hits    uint64
+	misses  uint64
+}
+
+// lookup sets the cached translations of the texts to translations and returns
+// the indices of the texts not in the cache. All the indices are returned if the
+// cache is nil. The errors of the backend are logged to the logger.
+func (tc *translationCache) lookup(
+	ctx context.Context,
+	logger *log.Logger,
+	texts []string,
+	opts *TranslateOptions,
+	translations []Translation,
+) []int {
+	pending := make([]int, 0, len(texts))
+
+	for index, text := range texts {
+		if tc == nil {
+			pending = append(pending, index)
+
+			continue
+		}
+
+		value, ok, err := tc.backend.Get(ctx, cacheKey(text, opts))
+		if err != nil && logger != nil {
+			logger.Printf("failed to get translation from cache: %v", err)
+		}
+
+		if ok {
+			ok = json.Unmarshal(value, &translations[index]) == nil
+		}
+
+		if !ok {
+			atomic.AddUint64(&tc.misses, 1)
+
+			pending = append(pending, index)
+
+			continue
+		}
+
+		atomic.AddUint64(&tc.hits, 1)
+	}
+
+	return pending
+}
+
+// store stores the translation of the text. It does nothing if the cache is
+// nil.
+func (tc *translationCache) store(
+	ctx context.Context,
+	logger *log.Logger,
+	text string,
+	opts *TranslateOptions,
+	trans Translation,
+) {
+	if tc == nil {
+		return
+	}
+
+	// Cached translations are not billed
+	trans.BilledCharacters = 0
+
+	value, err := json.Marshal(trans)
+	if err == nil {
+		err = tc.backend.Set(ctx, cacheKey(text, opts), value)
+	}
+
+	if err != nil && logger != nil {
+		logger.Printf("failed to set translation to cache: %v", err)
+	}
+}
+
+// stats returns the hit and miss counts.
+func (tc *translationCache) stats() CacheStats {
+	return CacheStats{
+		Hits:   atomic.LoadUint64(&tc.hits),
+		Misses: atomic.LoadUint64(&tc.misses),
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  Private Functions
+// ----------------------------------------------------------------------------
+
+// cacheKey returns the cache key of the text translated with the options. The
+// options that do not affect the translation are excluded.
+func cacheKey(text string, opts *TranslateOptions) string {
+	urlVal := opts.urlValues()
+	urlVal.Del("show_billed_characters")
+
+	hash := sha256.Sum256([]byte(urlVal.Encode() + "\n" + text))
+
+	return hex.EncodeToString(hash[:])
+}
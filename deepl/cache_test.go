@@ -0,0 +1,247 @@
+package deepl
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  WithCache
+// ----------------------------------------------------------------------------
+
+func TestWithCache_nil(t *testing.T) {
+	t.Parallel()
+
+	_, err := New(APIFree, nil, WithCache(nil))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cache backend is nil")
+}
+
+func TestClient_Translate_cache(t *testing.T) {
+	t.Parallel()
+
+	cli, teardown, countReq := spawnEchoServer(t)
+	defer teardown()
+
+	require.NoError(t, WithCache(NewMemoryCache(10, 0))(cli))
+
+	_, err := cli.TranslateTexts(context.Background(), []string{"Hello", "World"}, "EN", "DE")
+	require.NoError(t, err)
+
+	resp, err := cli.TranslateTexts(context.Background(), []string{"Hello", "Cache", "World"}, "EN", "DE")
+	require.NoError(t, err)
+
+	require.Len(t, resp.Translations, 3)
+	require.Equal(t, "DE:Hello", resp.Translations[0].Text)
+	require.Equal(t, "DE:Cache", resp.Translations[1].Text)
+	require.Equal(t, "DE:World", resp.Translations[2].Text)
+	require.Equal(t, "EN", resp.Translations[2].DetectedSourceLanguage)
+
+	require.Equal(t, int32(2), atomic.LoadInt32(countReq), "only the missed text should be requested")
+	require.Equal(t, CacheStats{Hits: 2, Misses: 3}, cli.CacheStats())
+	require.InDelta(t, 0.4, cli.CacheStats().HitRatio(), 0.001)
+
+	// All cached
+	_, err = cli.TranslateTexts(context.Background(), []string{"Cache"}, "EN", "DE")
+	require.NoError(t, err)
+	require.Equal(t, int32(2), atomic.LoadInt32(countReq), "no request should be sent")
+}
+
+func TestClient_Translate_cache_key_by_options(t *testing.T) {
+	t.Parallel()
+
+	cli, teardown, countReq := spawnEchoServer(t)
+	defer teardown()
+
+	require.NoError(t, WithCache(NewMemoryCache(0, 0))(cli))
+
+	for _, opts := range []*TranslateOptions{
+		{SourceLang: "EN", TargetLang: "DE"},
+		{SourceLang: "EN", TargetLang: "FR"},
+		{SourceLang: "EN", TargetLang: "DE", Formality: FormalityMore},
+		{SourceLang: "EN", TargetLang: "DE", GlossaryID: "def3a26b-3e84-45b3-84ae-0c0aaf3525f7"},
+		{SourceLang: "EN", TargetLang: "DE", ShowBilledCharacters: true}, // same as the first
+	} {
+		_, err := cli.Translate(context.Background(), []string{"Hello"}, opts)
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, int32(4), atomic.LoadInt32(countReq))
+	require.Equal(t, CacheStats{Hits: 1, Misses: 4}, cli.CacheStats())
+}
+
+// brokenCache is a CacheBackend that always fails.
+type brokenCache struct{}
+
+func (brokenCache) Get(context.Context, string) ([]byte, bool, error) {
+	return nil, false, errors.New("forced get error")
+}
+
+func (brokenCache) Set(context.Context, string, []byte) error {
+	return errors.New("forced set error")
+}
+
+func TestClient_Translate_cache_backend_error(t *testing.T) {
+	t.Parallel()
+
+	cli, teardown, _ := spawnEchoServer(t)
+	defer teardown()
+
+	var logOut strings.Builder
+
+	cli.Logger.SetOutput(&logOut)
+
+	require.NoError(t, WithCache(brokenCache{})(cli))
+
+	resp, err := cli.TranslateSentence(context.Background(), "Hello", "EN", "DE")
+	require.NoError(t, err, "cache errors should not fail the translation")
+	require.Equal(t, "DE:Hello", resp.Translations[0].Text)
+
+	assert.Contains(t, logOut.String(), "forced get error")
+	assert.Contains(t, logOut.String(), "forced set error")
+	require.Equal(t, CacheStats{Hits: 0, Misses: 1}, cli.CacheStats())
+}
+
+func TestClient_CacheStats_no_cache(t *testing.T) {
+	t.Parallel()
+
+	cli, err := New(APIFree, nil)
+	require.NoError(t, err)
+
+	require.Equal(t, CacheStats{}, cli.CacheStats())
+	require.Zero(t, cli.CacheStats().HitRatio())
+}
+
+// ----------------------------------------------------------------------------
+//  MemoryCache
+// ----------------------------------------------------------------------------
+
+func TestMemoryCache_lru(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	cache := NewMemoryCache(2, 0)
+
+	require.NoError(t, cache.Set(ctx, "a", []byte("1")))
+	require.NoError(t, cache.Set(ctx, "b", []byte("2")))
+
+	// "a" becomes the most recently used
+	_, ok, err := cache.Get(ctx, "a")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	require.NoError(t, cache.Set(ctx, "c", []byte("3")))
+	require.Equal(t, 2, cache.Len())
+
+	_, ok, _ = cache.Get(ctx, "b")
+	require.False(t, ok, "least recently used entry should be evicted")
+
+	value, ok, _ := cache.Get(ctx, "a")
+	require.True(t, ok)
+	require.Equal(t, []byte("1"), value)
+
+	// Overwrite
+	require.NoError(t, cache.Set(ctx, "a", []byte("updated")))
+
+	value, _, _ = cache.Get(ctx, "a")
+	require.Equal(t, []byte("updated"), value)
+	require.Equal(t, 2, cache.Len())
+}
+
+func TestMemoryCache_ttl(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	cache := NewMemoryCache(0, 10*time.Millisecond)
+
+	require.NoError(t, cache.Set(ctx, "a", []byte("1")))
+
+	_, ok, _ := cache.Get(ctx, "a")
+	require.True(t, ok)
+
+	time.Sleep(20 * time.Millisecond)
+
+	_, ok, _ = cache.Get(ctx, "a")
+	require.False(t, ok, "expired entry should be missing")
+	require.Zero(t, cache.Len(), "expired entry should be evicted")
+}
+
+// ----------------------------------------------------------------------------
+//  FileCache
+// ----------------------------------------------------------------------------
+
+func TestFileCache(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "cache")
+
+	cache, err := NewFileCache(dir, time.Hour)
+	require.NoError(t, err)
+
+	_, ok, err := cache.Get(ctx, "a")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	require.NoError(t, cache.Set(ctx, "a", []byte("1")))
+
+	// Persists across the instances
+	cache2, err := NewFileCache(dir, time.Hour)
+	require.NoError(t, err)
+
+	value, ok, err := cache2.Get(ctx, "a")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, []byte("1"), value)
+
+	// Expired
+	old := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "a"), old, old))
+
+	_, ok, err = cache2.Get(ctx, "a")
+	require.NoError(t, err)
+	require.False(t, ok, "expired entry should be missing")
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "temporary files should be removed")
+}
+
+func TestNewFileCache_bad_dir(t *testing.T) {
+	t.Parallel()
+
+	pathFile := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(pathFile, nil, 0o600))
+
+	_, err := NewFileCache(filepath.Join(pathFile, "cache"), 0)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to create cache directory")
+}
+
+func TestClient_Translate_file_cache(t *testing.T) {
+	t.Parallel()
+
+	cli, teardown, countReq := spawnEchoServer(t)
+	defer teardown()
+
+	cache, err := NewFileCache(t.TempDir(), 0)
+	require.NoError(t, err)
+	require.NoError(t, WithCache(cache)(cli))
+
+	for i := 0; i < 2; i++ {
+		resp, err := cli.TranslateSentence(context.Background(), "Hello", "EN", "DE")
+		require.NoError(t, err)
+		require.Equal(t, "DE:Hello", resp.Translations[0].Text)
+	}
+
+	require.Equal(t, int32(1), atomic.LoadInt32(countReq))
+}
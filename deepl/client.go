@@ -2,11 +2,12 @@ package deepl
 
 import (
 	"context"
+	"io"
 	"log"
 	"net/http"
 	"net/url"
-	"os"
 	"path"
+	"strings"
 )
 
 // ----------------------------------------------------------------------------
@@ -18,6 +19,25 @@ type Client struct {
 	BaseURL    *url.URL
 	HTTPClient *http.Client
 	Logger     *log.Logger
+	// KeyProvider provides the API key for each request. If nil, the key is read
+	// from the environment variable named NameEnvKeyAPI.
+	KeyProvider KeyProvider
+	// UserAgent is the user agent used in the HTTP requests. If empty, the global
+	// UserAgent is used.
+	UserAgent string
+	// RetryPolicy configures the automatic retries of the failed requests. If
+	// nil, the requests are not retried.
+	RetryPolicy *RetryPolicy
+	// ValidateLanguages enables the client-side validation of the source and
+	// target languages before sending the translation requests. The supported
+	// languages are fetched once and cached in the client.
+	ValidateLanguages bool
+
+	limiter   *rateLimiter
+	budget    *budgetGuard
+	cache     *translationCache
+	apiType   APIType
+	langCache languageCache
 }
 
 // ----------------------------------------------------------------------------
@@ -25,9 +45,21 @@ type Client struct {
 // ----------------------------------------------------------------------------
 
 // New returns a new Client instance.
-// It will request to the given rawBaseURL and use the given logger. If the logger
-// is nil, it will use the default logger which simply logs to stderr.
-func New(apiType APIType, logger *log.Logger) (*Client, error) {
+// It will request to the base URL of the given apiType and use the given logger.
+// If the logger is nil, it will use the default logger which simply logs to
+// stderr.
+//
+// The client can be configured further with the functional options. Such as
+// WithBaseURL, WithUserAgent, WithHTTPClient, WithLogger, WithKeyProvider,
+// WithRetryPolicy, WithRateLimit, WithBudget and WithCache.
+// The options are applied in order and override the arguments.
+//
+// The API key is read from the environment variable named NameEnvKeyAPI by
+// default. Use WithAPIKey or WithKeyProvider to use a different key per client.
+//
+// With APIAuto, the base URL is selected from the API key on each request
+// unless WithBaseURL is given.
+func New(apiType APIType, logger *log.Logger, opts ...Option) (*Client, error) {
 	rawBaseURL := apiType.BaseURL()
 
 	baseURL, err := url.Parse(rawBaseURL)
@@ -36,14 +68,23 @@ func New(apiType APIType, logger *log.Logger) (*Client, error) {
 	}
 
 	if logger == nil {
-		logger = log.New(os.Stderr, "[Log]", log.LstdFlags)
+		logger = newDefaultLogger()
 	}
 
-	return &Client{
+	cli := &Client{
 		BaseURL:    baseURL,
 		HTTPClient: http.DefaultClient,
 		Logger:     logger,
-	}, nil
+		apiType:    apiType,
+	}
+
+	for index, opt := range opts {
+		if err := opt(cli); err != nil {
+			return nil, WrapIfErr(err, "failed to apply option #%d", index+1)
+		}
+	}
+
+	return cli, nil
 }
 
 // ----------------------------------------------------------------------------
@@ -51,41 +92,15 @@ func New(apiType APIType, logger *log.Logger) (*Client, error) {
 // ----------------------------------------------------------------------------
 
 // GetAccountStatus returns the account status.
-// The API key is retrieved via getAPIKey() function which tries to get the API
-// key from the environment variable "DEEPL_API_KEY".
 func (c *Client) GetAccountStatus(ctx context.Context) (*AccountStatus, error) {
-	apiKey, err := getAPIKey()
+	req, err := c.newRequest(ctx, http.MethodPost, "v2/usage", nil)
 	if err != nil {
-		return nil, WrapIfErr(err, "failed to get API key")
-	}
-
-	// Set endpoint path of the API
-	reqURL := *c.BaseURL
-	reqURL.Path = path.Join(reqURL.Path, "v2", "usage")
-
-	// Set query parameters
-	urlVal := reqURL.Query()
-
-	urlVal.Add("auth_key", apiKey)
-
-	reqURL.RawQuery = urlVal.Encode()
-
-	// Make new request
-	req, err := http.NewRequest(http.MethodPost, reqURL.String(), nil)
-	if err != nil {
-		return nil, WrapIfErr(err, "failed to create request")
+		return nil, err
 	}
 
-	// Set header
-	req.Header.Set("User-Agent", UserAgent)
-
-	// Set context
-	req = req.WithContext(ctx)
-
-	// Request
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
-		return nil, WrapIfErr(err, "failed to send http request")
+		return nil, err
 	}
 
 	defer resp.Body.Close()
@@ -105,41 +120,132 @@ func (c *Client) TranslateSentence(
 	sourceLang string,
 	targetLang string,
 ) (*TranslateResponse, error) {
-	apiKey, err := getAPIKey()
+	return c.Translate(ctx, []string{text}, &TranslateOptions{
+		SourceLang: sourceLang,
+		TargetLang: targetLang,
+	})
+}
+
+// TranslateTexts translates the given texts from the sourceLang to the targetLang.
+//
+// It is a shorthand of Translate with only the source and target languages set.
+func (c *Client) TranslateTexts(
+	ctx context.Context,
+	texts []string,
+	sourceLang string,
+	targetLang string,
+) (*TranslateResponse, error) {
+	return c.Translate(ctx, texts, &TranslateOptions{
+		SourceLang: sourceLang,
+		TargetLang: targetLang,
+	})
+}
+
+// Translate translates the given texts with the given options.
+//
+// The texts are packed into as few requests as possible, honoring the DeepL API
+// limits of MaxTextsPerRequest texts and MaxRequestSize bytes per request. The
+// translations in the returned response are aligned index-for-index with the
+// given texts. If the cache is set by WithCache, only the texts not in the cache
+// are requested.
+func (c *Client) Translate(
+	ctx context.Context,
+	texts []string,
+	opts *TranslateOptions,
+) (*TranslateResponse, error) {
+	if opts == nil {
+		return nil, NewErr("translate options is nil")
+	}
+
+	if err := opts.Validate(); err != nil {
+		return nil, WrapIfErr(err, "invalid translate options")
+	}
+
+	if err := c.validateLanguages(ctx, opts.SourceLang, opts.TargetLang, opts.Formality); err != nil {
+		return nil, WrapIfErr(err, "invalid language")
+	}
+
+	result := &TranslateResponse{
+		Translations: make([]Translation, len(texts)),
+	}
+
+	// Indices of the texts to request. Which are the ones not in the cache.
+	pending := c.cache.lookup(ctx, c.Logger, texts, opts, result.Translations)
+
+	pendingTexts := make([]string, len(pending))
+	for i, index := range pending {
+		pendingTexts[i] = texts[index]
+	}
+
+	// Size of the parameters common to all the requests
+	batches, err := packTexts(pendingTexts, len(opts.urlValues().Encode()))
 	if err != nil {
-		return nil, WrapIfErr(err, "failed to get API key")
+		return nil, WrapIfErr(err, "failed to pack texts into requests")
 	}
 
-	// Set endpoint path of the API
-	reqURL := *c.BaseURL
-	reqURL.Path = path.Join(reqURL.Path, "v2", "translate")
+	offset := 0
 
-	// Set query parameters
-	urlVal := reqURL.Query()
+	for index, batch := range batches {
+		transResp, err := c.translateBatch(ctx, batch, opts)
+		if err != nil {
+			return nil, WrapIfErr(err, "failed to translate batch #%d", index+1)
+		}
 
-	urlVal.Add("auth_key", apiKey)
-	urlVal.Add("text", text)
-	urlVal.Add("target_lang", targetLang)
-	urlVal.Add("source_lang", sourceLang)
+		if len(transResp.Translations) != len(batch) {
+			return nil, NewErr(
+				"number of translations mismatch in batch #%d. Requested: %d, Returned: %d",
+				index+1, len(batch), len(transResp.Translations),
+			)
+		}
 
-	reqURL.RawQuery = urlVal.Encode()
+		for i, trans := range transResp.Translations {
+			indexText := pending[offset+i]
 
-	// Make new request
-	req, err := http.NewRequest(http.MethodPost, reqURL.String(), nil)
+			result.Translations[indexText] = trans
+			c.cache.store(ctx, c.Logger, texts[indexText], opts, trans)
+		}
+
+		offset += len(batch)
+	}
+
+	return result, nil
+}
+
+// ----------------------------------------------------------------------------
+//  Private Methods
+// ----------------------------------------------------------------------------
+
+// translateBatch requests a single translation request to the API with the
+// given texts and options.
+func (c *Client) translateBatch(
+	ctx context.Context,
+	texts []string,
+	opts *TranslateOptions,
+) (*TranslateResponse, error) {
+	urlVal := opts.urlValues()
+
+	for _, text := range texts {
+		urlVal.Add("text", text)
+	}
+
+	characters := countCharacters(texts)
+
+	settle, err := c.reserveBudget(ctx, characters)
 	if err != nil {
-		return nil, WrapIfErr(err, "failed to create request")
+		return nil, err
 	}
 
-	// Set header
-	req.Header.Set("User-Agent", UserAgent)
+	billed := 0
+	defer func() { settle(billed) }()
 
-	// Set context
-	req = req.WithContext(ctx)
+	req, err := c.newRequest(withCharacters(ctx, characters), http.MethodPost, "v2/translate", urlVal)
+	if err != nil {
+		return nil, err
+	}
 
-	// Request
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
-		return nil, WrapIfErr(err, "failed to send http request")
+		return nil, err
 	}
 
 	defer resp.Body.Close()
@@ -149,5 +255,155 @@ func (c *Client) TranslateSentence(
 		return nil, WrapIfErr(err, "failed to parse response to TranslateResponse")
 	}
 
+	billed = billedCharacters(&transResp, texts)
+
 	return &transResp, nil
 }
+
+// requestJSON sends a request to the given endpoint of the API and parses the
+// JSON response into outStruct.
+func (c *Client) requestJSON(
+	ctx context.Context,
+	method string,
+	endpoint string,
+	urlVal url.Values,
+	outStruct interface{},
+) error {
+	req, err := c.newRequest(ctx, method, endpoint, urlVal)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	return responseParse(resp, outStruct)
+}
+
+// newRequest creates a new request to the given endpoint of the API with the
+// given parameters and the API key set.
+//
+// The parameters are sent as a form-encoded body for POST requests and as the
+// URL query for the other methods.
+func (c *Client) newRequest(
+	ctx context.Context,
+	method string,
+	endpoint string,
+	urlVal url.Values,
+) (*http.Request, error) {
+	return c.newRequestWithBody(ctx, method, endpoint, urlVal, nil, "")
+}
+
+// newRequestWithBody is the same as newRequest but with the given request body
+// and its content type. If the body is given, the parameters are always sent as
+// the URL query.
+//
+// The API key is retrieved from the KeyProvider of the client, which defaults to
+// the environment variable "DEEPL_API_KEY". It is sent via the "Authorization"
+// header to avoid leaking it in the URL.
+func (c *Client) newRequestWithBody(
+	ctx context.Context,
+	method string,
+	endpoint string,
+	urlVal url.Values,
+	body io.Reader,
+	contentType string,
+) (*http.Request, error) {
+	apiKey, err := c.apiKey(ctx)
+	if err != nil {
+		return nil, WrapIfErr(err, "failed to get API key")
+	}
+
+	// Set endpoint path of the API
+	reqURL, err := c.resolveBaseURL(apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	reqURL.Path = path.Join(reqURL.Path, endpoint)
+
+	// Set parameters
+	switch {
+	case body == nil && method == http.MethodPost:
+		body = strings.NewReader(urlVal.Encode())
+		contentType = "application/x-www-form-urlencoded"
+	case len(urlVal) != 0:
+		reqURL.RawQuery = urlVal.Encode()
+	}
+
+	// Make new request
+	req, err := http.NewRequest(method, reqURL.String(), body)
+	if err != nil {
+		return nil, WrapIfErr(err, "failed to create request")
+	}
+
+	// Set header
+	req.Header.Set("Authorization", "DeepL-Auth-Key "+apiKey)
+	req.Header.Set("User-Agent", c.userAgent())
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	// Set context
+	return req.WithContext(ctx), nil
+}
+
+// resolveBaseURL returns a copy of the base URL to request with the given API
+// key.
+//
+// For APIAuto with no base URL set, it is detected from the API key. It returns
+// an error if the key is for the free account but the host is for the pro
+// account, and vice versa.
+func (c *Client) resolveBaseURL(apiKey string) (url.URL, error) {
+	if c.BaseURL == nil {
+		return url.URL{}, NewErr("base URL is not set")
+	}
+
+	baseURL := *c.BaseURL
+
+	if c.apiType == APIAuto && baseURL.Host == "" {
+		autoURL, err := url.Parse(APITypeFromKey(apiKey).BaseURL())
+		if err != nil {
+			return url.URL{}, WrapIfErr(err, "failed to parse URL")
+		}
+
+		baseURL = *autoURL
+	}
+
+	switch {
+	case baseURL.Host == hostPro && IsFreeKey(apiKey):
+		return url.URL{}, WrapIfErr(ErrAPIKeyMismatch,
+			"The key of the free account (ending with %q) can not be used with %s. Use APIFree or APIAuto",
+			suffixFreeKey, hostPro,
+		)
+	case baseURL.Host == hostFree && !IsFreeKey(apiKey):
+		return url.URL{}, WrapIfErr(ErrAPIKeyMismatch,
+			"The key of the pro account can not be used with %s. Use APIPro or APIAuto",
+			hostFree,
+		)
+	}
+
+	return baseURL, nil
+}
+
+// userAgent returns the user agent of the client. If not set, it falls back to
+// the global UserAgent.
+func (c *Client) userAgent() string {
+	if c.UserAgent != "" {
+		return c.UserAgent
+	}
+
+	return UserAgent
+}
+
+// do sends the given request with the retries according to the RetryPolicy and
+// the rate limits set by WithRateLimit. The caller must close the response body
+// to release the in-flight slot.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	return c.doWithRetry(req)
+}
@@ -0,0 +1,97 @@
+package deepl
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// Option is a functional option to configure a Client in New.
+type Option func(*Client) error
+
+// WithBaseURL sets the base URL of the API for the client. Such as a local stub
+// server for testing. It takes precedence over the APIType and SetCustomURL.
+func WithBaseURL(rawBaseURL string) Option {
+	return func(c *Client) error {
+		baseURL, err := url.Parse(rawBaseURL)
+		if err != nil {
+			return WrapIfErr(err, "failed to parse URL")
+		}
+
+		c.BaseURL = baseURL
+
+		return nil
+	}
+}
+
+// WithUserAgent sets the user agent used in the HTTP requests of the client.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) error {
+		if userAgent == "" {
+			return NewErr("user agent is empty")
+		}
+
+		c.UserAgent = userAgent
+
+		return nil
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to send the requests. Use it to set
+// timeouts, proxies and custom transports.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) error {
+		if httpClient == nil {
+			return NewErr("HTTP client is nil")
+		}
+
+		c.HTTPClient = httpClient
+
+		return nil
+	}
+}
+
+// WithLogger sets the logger of the client. If nil, the default logger which
+// simply logs to stderr is used.
+func WithLogger(logger *log.Logger) Option {
+	return func(c *Client) error {
+		if logger == nil {
+			logger = newDefaultLogger()
+		}
+
+		c.Logger = logger
+
+		return nil
+	}
+}
+
+// WithKeyProvider sets the KeyProvider to get the API key for each request.
+func WithKeyProvider(provider KeyProvider) Option {
+	return func(c *Client) error {
+		if provider == nil {
+			return NewErr("key provider is nil")
+		}
+
+		c.KeyProvider = provider
+
+		return nil
+	}
+}
+
+// WithAPIKey sets the API key of the client. It is a shorthand of
+// WithKeyProvider(StaticKey(apiKey)).
+func WithAPIKey(apiKey string) Option {
+	return func(c *Client) error {
+		if apiKey == "" {
+			return NewErr("API key is empty")
+		}
+
+		return WithKeyProvider(StaticKey(apiKey))(c)
+	}
+}
+
+// newDefaultLogger returns the default logger which simply logs to stderr.
+func newDefaultLogger() *log.Logger {
+	return log.New(os.Stderr, "[Log]", log.LstdFlags)
+}
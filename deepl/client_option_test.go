@@ -0,0 +1,101 @@
+package deepl
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_with_options(t *testing.T) {
+	t.Parallel()
+
+	httpClient := new(http.Client)
+	logger := log.New(new(bytes.Buffer), "", 0)
+
+	cli, err := New(APIFree, nil,
+		WithBaseURL("http://localhost:8080"),
+		WithUserAgent("MyApp/1.0"),
+		WithHTTPClient(httpClient),
+		WithLogger(logger),
+		WithAPIKey(dummyAuthKey),
+	)
+
+	require.NoError(t, err, "valid options should not return an error")
+	require.Equal(t, "http://localhost:8080", cli.BaseURL.String(),
+		"WithBaseURL should override the URL of the APIType")
+	require.Equal(t, "MyApp/1.0", cli.userAgent())
+	require.Same(t, httpClient, cli.HTTPClient)
+	require.Same(t, logger, cli.Logger)
+
+	apiKey, err := cli.apiKey(context.TODO())
+
+	require.NoError(t, err)
+	require.Equal(t, dummyAuthKey, apiKey)
+}
+
+func TestNew_options_do_not_leak(t *testing.T) {
+	t.Parallel()
+
+	cliStub, err := New(APIFree, nil, WithBaseURL("http://localhost:8080"), WithUserAgent("Stub"))
+	require.NoError(t, err)
+
+	cliProd, err := New(APIFree, nil)
+	require.NoError(t, err)
+
+	require.Equal(t, "http://localhost:8080", cliStub.BaseURL.String())
+	require.Equal(t, "https://api-free.deepl.com", cliProd.BaseURL.String(),
+		"options of a client should not affect the other clients")
+	require.Equal(t, UserAgentDefault, cliProd.userAgent(),
+		"client without user agent should use the default")
+}
+
+func TestNew_bad_options(t *testing.T) {
+	t.Parallel()
+
+	for index, tt := range []struct {
+		opt              Option
+		expectErrMessage string
+	}{
+		{WithBaseURL("http://badurl.with.control.char/\n"), "failed to parse URL"},
+		{WithUserAgent(""), "user agent is empty"},
+		{WithHTTPClient(nil), "HTTP client is nil"},
+		{WithKeyProvider(nil), "key provider is nil"},
+		{WithAPIKey(""), "API key is empty"},
+	} {
+		cli, err := New(APIFree, nil, WithLogger(nil), tt.opt)
+
+		require.Error(t, err, "test #%d: invalid option should return an error", index+1)
+		require.Nil(t, cli, "test #%d: returned client should be nil on error", index+1)
+		assert.Contains(t, err.Error(), "failed to apply option #2",
+			"test #%d: it should contain the error reason", index+1)
+		assert.Contains(t, err.Error(), tt.expectErrMessage,
+			"test #%d: it should contain the underlying error reason", index+1)
+	}
+}
+
+func TestClient_UserAgent(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(respWriter http.ResponseWriter, req *http.Request) {
+		require.Equal(t, "MyApp/1.0", req.Header.Get("User-Agent"),
+			"user agent of the client should be sent")
+
+		_, err := respWriter.Write([]byte(`{"character_count":1,"character_limit":2}`))
+		require.NoError(t, err, "failed to write response body")
+	}))
+	defer server.Close()
+
+	cli := newTestClient(t, server)
+	require.NoError(t, WithUserAgent("MyApp/1.0")(cli))
+
+	status, err := cli.GetAccountStatus(context.TODO())
+
+	require.NoError(t, err)
+	require.Equal(t, int64(2), status.CharacterLimit)
+}
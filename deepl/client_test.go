@@ -6,7 +6,9 @@ import (
 	"log"
 	"net/http"
 	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -44,9 +46,8 @@ func TestNew_bad_custom_url(t *testing.T) {
 //  Client.GetAccountStatus
 // ----------------------------------------------------------------------------
 
-//nolint:paralleltest // do not parallelize due to temporary env var change
 func TestClient_GetAccountStatus(t *testing.T) {
-	t.Setenv(NameEnvKeyAPI, dummyAuthKey) // Set dummy DeepL API key
+	t.Parallel()
 
 	//nolint:varnamelen // tt is a test case by habit
 	for index, tt := range dataGetAccountStatus {
@@ -59,15 +60,14 @@ func TestClient_GetAccountStatus(t *testing.T) {
 				tt.mockResponseBodyFile,
 				tt.expectMethod,
 				tt.expectRequestPath,
-				tt.expectRawQuery,
+				tt.expectRawParams,
 			)
 			defer teardown()
 
 			actualResponse, err := cli.GetAccountStatus(context.Background())
 			if tt.expectErrMessage == "" {
 				require.NoError(t, err, "response error should be nil on success")
-				require.Equal(t, tt.expectResponse.CharacterCount, actualResponse.CharacterCount, "response items wrong")
-				require.Equal(t, tt.expectResponse.CharacterLimit, actualResponse.CharacterLimit, "response items wrong")
+				require.Equal(t, tt.expectResponse, actualResponse, "response items wrong")
 			} else {
 				require.Error(t, err, "response error should not be nil on error case")
 			}
@@ -99,23 +99,17 @@ func TestClient_GetAccountStatus_no_env_set(t *testing.T) {
 		"it should contain the underlying error reason")
 }
 
-//nolint:paralleltest // do not parallelize due to temporary env var change
 func TestClient_GetAccountStatus_bad_scheme(t *testing.T) {
-	SetCustomURL("http://0.0.0.0:0") // Set dummy base URL
-
-	defer func() {
-		SetCustomURL("")
-	}()
-
-	t.Setenv(NameEnvKeyAPI, dummyAuthKey) // Set dummy DeepL API key
+	t.Parallel()
 
 	badURL := new(url.URL)
 	badURL.Host = "\n" // Set invalid host
 
 	cli := &Client{
-		BaseURL:    badURL,
-		HTTPClient: http.DefaultClient,
-		Logger:     &log.Logger{},
+		BaseURL:     badURL,
+		HTTPClient:  http.DefaultClient,
+		Logger:      &log.Logger{},
+		KeyProvider: StaticKey(dummyAuthKey),
 	}
 	act, err := cli.GetAccountStatus(context.TODO())
 
@@ -127,20 +121,14 @@ func TestClient_GetAccountStatus_bad_scheme(t *testing.T) {
 		"it should contain the error reason")
 }
 
-//nolint:paralleltest // do not parallelize due to temporary env var change
 func TestClient_GetAccountStatus_fail_request(t *testing.T) {
-	SetCustomURL("http://0.0.0.0:0") // Set dummy base URL
-
-	defer func() {
-		SetCustomURL("")
-	}()
-
-	t.Setenv(NameEnvKeyAPI, dummyAuthKey) // Set dummy DeepL API key
+	t.Parallel()
 
 	cli := &Client{
-		BaseURL:    new(url.URL),
-		HTTPClient: http.DefaultClient,
-		Logger:     &log.Logger{},
+		BaseURL:     new(url.URL),
+		HTTPClient:  http.DefaultClient,
+		Logger:      &log.Logger{},
+		KeyProvider: StaticKey(dummyAuthKey),
 	}
 
 	act, err := cli.GetAccountStatus(context.TODO())
@@ -153,9 +141,8 @@ func TestClient_GetAccountStatus_fail_request(t *testing.T) {
 		"it should contain the error reason")
 }
 
-//nolint:paralleltest // do not parallelize due to temporary env var change
 func TestClient_GetAccountStatus_bad_response_format(t *testing.T) {
-	t.Setenv(NameEnvKeyAPI, dummyAuthKey) // Set dummy DeepL API key
+	t.Parallel()
 
 	cli, teardown := spawnTestServer(
 		t,
@@ -163,7 +150,7 @@ func TestClient_GetAccountStatus_bad_response_format(t *testing.T) {
 		"testdata/GetAccountStatus/malformed-body",
 		http.MethodPost,
 		"/v2/usage",
-		fmt.Sprintf("auth_key=%s", dummyAuthKey),
+		"",
 	)
 	defer teardown()
 
@@ -181,9 +168,8 @@ func TestClient_GetAccountStatus_bad_response_format(t *testing.T) {
 //  Client.TranslateSentence
 // ----------------------------------------------------------------------------
 
-//nolint:paralleltest // do not parallelize due to temporary env var change
 func TestClient_TranslateSentence(t *testing.T) {
-	t.Setenv(NameEnvKeyAPI, dummyAuthKey) // Set dummy DeepL API key
+	t.Parallel()
 
 	//nolint:varnamelen // tt is a test case by habit
 	for index, tt := range dataTranslateSentence {
@@ -196,7 +182,7 @@ func TestClient_TranslateSentence(t *testing.T) {
 				tt.mockResponseBodyFile,
 				tt.expectMethod,
 				tt.expectRequestPath,
-				tt.expectRawQuery,
+				tt.expectRawParams,
 			)
 			defer teardown()
 
@@ -234,7 +220,7 @@ func TestClient_TranslateSentence(t *testing.T) {
 	}
 }
 
-//nolint:paralleltest // do not parallelize due to temporary env var change
+//nolint:paralleltest // do not parallelize due to global variable change
 func TestClient_TranslateSentence_no_env_set(t *testing.T) {
 	oldNameEnvKeyAPI := NameEnvKeyAPI
 	defer func() {
@@ -263,23 +249,17 @@ func TestClient_TranslateSentence_no_env_set(t *testing.T) {
 		"it should contain the underlying error reason")
 }
 
-//nolint:paralleltest // do not parallelize due to temporary env var change
 func TestClient_TranslateSentence_bad_scheme(t *testing.T) {
-	SetCustomURL("http://0.0.0.0:0") // Set dummy base URL
-
-	defer func() {
-		SetCustomURL("")
-	}()
-
-	t.Setenv(NameEnvKeyAPI, dummyAuthKey) // Set dummy DeepL API key
+	t.Parallel()
 
 	badURL := new(url.URL)
 	badURL.Host = "\n" // Set invalid host
 
 	cli := &Client{
-		BaseURL:    badURL,
-		HTTPClient: http.DefaultClient,
-		Logger:     &log.Logger{},
+		BaseURL:     badURL,
+		HTTPClient:  http.DefaultClient,
+		Logger:      &log.Logger{},
+		KeyProvider: StaticKey(dummyAuthKey),
 	}
 	act, err := cli.TranslateSentence(
 		context.TODO(),
@@ -296,20 +276,14 @@ func TestClient_TranslateSentence_bad_scheme(t *testing.T) {
 		"it should contain the error reason")
 }
 
-//nolint:paralleltest // do not parallelize due to temporary env var change
 func TestClient_TranslateSentence_fail_request(t *testing.T) {
-	SetCustomURL("http://0.0.0.0:0") // Set dummy base URL
-
-	defer func() {
-		SetCustomURL("")
-	}()
-
-	t.Setenv(NameEnvKeyAPI, dummyAuthKey) // Set dummy DeepL API key
+	t.Parallel()
 
 	cli := &Client{
-		BaseURL:    new(url.URL),
-		HTTPClient: http.DefaultClient,
-		Logger:     &log.Logger{},
+		BaseURL:     new(url.URL),
+		HTTPClient:  http.DefaultClient,
+		Logger:      &log.Logger{},
+		KeyProvider: StaticKey(dummyAuthKey),
 	}
 
 	act, err := cli.TranslateSentence(
@@ -327,6 +301,215 @@ func TestClient_TranslateSentence_fail_request(t *testing.T) {
 		"it should contain the error reason")
 }
 
+// ----------------------------------------------------------------------------
+//  Client.TranslateTexts
+// ----------------------------------------------------------------------------
+
+func TestClient_TranslateTexts(t *testing.T) {
+	t.Parallel()
+
+	cli, teardown, countReq := spawnEchoServer(t)
+	defer teardown()
+
+	// 2.5 times more than the limit of texts per request
+	texts := make([]string, MaxTextsPerRequest*5/2)
+	for index := range texts {
+		texts[index] = fmt.Sprintf("text #%d", index)
+	}
+
+	actualResponse, err := cli.TranslateTexts(context.TODO(), texts, "EN", "JA")
+
+	require.NoError(t, err, "response error should be nil on success")
+	require.Equal(t, int32(3), *countReq,
+		"texts should be packed into the minimum number of requests")
+	require.Len(t, actualResponse.Translations, len(texts),
+		"number of translations should be the same as the input")
+
+	for index, transVal := range actualResponse.Translations {
+		require.Equal(t, "JA:"+texts[index], transVal.Text,
+			"translations should be aligned with the input")
+		require.Equal(t, "EN", transVal.DetectedSourceLanguage,
+			"response items wrong")
+	}
+}
+
+func TestClient_TranslateTexts_empty(t *testing.T) {
+	t.Parallel()
+
+	cli, teardown, countReq := spawnEchoServer(t)
+	defer teardown()
+
+	actualResponse, err := cli.TranslateTexts(context.TODO(), nil, "EN", "JA")
+
+	require.NoError(t, err, "empty input should not be an error")
+	require.Empty(t, actualResponse.Translations,
+		"empty input should return empty translations")
+	require.Equal(t, int32(0), *countReq,
+		"empty input should not send any request")
+}
+
+func TestClient_TranslateTexts_too_large_text(t *testing.T) {
+	t.Parallel()
+
+	cli, teardown, countReq := spawnEchoServer(t)
+	defer teardown()
+
+	texts := []string{"hello", strings.Repeat("a", MaxRequestSize)}
+
+	act, err := cli.TranslateTexts(context.TODO(), texts, "EN", "JA")
+
+	require.Error(t, err,
+		"it should return an error if a text exceeds the request size limit")
+	require.Nil(t, act,
+		"returned value should be nil on error")
+	assert.Contains(t, err.Error(), "failed to pack texts into requests",
+		"it should contain the error reason")
+	assert.Contains(t, err.Error(), "text #2 is too large to send",
+		"it should contain the underlying error reason")
+	require.Equal(t, int32(0), *countReq,
+		"no request should be sent if the texts can not be packed")
+}
+
+func TestClient_TranslateTexts_mismatch_response(t *testing.T) {
+	t.Parallel()
+
+	cli, teardown := spawnTestServer(
+		t,
+		"testdata/TranslateText/success-header",
+		"testdata/TranslateText/success-body",
+		http.MethodPost,
+		"/v2/translate",
+		"source_lang=EN&target_lang=JA&text=hello&text=world",
+	)
+	defer teardown()
+
+	act, err := cli.TranslateTexts(context.TODO(), []string{"hello", "world"}, "EN", "JA")
+
+	require.Error(t, err,
+		"it should return an error if the number of translations mismatch")
+	require.Nil(t, act,
+		"returned value should be nil on error")
+	assert.Contains(t, err.Error(), "number of translations mismatch in batch #1",
+		"it should contain the error reason")
+}
+
+func TestClient_TranslateTexts_fail_request(t *testing.T) {
+	t.Parallel()
+
+	cli := &Client{
+		BaseURL:     new(url.URL),
+		HTTPClient:  http.DefaultClient,
+		Logger:      &log.Logger{},
+		KeyProvider: StaticKey(dummyAuthKey),
+	}
+
+	act, err := cli.TranslateTexts(context.TODO(), []string{"hello"}, "EN", "JA")
+
+	require.Error(t, err,
+		"it should return an error if sending request fails")
+	require.Nil(t, act,
+		"returned value should be nil on error")
+	assert.Contains(t, err.Error(), "failed to translate batch #1",
+		"it should contain the error reason")
+	assert.Contains(t, err.Error(), "failed to send http request",
+		"it should contain the underlying error reason")
+}
+
+// ----------------------------------------------------------------------------
+//  Client.Translate
+// ----------------------------------------------------------------------------
+
+func TestClient_Translate(t *testing.T) {
+	t.Parallel()
+
+	cli, teardown := spawnTestServer(
+		t,
+		"testdata/TranslateText/success-header",
+		"testdata/TranslateText/success-body",
+		http.MethodPost,
+		"/v2/translate",
+		"context=greeting&formality=less&model_type=latency_optimized"+
+			"&preserve_formatting=1&split_sentences=0&target_lang=JA&text=hello",
+	)
+	defer teardown()
+
+	actualResponse, err := cli.Translate(context.TODO(), []string{"hello"}, &TranslateOptions{
+		TargetLang:         "JA",
+		Context:            "greeting",
+		Formality:          FormalityLess,
+		SplitSentences:     SplitSentencesOff,
+		ModelType:          ModelTypeLatencyOptimized,
+		PreserveFormatting: true,
+	})
+
+	require.NoError(t, err, "response error should be nil on success")
+	require.Len(t, actualResponse.Translations, 1)
+	require.Equal(t, "こんにちわ", actualResponse.Translations[0].Text,
+		"response items wrong")
+}
+
+func TestClient_Translate_tag_handling(t *testing.T) {
+	t.Parallel()
+
+	cli, teardown := spawnTestServer(
+		t,
+		"testdata/TranslateText/success-header",
+		"testdata/TranslateText/success-body",
+		http.MethodPost,
+		"/v2/translate",
+		"ignore_tags=code%2Cpre&non_splitting_tags=b&outline_detection=0"+
+			"&splitting_tags=p%2Cli&tag_handling=html&target_lang=JA"+
+			"&text=%3Cp%3Ehello%3C%2Fp%3E",
+	)
+	defer teardown()
+
+	actualResponse, err := cli.Translate(context.TODO(), []string{"<p>hello</p>"}, &TranslateOptions{
+		TargetLang:              "JA",
+		TagHandling:             TagHandlingHTML,
+		NonSplittingTags:        NewTags("b"),
+		SplittingTags:           NewTags("p", "li"),
+		IgnoreTags:              NewTags("code", "pre"),
+		DisableOutlineDetection: true,
+	})
+
+	require.NoError(t, err, "response error should be nil on success")
+	require.Len(t, actualResponse.Translations, 1)
+}
+
+func TestClient_Translate_bad_options(t *testing.T) {
+	t.Parallel()
+
+	cli, err := New(APIFree, nil)
+	require.NoError(t, err, "failed to create client")
+
+	t.Run("nil options", func(t *testing.T) {
+		t.Parallel()
+
+		act, err := cli.Translate(context.TODO(), []string{"hello"}, nil)
+
+		require.Error(t, err, "nil options should return an error")
+		require.Nil(t, act, "returned value should be nil on error")
+		assert.Contains(t, err.Error(), "translate options is nil",
+			"it should contain the error reason")
+	})
+
+	t.Run("invalid enum", func(t *testing.T) {
+		t.Parallel()
+
+		act, err := cli.Translate(context.TODO(), []string{"hello"}, &TranslateOptions{
+			TargetLang: "DE",
+			Formality:  "very_polite",
+		})
+
+		require.Error(t, err, "invalid options should return an error")
+		require.Nil(t, act, "returned value should be nil on error")
+		assert.Contains(t, err.Error(), "invalid translate options",
+			"it should contain the error reason")
+		assert.Contains(t, err.Error(), "unsupported formality",
+			"it should contain the underlying error reason")
+	})
+}
+
 // ============================================================================
 //  Data Providers
 // ============================================================================
@@ -340,7 +523,7 @@ var dataGetAccountStatus = []struct {
 
 	expectMethod      string
 	expectRequestPath string
-	expectRawQuery    string
+	expectRawParams   string
 	expectResponse    *AccountStatus
 	expectErrMessage  string
 }{
@@ -352,9 +535,35 @@ var dataGetAccountStatus = []struct {
 
 		expectMethod:      http.MethodPost,
 		expectRequestPath: "/v2/usage",
-		expectRawQuery:    fmt.Sprintf("auth_key=%s", dummyAuthKey),
+		expectRawParams:   "",
 		expectResponse:    &AccountStatus{CharacterCount: 30315, CharacterLimit: 1000000},
 	},
+	{
+		name: "success pro with all the fields",
+
+		mockResponseHeaderFile: "testdata/GetAccountStatus/success-header",
+		mockResponseBodyFile:   "testdata/GetAccountStatus/success-pro-body",
+
+		expectMethod:      http.MethodPost,
+		expectRequestPath: "/v2/usage",
+		expectRawParams:   "",
+		expectResponse: &AccountStatus{
+			StartTime: timePtr(time.Date(2025, 5, 13, 9, 18, 42, 0, time.UTC)),
+			EndTime:   timePtr(time.Date(2025, 6, 13, 9, 18, 42, 0, time.UTC)),
+			Products: []ProductUsage{
+				{ProductType: "write", CharacterCount: 0, APIKeyCharacterCount: 0},
+				{ProductType: "translate", CharacterCount: 636, APIKeyCharacterCount: 636},
+			},
+			CharacterCount:       5947223,
+			CharacterLimit:       1000000000000,
+			DocumentCount:        12,
+			DocumentLimit:        0,
+			TeamDocumentCount:    3,
+			TeamDocumentLimit:    50,
+			APIKeyCharacterCount: 636,
+			APIKeyCharacterLimit: 1000000000000,
+		},
+	},
 }
 
 // dataTranslateSentence is a data provider for TestClient_TranslateSentence.
@@ -370,7 +579,7 @@ var dataTranslateSentence = []struct {
 
 	expectMethod      string
 	expectRequestPath string
-	expectRawQuery    string
+	expectRawParams   string
 	expectResponse    *TranslateResponse
 	expectErrMessage  string
 }{
@@ -386,7 +595,7 @@ var dataTranslateSentence = []struct {
 
 		expectMethod:      http.MethodPost,
 		expectRequestPath: "/v2/translate",
-		expectRawQuery:    fmt.Sprintf("auth_key=%s&source_lang=EN&target_lang=JA&text=hello", dummyAuthKey),
+		expectRawParams:   "source_lang=EN&target_lang=JA&text=hello",
 		expectResponse:    createTranslateResponse("EN", "こんにちわ"),
 	},
 	{
@@ -401,7 +610,7 @@ var dataTranslateSentence = []struct {
 
 		expectMethod:      http.MethodPost,
 		expectRequestPath: "/v2/translate",
-		expectRawQuery:    fmt.Sprintf("auth_key=%s&source_lang=EN&target_lang=&text=hello", dummyAuthKey),
+		expectRawParams:   "source_lang=EN&target_lang=&text=hello",
 		expectErrMessage:  "Bad request.",
 	},
 	{
@@ -416,7 +625,7 @@ var dataTranslateSentence = []struct {
 
 		expectMethod:      http.MethodPost,
 		expectRequestPath: "/v2/translate",
-		expectRawQuery:    fmt.Sprintf("auth_key=%s&source_lang=EN&target_lang=AA&text=hello", dummyAuthKey),
+		expectRawParams:   "source_lang=EN&target_lang=AA&text=hello",
 		expectErrMessage:  "Bad request.",
 	},
 	{
@@ -431,7 +640,7 @@ var dataTranslateSentence = []struct {
 
 		expectMethod:      http.MethodPost,
 		expectRequestPath: "/v2/translate",
-		expectRawQuery:    fmt.Sprintf("auth_key=%s&source_lang=EN&target_lang=JA&text=hello", dummyAuthKey),
+		expectRawParams:   "source_lang=EN&target_lang=JA&text=hello",
 		expectErrMessage:  "Authorization failed.",
 	},
 }
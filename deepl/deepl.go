@@ -2,12 +2,21 @@ package deepl
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"time"
 )
 
 const (
+	// MaxTextsPerRequest is the maximum number of texts that can be sent in a
+	// single translation request.
+	MaxTextsPerRequest = 50
+	// MaxRequestSize is the maximum size in bytes of a single request to the
+	// DeepL API. Which is 128 KiB.
+	MaxRequestSize = 128 * 1024
 	// NameEnvKeyAPIDefault is default environment key name of DeepL API key to search.
 	NameEnvKeyAPIDefault = "DEEPL_API_KEY"
 	// StatusQuotaExceeded is the status code of DeepL API when the character limit
@@ -23,6 +32,10 @@ var (
 	NameEnvKeyAPI = NameEnvKeyAPIDefault
 	// UserAgent is the user agent used in HTTP requests. By default, it is
 	// "Deepl-Go-Client".
+	//
+	// Deprecated: It affects all the clients in the process. Use WithUserAgent
+	// option of New to set it per client. It remains as the default of the
+	// clients with no user agent set.
 	UserAgent = UserAgentDefault
 )
 
@@ -33,22 +46,60 @@ var ioReadAll = io.ReadAll
 //  Types (Structs for JSON Unmarshaling)
 // ----------------------------------------------------------------------------
 
+// AccountStatus is the usage and the limits of the account in the current
+// billing period. The fields not returned by the API are zero. Such as the
+// document fields of the Pro accounts and the API key fields of the Free ones.
+//
+// Use the methods such as Remaining, PercentUsed and LimitReached rather than
+// the raw limits since the Pro accounts report "no limit" as a huge number. The
+// counters are int64 to hold it on the 32-bit platforms.
 type AccountStatus struct {
-	CharacterCount int `json:"character_count"`
-	CharacterLimit int `json:"character_limit"`
+	// StartTime and EndTime are the current billing period (Pro only).
+	StartTime *time.Time `json:"start_time,omitempty"`
+	EndTime   *time.Time `json:"end_time,omitempty"`
+	// Products is the breakdown of the characters per product (Pro only).
+	Products []ProductUsage `json:"products,omitempty"`
+	// CharacterCount and CharacterLimit are the characters of the account.
+	CharacterCount int64 `json:"character_count"`
+	CharacterLimit int64 `json:"character_limit"`
+	// DocumentCount and DocumentLimit are the documents of the account.
+	DocumentCount int64 `json:"document_count,omitempty"`
+	DocumentLimit int64 `json:"document_limit,omitempty"`
+	// TeamDocumentCount and TeamDocumentLimit are the documents of the team.
+	TeamDocumentCount int64 `json:"team_document_count,omitempty"`
+	TeamDocumentLimit int64 `json:"team_document_limit,omitempty"`
+	// APIKeyCharacterCount and APIKeyCharacterLimit are the characters of the
+	// API key used for the request (Pro only).
+	APIKeyCharacterCount int64 `json:"api_key_character_count,omitempty"`
+	APIKeyCharacterLimit int64 `json:"api_key_character_limit,omitempty"`
+}
+
+// ProductUsage is the characters used per product. Such as "translate" and
+// "write".
+type ProductUsage struct {
+	ProductType          string `json:"product_type"`
+	CharacterCount       int64  `json:"character_count"`
+	APIKeyCharacterCount int64  `json:"api_key_character_count"`
 }
 
 type ErrorResponse struct {
 	ErrMessage string `json:"message"`
+	Detail     string `json:"detail,omitempty"`
 }
 
 type TranslateResponse struct {
-	Translations []translation `json:"translations"`
+	Translations []Translation `json:"translations"`
 }
 
-type translation struct {
+// Translation is a translated text in TranslateResponse.
+type Translation struct {
 	DetectedSourceLanguage string `json:"detected_source_language"`
 	Text                   string `json:"text"`
+	// BilledCharacters is only available if TranslateOptions.ShowBilledCharacters
+	// is set.
+	BilledCharacters int `json:"billed_characters,omitempty"`
+	// ModelTypeUsed is only available if TranslateOptions.ModelType is set.
+	ModelTypeUsed string `json:"model_type_used,omitempty"`
 }
 
 // ----------------------------------------------------------------------------
@@ -67,18 +118,64 @@ func decodeBody(bodyBytes []byte, outStruct interface{}) error {
 // getAPIKey returns DeepL API key from environment variable.
 // If the environment variable is not set or empty, it returns an error.
 func getAPIKey() (string, error) {
-	apiKey, ok := os.LookupEnv(NameEnvKeyAPI)
+	return getAPIKeyFromEnv(NameEnvKeyAPI)
+}
+
+// getAPIKeyFromEnv returns DeepL API key from the given environment variable.
+// If the environment variable is not set or empty, it returns an error.
+func getAPIKeyFromEnv(nameEnv string) (string, error) {
+	apiKey, ok := os.LookupEnv(nameEnv)
 	if !ok {
-		return "", NewErr("env variable for API key not set: %s", NameEnvKeyAPI)
+		return "", NewErr("env variable for API key not set: %s", nameEnv)
 	}
 
 	if apiKey == "" {
-		return "", NewErr("env var is set but empty: %s", NameEnvKeyAPI)
+		return "", NewErr("env var is set but empty: %s", nameEnv)
 	}
 
 	return apiKey, nil
 }
 
+// packTexts splits the given texts into batches that each fit in a single
+// translation request. The baseSize is the size of the other request parameters
+// than the texts.
+//
+// It returns an error if a text alone exceeds the request size limit.
+func packTexts(texts []string, baseSize int) ([][]string, error) {
+	var (
+		batches [][]string
+		batch   []string
+		size    = baseSize
+	)
+
+	for index, text := range texts {
+		// "&text=" + URL encoded text
+		sizeText := len("&text=") + len(url.QueryEscape(text))
+
+		if baseSize+sizeText > MaxRequestSize {
+			return nil, NewErr(
+				"text #%d is too large to send. Size: %d bytes, Limit: %d bytes",
+				index+1, baseSize+sizeText, MaxRequestSize,
+			)
+		}
+
+		if len(batch) == MaxTextsPerRequest || size+sizeText > MaxRequestSize {
+			batches = append(batches, batch)
+			batch = nil
+			size = baseSize
+		}
+
+		batch = append(batch, text)
+		size += sizeText
+	}
+
+	if len(batch) != 0 {
+		batches = append(batches, batch)
+	}
+
+	return batches, nil
+}
+
 // responseParse parses the response from DeepL API.
 func responseParse(resp *http.Response, outStruct interface{}) error {
 	if resp == nil || outStruct == nil {
@@ -90,71 +187,76 @@ func responseParse(resp *http.Response, outStruct interface{}) error {
 		return WrapIfErr(err, "failed to read response")
 	}
 
-	return treatBodyAsErr(resp.StatusCode, bodyBytes, outStruct)
+	return withRequestInfo(treatBodyAsErr(resp.StatusCode, bodyBytes, outStruct), resp.Request)
 }
 
-// treatBodyAsErr treats the response body as an error message if the status code
-// is not 200.
-//
-//nolint:cyclop // due to switch statement allow cyclomatic complexity be 16/10.
-func treatBodyAsErr(status int, body []byte, outStruct interface{}) error {
-	var (
-		errResp    ErrorResponse
-		errMessage string
-	)
+// responseRead reads the raw response body from DeepL API. It returns an error
+// if the status code is not 2xx.
+func responseRead(resp *http.Response) ([]byte, error) {
+	if resp == nil {
+		return nil, NewErr("the input was nil")
+	}
 
-	// Capture the response body as an error message if the status code is not
-	// 200.
-	if status != http.StatusOK && len(body) != 0 {
-		err := decodeBody(body, &errResp)
-		if err != nil {
-			return WrapIfErr(err, "failed to decode error response")
-		}
+	bodyBytes, err := ioReadAll(resp.Body)
+	if err != nil {
+		return nil, WrapIfErr(err, "failed to read response")
+	}
 
-		errMessage = errResp.ErrMessage
+	if isStatusSuccess(resp.StatusCode) {
+		return bodyBytes, nil
 	}
 
-	switch status {
-	case http.StatusOK:
+	return nil, withRequestInfo(treatBodyAsErr(resp.StatusCode, bodyBytes, nil), resp.Request)
+}
+
+// isStatusSuccess returns true if the status code is 2xx.
+func isStatusSuccess(status int) bool {
+	return status >= http.StatusOK && status < http.StatusMultipleChoices
+}
+
+// treatBodyAsErr treats the response body as an error message if the status code
+// is not 2xx. The returned error is *APIError in that case. Otherwise, the body
+// is decoded into outStruct unless the status is 204 No Content.
+func treatBodyAsErr(status int, body []byte, outStruct interface{}) error {
+	if status == http.StatusNoContent {
+		return nil
+	}
+
+	if isStatusSuccess(status) {
 		err := decodeBody(body, &outStruct)
 
 		return WrapIfErr(err, "failed to parse JSON response")
-	case http.StatusBadRequest:
-		return NewErr(
-			"Bad request. Please check the error message and your parameters. Returned message: %s",
-			errMessage,
-		)
-	case http.StatusUnauthorized:
-		return NewErr(
-			"Unauthorized. Please check your API key. Returned message: %s",
-			errMessage,
-		)
-	case http.StatusForbidden:
-		return NewErr("Authorization failed. Please supply a valid auth_key parameter. Returned message: %s",
-			errMessage)
-	case http.StatusNotFound:
-		return NewErr("Not found. The requested resource clould not be found. Returned message: %s",
-			errMessage)
-	case http.StatusRequestEntityTooLarge:
-		return NewErr("Request entity too large. The entity size exceeds the limit of each request. Returned message: %s",
-			errMessage)
-	case http.StatusTooManyRequests:
-		return NewErr("Too many requests. Please wait and resend your request. Returned message: %s",
-			errMessage)
-	case StatusQuotaExceeded:
-		return NewErr("Quota exceeded. The character limit has been reached. Returned message: %s",
-			errMessage)
-	case http.StatusServiceUnavailable:
-		return NewErr("Service currently unavailable. Try again later. Returned message: %s",
-			errMessage)
-	default:
-		// Internal error (5**) other than "http.StatusServiceUnavailable"(503)
-		if 599 >= status && status >= 500 {
-			return NewErr("Internal server error. Please try again later. Status code: %d, Returned message: %s",
-				status, errMessage)
+	}
+
+	apiErr := &APIError{StatusCode: status}
+
+	// Capture the response body as an error message
+	if len(body) != 0 {
+		var errResp ErrorResponse
+
+		if err := decodeBody(body, &errResp); err != nil {
+			apiErr.Message = string(body)
+			apiErr.Err = WrapIfErr(err, "failed to decode error response")
+
+			return apiErr
 		}
+
+		apiErr.Message = errResp.ErrMessage
+		apiErr.Detail = errResp.Detail
+	}
+
+	return apiErr
+}
+
+// withRequestInfo sets the method and the endpoint of the request to the
+// *APIError in err if any. It returns err as is.
+func withRequestInfo(err error, req *http.Request) error {
+	var apiErr *APIError
+
+	if req != nil && req.URL != nil && errors.As(err, &apiErr) {
+		apiErr.Method = req.Method
+		apiErr.Endpoint = req.URL.Path
 	}
 
-	return NewErr("Unexpected error. Status code: %d, Returned message: %s",
-		status, errMessage)
+	return err
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -88,6 +89,51 @@ func Test_getAPIKey(t *testing.T) {
 	})
 }
 
+func Test_packTexts(t *testing.T) {
+	t.Parallel()
+
+	t.Run("limit by number of texts", func(t *testing.T) {
+		t.Parallel()
+
+		texts := make([]string, MaxTextsPerRequest+1)
+
+		batches, err := packTexts(texts, 0)
+
+		require.NoError(t, err)
+		require.Len(t, batches, 2,
+			"texts more than MaxTextsPerRequest should be split")
+		require.Len(t, batches[0], MaxTextsPerRequest)
+		require.Len(t, batches[1], 1)
+	})
+
+	t.Run("limit by request size", func(t *testing.T) {
+		t.Parallel()
+
+		// Each text fits alone but two of them do not
+		text := strings.Repeat("a", MaxRequestSize/2)
+		texts := []string{text, text, "b"}
+
+		batches, err := packTexts(texts, 0)
+
+		require.NoError(t, err)
+		require.Equal(t, [][]string{{text}, {text, "b"}}, batches,
+			"texts exceeding MaxRequestSize should be split preserving the order")
+	})
+
+	t.Run("too large text", func(t *testing.T) {
+		t.Parallel()
+
+		batches, err := packTexts([]string{"a"}, MaxRequestSize)
+
+		require.Error(t, err,
+			"text exceeding the limit with the base size should return error")
+		require.Nil(t, batches,
+			"returned value should be nil on error")
+		assert.Contains(t, err.Error(), "text #1 is too large to send",
+			"returned error should contain the reason")
+	})
+}
+
 func Test_responseParse_nil_input(t *testing.T) {
 	t.Parallel()
 
@@ -224,7 +270,7 @@ var dataResponseParse = []struct {
 		name:          "forbidden",
 		respStatus:    "403 Forbidden",
 		respBody:      `{"message": "FORBIDDEN"}`,
-		expectMsgCore: "Authorization failed. Please supply a valid auth_key parameter.",
+		expectMsgCore: "Authorization failed. Please supply a valid API key.",
 		expectMsgSub:  "FORBIDDEN",
 		statusCode:    http.StatusForbidden,
 	},
@@ -0,0 +1,364 @@
+package deepltest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/KEINOS/go-deepl/deepl"
+)
+
+// Mode is the mode of Recorder.
+type Mode int
+
+const (
+	// ModeReplay serves the responses from the cassette. The requests not in the
+	// cassette fail.
+	ModeReplay Mode = iota
+	// ModeRecord sends the requests to the real API and records the exchanges.
+	ModeRecord
+	// ModeAuto records if the cassette file does not exist. Otherwise, replays.
+	ModeAuto
+)
+
+// ----------------------------------------------------------------------------
+//  Types
+// ----------------------------------------------------------------------------
+
+// Cassette is the recorded exchanges with the API.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a recorded pair of a request and its response.
+type Interaction struct {
+	Request  CassetteRequest  `json:"request"`
+	Response CassetteResponse `json:"response"`
+}
+
+// CassetteRequest is the recorded request. The API key is not recorded.
+type CassetteRequest struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	// Params are the normalized parameters of the URL query and the body. See
+	// NormalizeParams.
+	Params string `json:"params"`
+}
+
+// CassetteResponse is the recorded response.
+type CassetteResponse struct {
+	Header http.Header `json:"header,omitempty"`
+	// Body is the response body if it is a valid UTF-8 text.
+	Body string `json:"body,omitempty"`
+	// BodyBase64 is the base64 encoded response body if it is binary.
+	BodyBase64 string `json:"body_base64,omitempty"`
+	Status     int    `json:"status"`
+}
+
+// ----------------------------------------------------------------------------
+//  Type: Recorder
+// ----------------------------------------------------------------------------
+
+// Recorder is an http.RoundTripper that records the exchanges with the API to a
+// cassette file and replays them.
+//
+// Record once against a real account and keep the tests offline afterwards:
+//
+//	rec, err := deepltest.NewRecorder("testdata/translate.json", deepltest.ModeAuto, nil)
+//	defer rec.Save()
+//
+//	cli, err := deepl.New(deepl.APIFree, nil, deepl.WithHTTPClient(&http.Client{Transport: rec}))
+//
+// The requests are matched by the method, the path and the normalized
+// parameters. The same requests are replayed in the recorded order. The API key
+// is never written to the cassette.
+type Recorder struct {
+	transport http.RoundTripper
+	cassette  Cassette
+	path      string
+	used      []bool
+	mode      Mode
+	mutex     sync.Mutex
+}
+
+// NewRecorder returns a new Recorder of the cassette file in the mode. The
+// transport is used to send the requests on recording. If nil, the
+// http.DefaultTransport is used.
+func NewRecorder(path string, mode Mode, transport http.RoundTripper) (*Recorder, error) {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	if mode == ModeAuto {
+		mode = ModeReplay
+
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			mode = ModeRecord
+		}
+	}
+
+	rec := &Recorder{
+		transport: transport,
+		path:      path,
+		mode:      mode,
+	}
+
+	if mode == ModeRecord {
+		return rec, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, deepl.WrapIfErr(err, "failed to read cassette")
+	}
+
+	if err := json.Unmarshal(data, &rec.cassette); err != nil {
+		return nil, deepl.WrapIfErr(err, "failed to parse cassette")
+	}
+
+	rec.used = make([]bool, len(rec.cassette.Interactions))
+
+	return rec, nil
+}
+
+// Mode returns the mode of the recorder. ModeAuto is resolved to ModeRecord or
+// ModeReplay.
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// Cassette returns a copy of the recorded interactions.
+func (r *Recorder) Cassette() Cassette {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return Cassette{Interactions: append([]Interaction(nil), r.cassette.Interactions...)}
+}
+
+// RoundTrip is the implementation of http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	cassetteReq := CassetteRequest{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Params: NormalizeParams(req.URL.RawQuery, req.Header.Get("Content-Type"), body),
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(req, cassetteReq)
+	}
+
+	return r.record(req, body, cassetteReq)
+}
+
+// Save writes the recorded interactions to the cassette file. It does nothing
+// in the replay mode.
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	var data bytes.Buffer
+
+	encoder := json.NewEncoder(&data)
+	encoder.SetEscapeHTML(false) // keep "&" in the params readable
+	encoder.SetIndent("", "  ")
+
+	r.mutex.Lock()
+	err := encoder.Encode(r.cassette)
+	r.mutex.Unlock()
+
+	if err != nil {
+		return deepl.WrapIfErr(err, "failed to encode cassette")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return deepl.WrapIfErr(err, "failed to create cassette directory")
+	}
+
+	return deepl.WrapIfErr(os.WriteFile(r.path, data.Bytes(), 0o600), "failed to write cassette")
+}
+
+// replay returns the recorded response of the request. The unused interaction
+// is preferred so that the same requests are replayed in the recorded order.
+func (r *Recorder) replay(req *http.Request, cassetteReq CassetteRequest) (*http.Response, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	found := -1
+
+	for index, interaction := range r.cassette.Interactions {
+		if interaction.Request != cassetteReq {
+			continue
+		}
+
+		if !r.used[index] {
+			found = index
+
+			break
+		}
+
+		found = index // reuse the last one if all used
+	}
+
+	if found < 0 {
+		return nil, deepl.NewErr("no interaction in cassette %s for %s %s %s",
+			r.path, cassetteReq.Method, cassetteReq.Path, cassetteReq.Params)
+	}
+
+	r.used[found] = true
+
+	recorded := r.cassette.Interactions[found].Response
+
+	body := []byte(recorded.Body)
+	if recorded.BodyBase64 != "" {
+		decoded, err := base64.StdEncoding.DecodeString(recorded.BodyBase64)
+		if err != nil {
+			return nil, deepl.WrapIfErr(err, "failed to decode the body in cassette")
+		}
+
+		body = decoded
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.Status, http.StatusText(recorded.Status)),
+		StatusCode:    recorded.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recorded.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// record sends the request with the transport and records the exchange.
+func (r *Recorder) record(req *http.Request, body []byte, cassetteReq CassetteRequest) (*http.Response, error) {
+	outReq := req.Clone(req.Context())
+	if body != nil {
+		outReq.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	resp, err := r.transport.RoundTrip(outReq)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if err != nil {
+		return nil, deepl.WrapIfErr(err, "failed to read response body")
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	recorded := CassetteResponse{
+		Header: resp.Header.Clone(),
+		Status: resp.StatusCode,
+	}
+
+	if utf8.Valid(respBody) {
+		recorded.Body = string(respBody)
+	} else {
+		recorded.BodyBase64 = base64.StdEncoding.EncodeToString(respBody)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request:  cassetteReq,
+		Response: recorded,
+	})
+
+	return resp, nil
+}
+
+// ----------------------------------------------------------------------------
+//  Functions
+// ----------------------------------------------------------------------------
+
+// NormalizeParams returns the parameters of the URL query and the body in the
+// canonical form to match the requests. The keys are sorted while the order of
+// the values, such as the texts, is kept.
+//
+// The API key ("auth_key") is removed. The uploaded files of the multipart body
+// are replaced with their names and SHA-256 hashes.
+func NormalizeParams(rawQuery string, contentType string, body []byte) string {
+	params, _ := url.ParseQuery(rawQuery)
+
+	mediaType, mediaParams, _ := mime.ParseMediaType(contentType)
+
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		bodyParams, _ := url.ParseQuery(string(body))
+		for key, values := range bodyParams {
+			params[key] = append(params[key], values...)
+		}
+	case mediaType == "multipart/form-data":
+		addMultipartParams(params, body, mediaParams["boundary"])
+	}
+
+	params.Del("auth_key")
+
+	return params.Encode()
+}
+
+// ----------------------------------------------------------------------------
+//  Private Functions
+// ----------------------------------------------------------------------------
+
+// addMultipartParams adds the fields of the multipart body to the params.
+func addMultipartParams(params url.Values, body []byte, boundary string) {
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			return
+		}
+
+		content, _ := io.ReadAll(part)
+
+		if part.FileName() == "" {
+			params.Add(part.FormName(), string(content))
+
+			continue
+		}
+
+		hash := sha256.Sum256(content)
+
+		params.Add(part.FormName(), part.FileName()+":sha256:"+hex.EncodeToString(hash[:]))
+	}
+}
+
+// readBody reads and closes the request body.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+
+	return body, deepl.WrapIfErr(err, "failed to read request body")
+}
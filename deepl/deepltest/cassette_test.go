@@ -0,0 +1,171 @@
+package deepltest_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/KEINOS/go-deepl/deepl"
+	"github.com/KEINOS/go-deepl/deepl/deepltest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// offlineTransport is an http.RoundTripper that fails all the requests.
+type offlineTransport struct{}
+
+func (offlineTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("network is not available")
+}
+
+// newRecorderClient returns a new client of the server through the recorder.
+func newRecorderClient(t *testing.T, srv *deepltest.Server, rec *deepltest.Recorder) *deepl.Client {
+	t.Helper()
+
+	cli, err := deepl.New(deepl.APICustom, nil,
+		deepl.WithBaseURL(srv.URL),
+		deepl.WithAPIKey(srv.AuthKey),
+		deepl.WithHTTPClient(&http.Client{Transport: rec}),
+	)
+	require.NoError(t, err)
+
+	return cli
+}
+
+func TestRecorder_record_and_replay(t *testing.T) {
+	t.Parallel()
+
+	pathCassette := filepath.Join(t.TempDir(), "cassettes", "translate.json")
+
+	srv := deepltest.NewServer()
+	defer srv.Close()
+
+	// Record
+	rec, err := deepltest.NewRecorder(pathCassette, deepltest.ModeAuto, srv.Client().Transport)
+	require.NoError(t, err)
+	require.Equal(t, deepltest.ModeRecord, rec.Mode(), "it should record if the cassette does not exist")
+
+	cli := newRecorderClient(t, srv, rec)
+
+	for _, text := range []string{"Hello", "World", "Hello"} {
+		_, err = cli.TranslateSentence(context.Background(), text, "EN", "DE")
+		require.NoError(t, err)
+	}
+
+	var out bytes.Buffer
+
+	_, err = cli.TranslateDocument(context.Background(), strings.NewReader("Hello"), "hello.txt", &out,
+		&deepl.DocumentOptions{TargetLang: "DE"})
+	require.NoError(t, err)
+
+	_, err = cli.GetGlossary(context.Background(), "unknown")
+	require.ErrorIs(t, err, deepl.ErrNotFound)
+
+	require.Len(t, rec.Cassette().Interactions, 7)
+	require.NoError(t, rec.Save())
+
+	data, err := os.ReadFile(pathCassette)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), srv.AuthKey, "API key should be redacted")
+	require.Contains(t, string(data), "file=hello.txt%3Asha256%3A", "uploaded file should be hashed")
+
+	// Replay offline
+	srv.Close()
+
+	replay, err := deepltest.NewRecorder(pathCassette, deepltest.ModeAuto, offlineTransport{})
+	require.NoError(t, err)
+	require.Equal(t, deepltest.ModeReplay, replay.Mode())
+
+	cli = newRecorderClient(t, srv, replay)
+
+	resp, err := cli.TranslateSentence(context.Background(), "World", "EN", "DE")
+	require.NoError(t, err)
+	require.Equal(t, "[DE] World", resp.Translations[0].Text)
+
+	out.Reset()
+
+	_, err = cli.TranslateDocument(context.Background(), strings.NewReader("Hello"), "hello.txt", &out,
+		&deepl.DocumentOptions{TargetLang: "DE"})
+	require.NoError(t, err)
+	require.Equal(t, "[DE] Hello", out.String())
+
+	_, err = cli.GetGlossary(context.Background(), "unknown")
+	require.ErrorIs(t, err, deepl.ErrNotFound, "error responses should be replayed too")
+
+	// Not recorded
+	_, err = cli.TranslateSentence(context.Background(), "Unknown", "EN", "DE")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no interaction in cassette")
+
+	require.NoError(t, replay.Save(), "save should do nothing on replay")
+}
+
+func TestRecorder_replay_in_order(t *testing.T) {
+	t.Parallel()
+
+	pathCassette := filepath.Join(t.TempDir(), "usage.json")
+
+	srv := deepltest.NewServer()
+	defer srv.Close()
+
+	rec, err := deepltest.NewRecorder(pathCassette, deepltest.ModeRecord, srv.Client().Transport)
+	require.NoError(t, err)
+
+	cli := newRecorderClient(t, srv, rec)
+
+	for _, text := range []string{"Hello", "World"} {
+		_, err = cli.TranslateSentence(context.Background(), text, "EN", "DE")
+		require.NoError(t, err)
+
+		_, err = cli.GetAccountStatus(context.Background())
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, rec.Save())
+
+	replay, err := deepltest.NewRecorder(pathCassette, deepltest.ModeReplay, nil)
+	require.NoError(t, err)
+
+	cli = newRecorderClient(t, srv, replay)
+
+	for _, expect := range []int64{5, 10, 10} {
+		status, err := cli.GetAccountStatus(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, expect, status.CharacterCount, "same requests should be replayed in order")
+	}
+}
+
+func TestNewRecorder_errors(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	_, err := deepltest.NewRecorder(filepath.Join(dir, "missing.json"), deepltest.ModeReplay, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read cassette")
+
+	pathBroken := filepath.Join(dir, "broken.json")
+	require.NoError(t, os.WriteFile(pathBroken, []byte("{"), 0o600))
+
+	_, err = deepltest.NewRecorder(pathBroken, deepltest.ModeReplay, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse cassette")
+}
+
+func TestNormalizeParams(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t,
+		"a=1&target_lang=DE&text=World&text=Hello",
+		deepltest.NormalizeParams("a=1&auth_key=secret", "application/x-www-form-urlencoded; charset=utf-8",
+			[]byte("text=World&target_lang=DE&text=Hello")),
+		"keys should be sorted and the order of the texts kept",
+	)
+
+	require.Equal(t, "type=source", deepltest.NormalizeParams("type=source", "", nil))
+}
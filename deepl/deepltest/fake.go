@@ -0,0 +1,210 @@
+// Package deepltest provides the test doubles of the deepl package to test the
+// code using DeepL API without the network.
+package deepltest
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/KEINOS/go-deepl/deepl"
+)
+
+// DefaultSourceLanguages is the default source languages of the fakes.
+var DefaultSourceLanguages = []deepl.Language{
+	{Code: "DE", Name: "German"},
+	{Code: "EN", Name: "English"},
+	{Code: "ES", Name: "Spanish"},
+	{Code: "FR", Name: "French"},
+	{Code: "JA", Name: "Japanese"},
+}
+
+// DefaultTargetLanguages is the default target languages of the fakes.
+var DefaultTargetLanguages = []deepl.Language{
+	{Code: "DE", Name: "German", SupportsFormality: true},
+	{Code: "EN-GB", Name: "English (British)"},
+	{Code: "EN-US", Name: "English (American)"},
+	{Code: "ES", Name: "Spanish", SupportsFormality: true},
+	{Code: "FR", Name: "French", SupportsFormality: true},
+	{Code: "JA", Name: "Japanese", SupportsFormality: true},
+}
+
+// PseudoTranslate returns the pseudo translation of the text. Which is the text
+// prefixed with the target language. Such as "[DE] Hello".
+func PseudoTranslate(text string, targetLang string) string {
+	return "[" + strings.ToUpper(targetLang) + "] " + text
+}
+
+// ----------------------------------------------------------------------------
+//  Type: FakeTranslator
+// ----------------------------------------------------------------------------
+
+// TranslateCall is a recorded call of FakeTranslator.Translate.
+type TranslateCall struct {
+	Options deepl.TranslateOptions
+	Texts   []string
+}
+
+// FakeTranslator is an in-memory deepl.Translator for testing. The fields must
+// be set before use.
+type FakeTranslator struct {
+	// Err is returned by all the methods if set.
+	Err error
+	// TranslateFunc returns the translation of the text. Defaults to
+	// PseudoTranslate with the target language.
+	TranslateFunc func(text string, opts *deepl.TranslateOptions) string
+	// SourceLanguages and TargetLanguages are returned by GetLanguages.
+	SourceLanguages []deepl.Language
+	TargetLanguages []deepl.Language
+	// CharacterLimit is the character quota. Zero means unlimited. Translate
+	// fails with deepl.ErrQuotaExceeded once the characters exceed it.
+	CharacterLimit int64
+
+	calls          []TranslateCall
+	characterCount int64
+	mutex          sync.Mutex
+}
+
+// Ensure FakeTranslator implements deepl.Translator.
+var _ deepl.Translator = (*FakeTranslator)(nil)
+
+// NewFakeTranslator returns a new FakeTranslator with the default languages.
+func NewFakeTranslator() *FakeTranslator {
+	return &FakeTranslator{
+		SourceLanguages: DefaultSourceLanguages,
+		TargetLanguages: DefaultTargetLanguages,
+	}
+}
+
+// Translate is the implementation of deepl.Translator. The detected source
+// language is the given source language or "EN" if not set.
+func (f *FakeTranslator) Translate(
+	_ context.Context,
+	texts []string,
+	opts *deepl.TranslateOptions,
+) (*deepl.TranslateResponse, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	if opts == nil {
+		return nil, deepl.NewErr("translate options is nil")
+	}
+
+	if err := opts.Validate(); err != nil {
+		return nil, deepl.WrapIfErr(err, "invalid translate options")
+	}
+
+	if opts.TargetLang == "" {
+		return nil, &deepl.APIError{
+			Method:     http.MethodPost,
+			Endpoint:   "/v2/translate",
+			Message:    "Parameter 'target_lang' not specified.",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.calls = append(f.calls, TranslateCall{
+		Options: *opts,
+		Texts:   append([]string(nil), texts...),
+	})
+
+	var characters int64
+	for _, text := range texts {
+		characters += int64(utf8.RuneCountInString(text))
+	}
+
+	if f.CharacterLimit > 0 && f.characterCount+characters > f.CharacterLimit {
+		return nil, &deepl.APIError{
+			Method:     http.MethodPost,
+			Endpoint:   "/v2/translate",
+			Message:    "Quota exceeded",
+			StatusCode: deepl.StatusQuotaExceeded,
+		}
+	}
+
+	f.characterCount += characters
+
+	detectedLang := strings.ToUpper(opts.SourceLang)
+	if detectedLang == "" {
+		detectedLang = "EN"
+	}
+
+	resp := &deepl.TranslateResponse{
+		Translations: make([]deepl.Translation, len(texts)),
+	}
+
+	for index, text := range texts {
+		resp.Translations[index] = deepl.Translation{
+			DetectedSourceLanguage: detectedLang,
+			Text:                   f.translate(text, opts),
+		}
+
+		if opts.ShowBilledCharacters {
+			resp.Translations[index].BilledCharacters = utf8.RuneCountInString(text)
+		}
+	}
+
+	return resp, nil
+}
+
+// GetLanguages is the implementation of deepl.Translator.
+func (f *FakeTranslator) GetLanguages(_ context.Context, langType deepl.LanguageType) ([]deepl.Language, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	switch langType {
+	case deepl.LanguageTypeSource:
+		return f.SourceLanguages, nil
+	case deepl.LanguageTypeTarget:
+		return f.TargetLanguages, nil
+	}
+
+	return nil, deepl.NewErr("unsupported language type: %q", langType)
+}
+
+// GetAccountStatus is the implementation of deepl.Translator.
+func (f *FakeTranslator) GetAccountStatus(context.Context) (*deepl.AccountStatus, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return &deepl.AccountStatus{
+		CharacterCount: f.characterCount,
+		CharacterLimit: f.CharacterLimit,
+	}, nil
+}
+
+// Calls returns the recorded calls of Translate.
+func (f *FakeTranslator) Calls() []TranslateCall {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return append([]TranslateCall(nil), f.calls...)
+}
+
+// CharacterCount returns the characters translated so far.
+func (f *FakeTranslator) CharacterCount() int64 {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.characterCount
+}
+
+// translate returns the translation of the text by TranslateFunc.
+func (f *FakeTranslator) translate(text string, opts *deepl.TranslateOptions) string {
+	if f.TranslateFunc != nil {
+		return f.TranslateFunc(text, opts)
+	}
+
+	return PseudoTranslate(text, opts.TargetLang)
+}
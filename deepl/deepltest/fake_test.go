@@ -0,0 +1,122 @@
+package deepltest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/KEINOS/go-deepl/deepl"
+	"github.com/KEINOS/go-deepl/deepl/deepltest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeTranslator(t *testing.T) {
+	t.Parallel()
+
+	fake := deepltest.NewFakeTranslator()
+
+	var translator deepl.Translator = fake
+
+	resp, err := translator.Translate(context.Background(), []string{"Hello", "World"}, &deepl.TranslateOptions{
+		TargetLang:           "de",
+		ShowBilledCharacters: true,
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Translations, 2)
+	require.Equal(t, "[DE] Hello", resp.Translations[0].Text)
+	require.Equal(t, "EN", resp.Translations[0].DetectedSourceLanguage)
+	require.Equal(t, 5, resp.Translations[1].BilledCharacters)
+
+	calls := fake.Calls()
+	require.Len(t, calls, 1)
+	require.Equal(t, []string{"Hello", "World"}, calls[0].Texts)
+	require.Equal(t, "de", calls[0].Options.TargetLang)
+
+	status, err := translator.GetAccountStatus(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(10), status.CharacterCount)
+	require.Equal(t, int64(10), fake.CharacterCount())
+
+	languages, err := translator.GetLanguages(context.Background(), deepl.LanguageTypeTarget)
+	require.NoError(t, err)
+	require.Equal(t, deepltest.DefaultTargetLanguages, languages)
+
+	languages, err = translator.GetLanguages(context.Background(), deepl.LanguageTypeSource)
+	require.NoError(t, err)
+	require.Equal(t, deepltest.DefaultSourceLanguages, languages)
+
+	_, err = translator.GetLanguages(context.Background(), "unknown")
+	require.Error(t, err)
+}
+
+func TestFakeTranslator_TranslateFunc(t *testing.T) {
+	t.Parallel()
+
+	fake := deepltest.NewFakeTranslator()
+	fake.TranslateFunc = func(text string, opts *deepl.TranslateOptions) string {
+		return opts.SourceLang + ">" + opts.TargetLang + ":" + text
+	}
+
+	resp, err := fake.Translate(context.Background(), []string{"Hello"}, &deepl.TranslateOptions{
+		SourceLang: "EN",
+		TargetLang: "JA",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "EN>JA:Hello", resp.Translations[0].Text)
+}
+
+func TestFakeTranslator_quota(t *testing.T) {
+	t.Parallel()
+
+	fake := deepltest.NewFakeTranslator()
+	fake.CharacterLimit = 8
+
+	opts := &deepl.TranslateOptions{TargetLang: "DE"}
+
+	_, err := fake.Translate(context.Background(), []string{"Hello"}, opts)
+	require.NoError(t, err)
+
+	_, err = fake.Translate(context.Background(), []string{"World"}, opts)
+	require.Error(t, err)
+	require.ErrorIs(t, err, deepl.ErrQuotaExceeded)
+	require.Equal(t, int64(5), fake.CharacterCount(), "failed request should not be counted")
+}
+
+func TestFakeTranslator_errors(t *testing.T) {
+	t.Parallel()
+
+	fake := deepltest.NewFakeTranslator()
+
+	_, err := fake.Translate(context.Background(), []string{"Hello"}, nil)
+	require.Error(t, err)
+
+	_, err = fake.Translate(context.Background(), []string{"Hello"}, &deepl.TranslateOptions{})
+	require.Error(t, err, "missing target language should be an error")
+
+	fake.Err = errors.New("forced error")
+
+	_, err = fake.Translate(context.Background(), []string{"Hello"}, &deepl.TranslateOptions{TargetLang: "DE"})
+	require.ErrorIs(t, err, fake.Err)
+
+	_, err = fake.GetLanguages(context.Background(), deepl.LanguageTypeSource)
+	require.ErrorIs(t, err, fake.Err)
+
+	_, err = fake.GetAccountStatus(context.Background())
+	require.ErrorIs(t, err, fake.Err)
+}
+
+// Using the fake with the decorators of the deepl package.
+func TestFakeTranslator_with_decorators(t *testing.T) {
+	t.Parallel()
+
+	fake := deepltest.NewFakeTranslator()
+	translator := deepl.NewCachedTranslator(fake, deepl.NewMemoryCache(0, 0), nil)
+
+	for i := 0; i < 2; i++ {
+		resp, err := translator.Translate(context.Background(), []string{"Hello"}, &deepl.TranslateOptions{TargetLang: "FR"})
+		require.NoError(t, err)
+		require.Equal(t, "[FR] Hello", resp.Translations[0].Text)
+	}
+
+	require.Len(t, fake.Calls(), 1)
+}
@@ -0,0 +1,659 @@
+package deepltest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/KEINOS/go-deepl/deepl"
+)
+
+// AuthKeyDefault is the default API key accepted by Server.
+const AuthKeyDefault = "00000000-0000-0000-0000-000000000000:fx"
+
+// ----------------------------------------------------------------------------
+//  Types
+// ----------------------------------------------------------------------------
+
+// Failure is a failure injected to the responses of Server.
+type Failure struct {
+	// Path limits the failure to the requests of the path. Such as
+	// "/v2/translate". Empty matches all the requests.
+	Path string
+	// RetryAfter is the value of the "Retry-After" header if not empty.
+	RetryAfter string
+	// Status is the HTTP status code to respond. Such as 429, 456 and 503. If
+	// zero, the request is processed normally after the Delay. Which simulates
+	// a slow response.
+	Status int
+	// Delay delays the response. The request context is honored.
+	Delay time.Duration
+	// Times is the number of the requests to affect. Defaults to 1.
+	Times int
+}
+
+// RecordedRequest is a request received by Server.
+type RecordedRequest struct {
+	// Header is the request header. The API key is redacted.
+	Header http.Header
+	// Params are the parameters in the URL query and the body. The uploaded
+	// document is not included.
+	Params url.Values
+	Method string
+	Path   string
+}
+
+// glossaryData is a glossary stored in Server.
+type glossaryData struct {
+	entries deepl.GlossaryEntries
+	meta    deepl.Glossary
+}
+
+// documentData is a document stored in Server.
+type documentData struct {
+	key        string
+	translated string
+	polls      int
+	billed     int
+}
+
+// ----------------------------------------------------------------------------
+//  Type: Server
+// ----------------------------------------------------------------------------
+
+// Server is a fake DeepL API server for testing. It mimics the translate, usage,
+// languages, glossaries and documents endpoints in memory.
+//
+// The translations are the deterministic pseudo translations by
+// PseudoTranslate. The requests must have the API key of AuthKey in the
+// "Authorization" header.
+//
+//	srv := deepltest.NewServer()
+//	defer srv.Close()
+//
+//	cli, err := srv.NewClient()
+type Server struct {
+	*httptest.Server
+
+	// AuthKey is the API key accepted by the server. It must be set before
+	// requests.
+	AuthKey string
+
+	glossaries      map[string]*glossaryData
+	documents       map[string]*documentData
+	failures        []Failure
+	requests        []RecordedRequest
+	sourceLanguages []deepl.Language
+	targetLanguages []deepl.Language
+	characterCount  int64
+	characterLimit  int64
+	documentCount   int64
+	documentPolls   int
+	latency         time.Duration
+	countID         int
+	mutex           sync.Mutex
+}
+
+// NewServer starts and returns a new Server. The caller must call Close when
+// finished.
+func NewServer() *Server {
+	srv := &Server{
+		AuthKey:         AuthKeyDefault,
+		glossaries:      make(map[string]*glossaryData),
+		documents:       make(map[string]*documentData),
+		sourceLanguages: DefaultSourceLanguages,
+		targetLanguages: DefaultTargetLanguages,
+	}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/v2/translate", srv.handleTranslate)
+	mux.HandleFunc("/v2/usage", srv.handleUsage)
+	mux.HandleFunc("/v2/languages", srv.handleLanguages)
+	mux.HandleFunc("/v2/glossaries", srv.handleGlossaries)
+	mux.HandleFunc("/v2/glossaries/", srv.handleGlossary)
+	mux.HandleFunc("/v2/glossary-language-pairs", srv.handleGlossaryLanguagePairs)
+	mux.HandleFunc("/v2/document", srv.handleDocumentUpload)
+	mux.HandleFunc("/v2/document/", srv.handleDocument)
+
+	srv.Server = httptest.NewServer(srv.middleware(mux))
+
+	return srv
+}
+
+// NewClient returns a new client of the server with the API key. The options
+// are applied after the ones to connect to the server.
+func (s *Server) NewClient(opts ...deepl.Option) (*deepl.Client, error) {
+	return deepl.New(deepl.APICustom, nil, append([]deepl.Option{
+		deepl.WithBaseURL(s.URL),
+		deepl.WithHTTPClient(s.Client()),
+		deepl.WithAPIKey(s.AuthKey),
+	}, opts...)...)
+}
+
+// SetCharacterLimit sets the character quota. Zero means unlimited. The
+// translations exceeding the quota fail with status 456.
+func (s *Server) SetCharacterLimit(limit int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.characterLimit = limit
+}
+
+// SetCharacterCount sets the characters used so far.
+func (s *Server) SetCharacterCount(count int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.characterCount = count
+}
+
+// SetLanguages sets the languages returned by the languages endpoint.
+func (s *Server) SetLanguages(source, target []deepl.Language) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.sourceLanguages = source
+	s.targetLanguages = target
+}
+
+// SetLatency delays all the responses. Which simulates a slow network.
+func (s *Server) SetLatency(latency time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.latency = latency
+}
+
+// SetDocumentPolls sets the number of the status polls before the documents are
+// done. Zero (default) means they are done immediately.
+func (s *Server) SetDocumentPolls(polls int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.documentPolls = polls
+}
+
+// InjectFailure adds the failure to the following requests. The failures are
+// applied in the order added.
+func (s *Server) InjectFailure(failure Failure) {
+	if failure.Times <= 0 {
+		failure.Times = 1
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.failures = append(s.failures, failure)
+}
+
+// ClearFailures removes all the injected failures.
+func (s *Server) ClearFailures() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.failures = nil
+}
+
+// Requests returns the requests received so far.
+func (s *Server) Requests() []RecordedRequest {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]RecordedRequest(nil), s.requests...)
+}
+
+// CharacterCount returns the characters used so far.
+func (s *Server) CharacterCount() int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.characterCount
+}
+
+// ----------------------------------------------------------------------------
+//  Private Methods (middleware)
+// ----------------------------------------------------------------------------
+
+// middleware records the requests, applies the failures and checks the API key.
+func (s *Server) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(respWriter http.ResponseWriter, req *http.Request) {
+		if strings.HasPrefix(req.Header.Get("Content-Type"), "multipart/form-data") {
+			_ = req.ParseMultipartForm(deepl.MaxRequestSize)
+		} else {
+			_ = req.ParseForm()
+		}
+
+		failure, latency := s.record(req)
+
+		if !sleep(req, latency+failure.Delay) {
+			return
+		}
+
+		if failure.Status != 0 {
+			if failure.RetryAfter != "" {
+				respWriter.Header().Set("Retry-After", failure.RetryAfter)
+			}
+
+			writeError(respWriter, failure.Status, "Injected failure")
+
+			return
+		}
+
+		if req.Header.Get("Authorization") != "DeepL-Auth-Key "+s.AuthKey {
+			writeError(respWriter, http.StatusForbidden, "Authorization failed")
+
+			return
+		}
+
+		next.ServeHTTP(respWriter, req)
+	})
+}
+
+// record records the request and returns the failure to apply and the latency.
+func (s *Server) record(req *http.Request) (Failure, time.Duration) {
+	header := req.Header.Clone()
+	if header.Get("Authorization") != "" {
+		header.Set("Authorization", "DeepL-Auth-Key REDACTED")
+	}
+
+	params := url.Values{}
+	for key, values := range req.Form {
+		params[key] = append([]string(nil), values...)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.requests = append(s.requests, RecordedRequest{
+		Header: header,
+		Params: params,
+		Method: req.Method,
+		Path:   req.URL.Path,
+	})
+
+	for index := range s.failures {
+		failure := &s.failures[index]
+		if failure.Path != "" && failure.Path != req.URL.Path {
+			continue
+		}
+
+		found := *failure
+
+		failure.Times--
+		if failure.Times == 0 {
+			s.failures = append(s.failures[:index], s.failures[index+1:]...)
+		}
+
+		return found, s.latency
+	}
+
+	return Failure{}, s.latency
+}
+
+// ----------------------------------------------------------------------------
+//  Private Methods (handlers)
+// ----------------------------------------------------------------------------
+
+func (s *Server) handleTranslate(respWriter http.ResponseWriter, req *http.Request) {
+	texts := req.Form["text"]
+	targetLang := req.Form.Get("target_lang")
+
+	switch {
+	case targetLang == "":
+		writeError(respWriter, http.StatusBadRequest, "Parameter 'target_lang' not specified.")
+
+		return
+	case len(texts) == 0:
+		writeError(respWriter, http.StatusBadRequest, "Parameter 'text' not specified.")
+
+		return
+	}
+
+	var characters int64
+	for _, text := range texts {
+		characters += int64(utf8.RuneCountInString(text))
+	}
+
+	if !s.bill(characters) {
+		writeError(respWriter, deepl.StatusQuotaExceeded, "Quota exceeded")
+
+		return
+	}
+
+	detectedLang := strings.ToUpper(req.Form.Get("source_lang"))
+	if detectedLang == "" {
+		detectedLang = "EN"
+	}
+
+	resp := new(deepl.TranslateResponse)
+
+	for _, text := range texts {
+		trans := deepl.Translation{
+			DetectedSourceLanguage: detectedLang,
+			Text:                   PseudoTranslate(text, targetLang),
+		}
+
+		if req.Form.Get("show_billed_characters") == "1" {
+			trans.BilledCharacters = utf8.RuneCountInString(text)
+		}
+
+		if modelType := req.Form.Get("model_type"); modelType != "" {
+			trans.ModelTypeUsed = strings.TrimPrefix(modelType, "prefer_")
+		}
+
+		resp.Translations = append(resp.Translations, trans)
+	}
+
+	writeJSON(respWriter, http.StatusOK, resp)
+}
+
+func (s *Server) handleUsage(respWriter http.ResponseWriter, _ *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	writeJSON(respWriter, http.StatusOK, &deepl.AccountStatus{
+		CharacterCount: s.characterCount,
+		CharacterLimit: s.characterLimit,
+		DocumentCount:  s.documentCount,
+	})
+}
+
+func (s *Server) handleLanguages(respWriter http.ResponseWriter, req *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	switch deepl.LanguageType(req.Form.Get("type")) {
+	case deepl.LanguageTypeSource, "":
+		writeJSON(respWriter, http.StatusOK, s.sourceLanguages)
+	case deepl.LanguageTypeTarget:
+		writeJSON(respWriter, http.StatusOK, s.targetLanguages)
+	default:
+		writeError(respWriter, http.StatusBadRequest, "Value for 'type' not supported.")
+	}
+}
+
+func (s *Server) handleGlossaries(respWriter http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		list := struct {
+			Glossaries []deepl.Glossary `json:"glossaries"`
+		}{Glossaries: []deepl.Glossary{}}
+
+		for _, glossary := range s.glossaries {
+			list.Glossaries = append(list.Glossaries, glossary.meta)
+		}
+
+		sort.Slice(list.Glossaries, func(i, j int) bool {
+			return list.Glossaries[i].GlossaryID < list.Glossaries[j].GlossaryID
+		})
+
+		writeJSON(respWriter, http.StatusOK, list)
+	case http.MethodPost:
+		s.createGlossary(respWriter, req)
+	default:
+		writeError(respWriter, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+func (s *Server) createGlossary(respWriter http.ResponseWriter, req *http.Request) {
+	for _, key := range []string{"name", "source_lang", "target_lang", "entries", "entries_format"} {
+		if req.Form.Get(key) == "" {
+			writeError(respWriter, http.StatusBadRequest, fmt.Sprintf("Parameter '%s' not specified.", key))
+
+			return
+		}
+	}
+
+	entries, err := deepl.ParseGlossaryEntries(req.Form.Get("entries"),
+		deepl.GlossaryEntriesFormat(req.Form.Get("entries_format")))
+	if err != nil {
+		writeError(respWriter, http.StatusBadRequest, "Invalid glossary entries provided")
+
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.countID++
+
+	glossary := &glossaryData{
+		entries: entries,
+		meta: deepl.Glossary{
+			CreationTime: time.Date(2023, 1, 1, 0, 0, s.countID, 0, time.UTC),
+			GlossaryID:   fmt.Sprintf("00000000-0000-4000-8000-%012d", s.countID),
+			Name:         req.Form.Get("name"),
+			SourceLang:   strings.ToLower(req.Form.Get("source_lang")),
+			TargetLang:   strings.ToLower(req.Form.Get("target_lang")),
+			EntryCount:   len(entries),
+			Ready:        true,
+		},
+	}
+
+	s.glossaries[glossary.meta.GlossaryID] = glossary
+
+	writeJSON(respWriter, http.StatusCreated, glossary.meta)
+}
+
+func (s *Server) handleGlossary(respWriter http.ResponseWriter, req *http.Request) {
+	glossaryID := strings.TrimPrefix(req.URL.Path, "/v2/glossaries/")
+	glossaryID, sub := splitSub(glossaryID)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	glossary, ok := s.glossaries[glossaryID]
+	if !ok {
+		writeError(respWriter, http.StatusNotFound, "Glossary not found")
+
+		return
+	}
+
+	switch {
+	case sub == "" && req.Method == http.MethodGet:
+		writeJSON(respWriter, http.StatusOK, glossary.meta)
+	case sub == "" && req.Method == http.MethodDelete:
+		delete(s.glossaries, glossaryID)
+		respWriter.WriteHeader(http.StatusNoContent)
+	case sub == "entries" && req.Method == http.MethodGet:
+		data, _ := glossary.entries.Encode(deepl.GlossaryEntriesTSV)
+
+		respWriter.Header().Set("Content-Type", "text/tab-separated-values")
+		_, _ = io.WriteString(respWriter, data)
+	default:
+		writeError(respWriter, http.StatusNotFound, "Not found")
+	}
+}
+
+func (s *Server) handleGlossaryLanguagePairs(respWriter http.ResponseWriter, _ *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	pairs := struct {
+		SupportedLanguages []deepl.GlossaryLanguagePair `json:"supported_languages"`
+	}{}
+
+	for _, source := range s.sourceLanguages {
+		for _, target := range s.sourceLanguages {
+			if source.Code != target.Code {
+				pairs.SupportedLanguages = append(pairs.SupportedLanguages, deepl.GlossaryLanguagePair{
+					SourceLang: strings.ToLower(source.Code),
+					TargetLang: strings.ToLower(target.Code),
+				})
+			}
+		}
+	}
+
+	writeJSON(respWriter, http.StatusOK, pairs)
+}
+
+func (s *Server) handleDocumentUpload(respWriter http.ResponseWriter, req *http.Request) {
+	if req.MultipartForm == nil || len(req.MultipartForm.File["file"]) == 0 {
+		writeError(respWriter, http.StatusBadRequest, "Parameter 'file' not specified.")
+
+		return
+	}
+
+	targetLang := req.Form.Get("target_lang")
+	if targetLang == "" {
+		writeError(respWriter, http.StatusBadRequest, "Parameter 'target_lang' not specified.")
+
+		return
+	}
+
+	file, err := req.MultipartForm.File["file"][0].Open()
+	if err != nil {
+		writeError(respWriter, http.StatusBadRequest, "Failed to read the file")
+
+		return
+	}
+
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		writeError(respWriter, http.StatusBadRequest, "Failed to read the file")
+
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.countID++
+
+	handle := deepl.DocumentHandle{
+		DocumentID:  fmt.Sprintf("%032X", s.countID),
+		DocumentKey: fmt.Sprintf("%064X", s.countID),
+	}
+
+	s.documents[handle.DocumentID] = &documentData{
+		key:        handle.DocumentKey,
+		translated: PseudoTranslate(string(content), targetLang),
+		polls:      s.documentPolls,
+		billed:     utf8.RuneCount(content),
+	}
+
+	writeJSON(respWriter, http.StatusOK, handle)
+}
+
+func (s *Server) handleDocument(respWriter http.ResponseWriter, req *http.Request) {
+	documentID, sub := splitSub(strings.TrimPrefix(req.URL.Path, "/v2/document/"))
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	document, ok := s.documents[documentID]
+	if !ok || document.key != req.Form.Get("document_key") {
+		writeError(respWriter, http.StatusNotFound, "Document not found")
+
+		return
+	}
+
+	done := document.polls <= 0
+
+	switch sub {
+	case "":
+		status := &deepl.DocumentStatus{
+			DocumentID: documentID,
+			Status:     deepl.DocumentStatusTranslating,
+		}
+
+		if done {
+			status.Status = deepl.DocumentStatusDone
+			status.BilledCharacters = document.billed
+		} else {
+			document.polls--
+			status.SecondsRemaining = 1
+		}
+
+		writeJSON(respWriter, http.StatusOK, status)
+	case "result":
+		if !done {
+			writeError(respWriter, http.StatusServiceUnavailable, "Document not ready")
+
+			return
+		}
+
+		// The document can only be downloaded once
+		delete(s.documents, documentID)
+
+		s.characterCount += int64(document.billed)
+		s.documentCount++
+
+		_, _ = io.WriteString(respWriter, document.translated)
+	default:
+		writeError(respWriter, http.StatusNotFound, "Not found")
+	}
+}
+
+// bill adds the characters to the usage. It returns false if the quota is
+// exceeded.
+func (s *Server) bill(characters int64) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.characterLimit > 0 && s.characterCount+characters > s.characterLimit {
+		return false
+	}
+
+	s.characterCount += characters
+
+	return true
+}
+
+// ----------------------------------------------------------------------------
+//  Private Functions
+// ----------------------------------------------------------------------------
+
+// sleep waits for the duration. It returns false if the request is canceled.
+func sleep(req *http.Request, duration time.Duration) bool {
+	if duration <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-req.Context().Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// splitSub splits the path into the ID and the sub resource. Such as "ID" and
+// "entries" of "ID/entries".
+func splitSub(pathID string) (string, string) {
+	if index := strings.Index(pathID, "/"); index >= 0 {
+		return pathID[:index], pathID[index+1:]
+	}
+
+	return pathID, ""
+}
+
+// writeError writes the error response in the format of the API.
+func writeError(respWriter http.ResponseWriter, status int, message string) {
+	writeJSON(respWriter, status, &deepl.ErrorResponse{ErrMessage: message})
+}
+
+// writeJSON writes the value as the JSON response.
+func writeJSON(respWriter http.ResponseWriter, status int, value interface{}) {
+	respWriter.Header().Set("Content-Type", "application/json")
+	respWriter.WriteHeader(status)
+
+	_ = json.NewEncoder(respWriter).Encode(value)
+}
@@ -0,0 +1,326 @@
+package deepltest_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KEINOS/go-deepl/deepl"
+	"github.com/KEINOS/go-deepl/deepl/deepltest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newServerClient returns a new server and its client with the options.
+func newServerClient(t *testing.T, opts ...deepl.Option) (*deepltest.Server, *deepl.Client) {
+	t.Helper()
+
+	srv := deepltest.NewServer()
+	t.Cleanup(srv.Close)
+
+	cli, err := srv.NewClient(opts...)
+	require.NoError(t, err)
+
+	return srv, cli
+}
+
+// ----------------------------------------------------------------------------
+//  Translate and usage
+// ----------------------------------------------------------------------------
+
+func TestServer_translate(t *testing.T) {
+	t.Parallel()
+
+	srv, cli := newServerClient(t)
+
+	resp, err := cli.Translate(context.Background(), []string{"Hello", "World"}, &deepl.TranslateOptions{
+		SourceLang:           "EN",
+		TargetLang:           "DE",
+		ShowBilledCharacters: true,
+		ModelType:            deepl.ModelTypePreferQualityOptimized,
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Translations, 2)
+	require.Equal(t, "[DE] Hello", resp.Translations[0].Text)
+	require.Equal(t, "[DE] World", resp.Translations[1].Text)
+	require.Equal(t, "EN", resp.Translations[0].DetectedSourceLanguage)
+	require.Equal(t, 5, resp.Translations[0].BilledCharacters)
+	require.Equal(t, "quality_optimized", resp.Translations[0].ModelTypeUsed)
+
+	status, err := cli.GetAccountStatus(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(10), status.CharacterCount)
+	require.Equal(t, int64(10), srv.CharacterCount())
+}
+
+func TestServer_quota(t *testing.T) {
+	t.Parallel()
+
+	srv, cli := newServerClient(t)
+
+	srv.SetCharacterLimit(100)
+	srv.SetCharacterCount(95)
+
+	_, err := cli.TranslateSentence(context.Background(), "Hello", "EN", "DE")
+	require.NoError(t, err)
+
+	_, err = cli.TranslateSentence(context.Background(), "!", "EN", "DE")
+	require.Error(t, err)
+	require.ErrorIs(t, err, deepl.ErrQuotaExceeded)
+
+	status, err := cli.GetAccountStatus(context.Background())
+	require.NoError(t, err)
+	require.True(t, status.LimitReached())
+}
+
+func TestServer_bad_requests(t *testing.T) {
+	t.Parallel()
+
+	srv, cli := newServerClient(t)
+
+	_, err := cli.TranslateSentence(context.Background(), "Hello", "EN", "")
+	require.ErrorIs(t, err, deepl.ErrBadRequest)
+
+	srv.AuthKey = "another-key"
+
+	_, err = cli.GetAccountStatus(context.Background())
+	require.ErrorIs(t, err, deepl.ErrForbidden)
+}
+
+// ----------------------------------------------------------------------------
+//  Languages
+// ----------------------------------------------------------------------------
+
+func TestServer_languages(t *testing.T) {
+	t.Parallel()
+
+	srv, cli := newServerClient(t)
+
+	languages, err := cli.GetLanguages(context.Background(), deepl.LanguageTypeTarget)
+	require.NoError(t, err)
+	require.Equal(t, deepltest.DefaultTargetLanguages, languages)
+
+	srv.SetLanguages([]deepl.Language{{Code: "EN", Name: "English"}}, nil)
+
+	languages, err = cli.GetLanguages(context.Background(), deepl.LanguageTypeSource)
+	require.NoError(t, err)
+	require.Len(t, languages, 1)
+
+	// Client-side validation with the languages of the server
+	cli.ValidateLanguages = true
+
+	srv.SetLanguages(deepltest.DefaultSourceLanguages, deepltest.DefaultTargetLanguages)
+
+	_, err = cli.TranslateSentence(context.Background(), "Hello", "EN", "XX")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid language")
+}
+
+// ----------------------------------------------------------------------------
+//  Glossaries
+// ----------------------------------------------------------------------------
+
+func TestServer_glossaries(t *testing.T) {
+	t.Parallel()
+
+	_, cli := newServerClient(t)
+	ctx := context.Background()
+
+	entries := deepl.GlossaryEntries{{Source: "Hello", Target: "Hallo"}, {Source: "World", Target: "Welt"}}
+
+	glossary, err := cli.CreateGlossary(ctx, "My Glossary", "EN", "DE", entries)
+	require.NoError(t, err)
+	require.Equal(t, "00000000-0000-4000-8000-000000000001", glossary.GlossaryID)
+	require.Equal(t, 2, glossary.EntryCount)
+	require.Equal(t, "en", glossary.SourceLang)
+	require.True(t, glossary.Ready)
+
+	list, err := cli.ListGlossaries(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+
+	got, err := cli.GetGlossary(ctx, glossary.GlossaryID)
+	require.NoError(t, err)
+	require.Equal(t, glossary, got)
+
+	gotEntries, err := cli.GetGlossaryEntries(ctx, glossary.GlossaryID)
+	require.NoError(t, err)
+	require.Equal(t, entries, gotEntries)
+
+	pairs, err := cli.GetGlossaryLanguagePairs(ctx)
+	require.NoError(t, err)
+	require.Contains(t, pairs, deepl.GlossaryLanguagePair{SourceLang: "en", TargetLang: "de"})
+
+	require.NoError(t, cli.DeleteGlossary(ctx, glossary.GlossaryID))
+
+	_, err = cli.GetGlossary(ctx, glossary.GlossaryID)
+	require.ErrorIs(t, err, deepl.ErrNotFound)
+
+	list, err = cli.ListGlossaries(ctx)
+	require.NoError(t, err)
+	require.Empty(t, list)
+}
+
+func TestServer_glossary_bad_entries(t *testing.T) {
+	t.Parallel()
+
+	_, cli := newServerClient(t)
+
+	_, err := cli.CreateGlossaryFromData(context.Background(), "name", "EN", "DE", "no tab", deepl.GlossaryEntriesTSV)
+	require.ErrorIs(t, err, deepl.ErrBadRequest)
+}
+
+// ----------------------------------------------------------------------------
+//  Documents
+// ----------------------------------------------------------------------------
+
+func TestServer_documents(t *testing.T) {
+	t.Parallel()
+
+	_, cli := newServerClient(t)
+
+	var out bytes.Buffer
+
+	status, err := cli.TranslateDocument(context.Background(), strings.NewReader("Hello"), "hello.txt", &out,
+		&deepl.DocumentOptions{TargetLang: "DE"})
+	require.NoError(t, err)
+	require.True(t, status.Done())
+	require.Equal(t, 5, status.BilledCharacters)
+	require.Equal(t, "[DE] Hello", out.String())
+
+	usage, err := cli.GetAccountStatus(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(5), usage.CharacterCount)
+	require.Equal(t, int64(1), usage.DocumentCount)
+}
+
+func TestServer_documents_polls(t *testing.T) {
+	t.Parallel()
+
+	srv, cli := newServerClient(t)
+	srv.SetDocumentPolls(1)
+
+	handle, err := cli.UploadDocument(context.Background(), strings.NewReader("Hello"), "hello.txt",
+		&deepl.DocumentOptions{TargetLang: "DE"})
+	require.NoError(t, err)
+
+	err = cli.DownloadDocument(context.Background(), handle, new(bytes.Buffer))
+	require.ErrorIs(t, err, deepl.ErrServiceUnavailable, "document should not be ready yet")
+
+	status, err := cli.GetDocumentStatus(context.Background(), handle)
+	require.NoError(t, err)
+	require.Equal(t, deepl.DocumentStatusTranslating, status.Status)
+
+	status, err = cli.GetDocumentStatus(context.Background(), handle)
+	require.NoError(t, err)
+	require.True(t, status.Done())
+
+	require.NoError(t, cli.DownloadDocument(context.Background(), handle, new(bytes.Buffer)))
+
+	_, err = cli.GetDocumentStatus(context.Background(), handle)
+	require.ErrorIs(t, err, deepl.ErrNotFound, "document can only be downloaded once")
+}
+
+// ----------------------------------------------------------------------------
+//  Failures
+// ----------------------------------------------------------------------------
+
+func TestServer_InjectFailure(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		expectErr error
+		status    int
+	}{
+		{status: http.StatusTooManyRequests, expectErr: deepl.ErrTooManyRequests},
+		{status: deepl.StatusQuotaExceeded, expectErr: deepl.ErrQuotaExceeded},
+		{status: http.StatusServiceUnavailable, expectErr: deepl.ErrServiceUnavailable},
+	} {
+		srv, cli := newServerClient(t)
+
+		srv.InjectFailure(deepltest.Failure{Path: "/v2/translate", Status: test.status})
+
+		_, err := cli.GetAccountStatus(context.Background())
+		require.NoError(t, err, "failure should be limited to the path")
+
+		_, err = cli.TranslateSentence(context.Background(), "Hello", "EN", "DE")
+		require.ErrorIs(t, err, test.expectErr)
+
+		_, err = cli.TranslateSentence(context.Background(), "Hello", "EN", "DE")
+		require.NoError(t, err, "failure should be applied only once")
+	}
+}
+
+func TestServer_InjectFailure_retried(t *testing.T) {
+	t.Parallel()
+
+	srv, cli := newServerClient(t, deepl.WithRetryPolicy(deepl.RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    time.Millisecond,
+	}), deepl.WithLogger(log.New(io.Discard, "", 0)))
+
+	srv.InjectFailure(deepltest.Failure{Status: http.StatusTooManyRequests, RetryAfter: "0", Times: 2})
+
+	resp, err := cli.TranslateSentence(context.Background(), "Hello", "EN", "DE")
+	require.NoError(t, err)
+	require.Equal(t, "[DE] Hello", resp.Translations[0].Text)
+	require.Len(t, srv.Requests(), 3)
+}
+
+func TestServer_slow_response(t *testing.T) {
+	t.Parallel()
+
+	srv, cli := newServerClient(t)
+
+	srv.InjectFailure(deepltest.Failure{Delay: time.Second})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := cli.TranslateSentence(ctx, "Hello", "EN", "DE")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	srv.SetLatency(10 * time.Millisecond)
+
+	timeStart := time.Now()
+
+	_, err = cli.TranslateSentence(context.Background(), "Hello", "EN", "DE")
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, time.Since(timeStart), 10*time.Millisecond)
+
+	srv.InjectFailure(deepltest.Failure{Status: http.StatusServiceUnavailable, Times: 5})
+	srv.ClearFailures()
+
+	_, err = cli.TranslateSentence(context.Background(), "Hello", "EN", "DE")
+	require.NoError(t, err)
+}
+
+// ----------------------------------------------------------------------------
+//  Recording
+// ----------------------------------------------------------------------------
+
+func TestServer_Requests(t *testing.T) {
+	t.Parallel()
+
+	srv, cli := newServerClient(t)
+
+	_, err := cli.Translate(context.Background(), []string{"Hello", "World"}, &deepl.TranslateOptions{
+		TargetLang: "DE",
+		Formality:  deepl.FormalityLess,
+	})
+	require.NoError(t, err)
+
+	requests := srv.Requests()
+	require.Len(t, requests, 1)
+	require.Equal(t, http.MethodPost, requests[0].Method)
+	require.Equal(t, "/v2/translate", requests[0].Path)
+	require.Equal(t, []string{"Hello", "World"}, requests[0].Params["text"])
+	require.Equal(t, "less", requests[0].Params.Get("formality"))
+	require.Equal(t, "DeepL-Auth-Key REDACTED", requests[0].Header.Get("Authorization"))
+}
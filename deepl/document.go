@@ -0,0 +1,378 @@
+package deepl
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+)
+
+// Intervals to poll the status of the document translation. The interval starts
+// from the min and doubles on each poll up to the max. If the API returns the
+// estimated seconds remaining, it is used instead within the range.
+var (
+	documentPollIntervalMin = 1 * time.Second
+	documentPollIntervalMax = 30 * time.Second
+)
+
+// ----------------------------------------------------------------------------
+//  Types
+// ----------------------------------------------------------------------------
+
+// DocumentStatusCode is an enum type for the status of a document translation.
+type DocumentStatusCode string
+
+const (
+	// DocumentStatusQueued means the translation job is waiting to be processed.
+	DocumentStatusQueued DocumentStatusCode = "queued"
+	// DocumentStatusTranslating means the translation is in progress.
+	DocumentStatusTranslating DocumentStatusCode = "translating"
+	// DocumentStatusDone means the translated document is ready to download.
+	DocumentStatusDone DocumentStatusCode = "done"
+	// DocumentStatusError means an irrecoverable error occurred while translating.
+	DocumentStatusError DocumentStatusCode = "error"
+)
+
+// DocumentHandle holds the ID and the encryption key of an uploaded document.
+// Both are required to get the status and the result of the translation.
+type DocumentHandle struct {
+	DocumentID  string `json:"document_id"`
+	DocumentKey string `json:"document_key"`
+}
+
+// DocumentStatus holds the status of a document translation.
+type DocumentStatus struct {
+	DocumentID string             `json:"document_id"`
+	Status     DocumentStatusCode `json:"status"`
+	// ErrorMessage is only available if the status is DocumentStatusError.
+	ErrorMessage string `json:"error_message,omitempty"`
+	// SecondsRemaining is the estimated time until the translation is done. Only
+	// available while translating.
+	SecondsRemaining int `json:"seconds_remaining,omitempty"`
+	// BilledCharacters is only available if the status is DocumentStatusDone.
+	BilledCharacters int `json:"billed_characters,omitempty"`
+}
+
+// Done returns true if the translated document is ready to download.
+func (s *DocumentStatus) Done() bool {
+	return s.Status == DocumentStatusDone
+}
+
+// Failed returns true if the translation failed.
+func (s *DocumentStatus) Failed() bool {
+	return s.Status == DocumentStatusError
+}
+
+// DocumentOptions holds the request parameters of the document translation API
+// other than the document itself.
+type DocumentOptions struct {
+	// SourceLang is the language code of the document. If empty, the API will
+	// detect the language automatically.
+	SourceLang string
+	// TargetLang is the language code to translate the document into. (Required)
+	TargetLang string
+	// Formality sets whether the translation should lean towards formal or
+	// informal language.
+	Formality Formality
+	// GlossaryID is the ID of the glossary to use. SourceLang is required if set.
+	GlossaryID string
+	// OutputFormat is the file extension of the translated document if it differs
+	// from the uploaded one. E.g. "docx" to translate a PDF into a Word document.
+	OutputFormat string
+}
+
+// Validate returns an error if the options are invalid.
+func (o *DocumentOptions) Validate() error {
+	if o.TargetLang == "" {
+		return NewErr("target_lang is required")
+	}
+
+	return (&TranslateOptions{
+		SourceLang: o.SourceLang,
+		TargetLang: o.TargetLang,
+		Formality:  o.Formality,
+		GlossaryID: o.GlossaryID,
+	}).Validate()
+}
+
+// ----------------------------------------------------------------------------
+//  Methods of Client
+// ----------------------------------------------------------------------------
+
+// TranslateDocument translates the document read from "in" and writes the
+// translated document to "out".
+//
+// It uploads the document, polls the status with backoff until the translation
+// is done or failed and then downloads the result. The filename is required for
+// the API to detect the file type by its extension. If the translation failed,
+// the failed status is returned along with the error. Such as the document ID
+// and the ErrorMessage to look into the failure.
+func (c *Client) TranslateDocument(
+	ctx context.Context,
+	in io.Reader,
+	filename string,
+	out io.Writer,
+	opts *DocumentOptions,
+) (*DocumentStatus, error) {
+	handle, err := c.UploadDocument(ctx, in, filename, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := c.WaitDocument(ctx, handle)
+	if err != nil {
+		return status, err
+	}
+
+	if err := c.DownloadDocument(ctx, handle, out); err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
+
+// UploadDocument uploads the document read from "in" to be translated and
+// returns its handle. The filename is required for the API to detect the file
+// type by its extension.
+func (c *Client) UploadDocument(
+	ctx context.Context,
+	in io.Reader,
+	filename string,
+	opts *DocumentOptions,
+) (*DocumentHandle, error) {
+	if opts == nil {
+		return nil, NewErr("document options is nil")
+	}
+
+	if err := opts.Validate(); err != nil {
+		return nil, WrapIfErr(err, "invalid document options")
+	}
+
+	if err := c.validateLanguages(ctx, opts.SourceLang, opts.TargetLang, opts.Formality); err != nil {
+		return nil, WrapIfErr(err, "invalid language")
+	}
+
+	if filename == "" {
+		return nil, NewErr("filename is required to detect the file type")
+	}
+
+	// The billed characters are unknown until translated. Only check the usage
+	// here. They are billed by WaitDocument once done.
+	if _, err := c.reserveBudget(ctx, 0); err != nil {
+		return nil, err
+	}
+
+	urlVal := url.Values{}
+
+	urlVal.Set("target_lang", opts.TargetLang)
+	addIfNotEmpty(urlVal, "source_lang", opts.SourceLang)
+	addIfNotEmpty(urlVal, "formality", string(opts.Formality))
+	addIfNotEmpty(urlVal, "glossary_id", opts.GlossaryID)
+	addIfNotEmpty(urlVal, "output_format", opts.OutputFormat)
+
+	body, contentType, err := createMultipartBody(urlVal, in, filename)
+	if err != nil {
+		return nil, WrapIfErr(err, "failed to create multipart body")
+	}
+
+	// Uploading twice would translate and bill the document twice
+	ctx = withNonIdempotent(ctx)
+
+	req, err := c.newRequestWithBody(ctx, http.MethodPost, "v2/document", nil, body, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	handle := new(DocumentHandle)
+	if err := responseParse(resp, handle); err != nil {
+		return nil, WrapIfErr(err, "failed to parse response to DocumentHandle")
+	}
+
+	return handle, nil
+}
+
+// GetDocumentStatus returns the current status of the document translation.
+func (c *Client) GetDocumentStatus(ctx context.Context, handle *DocumentHandle) (*DocumentStatus, error) {
+	urlVal, err := handle.urlValues()
+	if err != nil {
+		return nil, err
+	}
+
+	status := new(DocumentStatus)
+
+	err = c.requestJSON(ctx, http.MethodPost, "v2/document/"+handle.DocumentID, urlVal, status)
+	if err != nil {
+		return nil, WrapIfErr(err, "failed to parse response to DocumentStatus")
+	}
+
+	return status, nil
+}
+
+// WaitDocument polls the status of the document translation with backoff until
+// it is done. It returns an error if the translation failed or the context is
+// done.
+//
+// Once done, the billed characters of the document are counted in the budget
+// set by WithBudget.
+func (c *Client) WaitDocument(ctx context.Context, handle *DocumentHandle) (*DocumentStatus, error) {
+	interval := documentPollIntervalMin
+
+	// Reuse the timer between the polls. It is stopped until the first wait.
+	timer := time.NewTimer(documentPollIntervalMax)
+	timer.Stop()
+
+	defer timer.Stop()
+
+	for {
+		status, err := c.GetDocumentStatus(ctx, handle)
+		if err != nil {
+			return nil, err
+		}
+
+		if status.Done() {
+			if c.budget != nil {
+				c.budget.settle(0, status.BilledCharacters)
+			}
+
+			return status, nil
+		}
+
+		if status.Failed() {
+			return status, NewErr("document translation failed. Returned message: %s", status.ErrorMessage)
+		}
+
+		wait := interval
+		if status.SecondsRemaining > 0 {
+			wait = time.Duration(status.SecondsRemaining) * time.Second
+		}
+
+		wait = clampDuration(wait, documentPollIntervalMin, documentPollIntervalMax)
+
+		timer.Reset(wait)
+
+		select {
+		case <-ctx.Done():
+			return nil, WrapIfErr(ctx.Err(), "stopped waiting for the document translation")
+		case <-timer.C:
+		}
+
+		interval = clampDuration(interval*2, documentPollIntervalMin, documentPollIntervalMax)
+	}
+}
+
+// DownloadDocument downloads the translated document and writes it to "out".
+// The translation must be done before downloading. Note that the document can
+// only be downloaded once.
+func (c *Client) DownloadDocument(ctx context.Context, handle *DocumentHandle, out io.Writer) error {
+	urlVal, err := handle.urlValues()
+	if err != nil {
+		return err
+	}
+
+	req, err := c.newRequest(ctx, http.MethodPost, "v2/document/"+handle.DocumentID+"/result", urlVal)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if !isStatusSuccess(resp.StatusCode) {
+		_, err := responseRead(resp)
+
+		return WrapIfErr(err, "failed to download document")
+	}
+
+	_, err = io.Copy(out, resp.Body)
+
+	return WrapIfErr(err, "failed to write the downloaded document")
+}
+
+// ----------------------------------------------------------------------------
+//  Private Functions
+// ----------------------------------------------------------------------------
+
+// urlValues returns the document key as the request parameter.
+func (h *DocumentHandle) urlValues() (url.Values, error) {
+	if h == nil {
+		return nil, NewErr("document handle is nil")
+	}
+
+	if err := validateID("document ID", h.DocumentID); err != nil {
+		return nil, err
+	}
+
+	if h.DocumentKey == "" {
+		return nil, NewErr("document key is empty")
+	}
+
+	urlVal := url.Values{}
+	urlVal.Set("document_key", h.DocumentKey)
+
+	return urlVal, nil
+}
+
+// clampDuration returns the duration within the range of min and max.
+func clampDuration(duration, minDuration, maxDuration time.Duration) time.Duration {
+	if duration < minDuration {
+		return minDuration
+	}
+
+	if duration > maxDuration {
+		return maxDuration
+	}
+
+	return duration
+}
+
+// createMultipartBody returns a multipart form body with the given fields and
+// the file read from "in". It also returns the content type with the boundary.
+func createMultipartBody(fields url.Values, in io.Reader, filename string) (io.Reader, string, error) {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys) // for reproducible requests
+
+	for _, key := range keys {
+		for _, value := range fields[key] {
+			if err := writer.WriteField(key, value); err != nil {
+				return nil, "", WrapIfErr(err, "failed to write field %s", key)
+			}
+		}
+	}
+
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		return nil, "", WrapIfErr(err, "failed to create form file")
+	}
+
+	if _, err := io.Copy(part, in); err != nil {
+		return nil, "", WrapIfErr(err, "failed to read the document")
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, "", WrapIfErr(err, "failed to close multipart writer")
+	}
+
+	return body, writer.FormDataContentType(), nil
+}
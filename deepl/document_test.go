@@ -0,0 +1,222 @@
+package deepl
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setDocumentPollInterval sets the poll intervals of the document translation
+// short for testing and returns a function to restore them.
+func setDocumentPollInterval() func() {
+	oldMin, oldMax := documentPollIntervalMin, documentPollIntervalMax
+
+	documentPollIntervalMin = time.Millisecond
+	documentPollIntervalMax = 5 * time.Millisecond
+
+	return func() {
+		documentPollIntervalMin, documentPollIntervalMax = oldMin, oldMax
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  Client.TranslateDocument
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // do not parallelize due to global variable change
+func TestClient_TranslateDocument(t *testing.T) {
+	defer setDocumentPollInterval()()
+
+	cli, teardown := spawnDocumentServer(t, 3, DocumentStatusDone)
+	defer teardown()
+
+	out := new(bytes.Buffer)
+
+	status, err := cli.TranslateDocument(
+		context.TODO(),
+		strings.NewReader("hello world"),
+		"sample.txt",
+		out,
+		&DocumentOptions{TargetLang: "DE"},
+	)
+
+	require.NoError(t, err, "translation should succeed")
+	require.Equal(t, "HELLO WORLD", out.String(), "downloaded document is wrong")
+	require.True(t, status.Done(), "returned status should be done")
+	require.Equal(t, 11, status.BilledCharacters, "billed characters is wrong")
+}
+
+//nolint:paralleltest // do not parallelize due to global variable change
+func TestClient_TranslateDocument_budget(t *testing.T) {
+	defer setDocumentPollInterval()()
+
+	cli, teardown := spawnDocumentServer(t, 3, DocumentStatusDone)
+	defer teardown()
+
+	// Refreshed recently to not request the usage
+	cli.budget = &budgetGuard{
+		refreshing: make(chan struct{}, 1),
+		budget:     Budget{HardRatio: 0.9, RefreshInterval: time.Hour},
+		status:     BudgetStatus{RefreshedAt: time.Now(), CharacterCount: 100, CharacterLimit: 1000},
+	}
+
+	_, err := cli.TranslateDocument(
+		context.TODO(),
+		strings.NewReader("hello world"),
+		"sample.txt",
+		new(bytes.Buffer),
+		&DocumentOptions{TargetLang: "DE"},
+	)
+	require.NoError(t, err)
+
+	status := cli.BudgetStatus()
+
+	require.Equal(t, int64(11), status.Billed, "billed characters of the document should be counted")
+	require.Zero(t, status.Reserved)
+	require.Equal(t, int64(111), status.Used())
+}
+
+//nolint:paralleltest // do not parallelize due to global variable change
+func TestClient_TranslateDocument_failed(t *testing.T) {
+	defer setDocumentPollInterval()()
+
+	cli, teardown := spawnDocumentServer(t, 1, DocumentStatusError)
+	defer teardown()
+
+	out := new(bytes.Buffer)
+
+	status, err := cli.TranslateDocument(
+		context.TODO(),
+		strings.NewReader("hello world"),
+		"sample.txt",
+		out,
+		&DocumentOptions{TargetLang: "DE"},
+	)
+
+	require.Error(t, err, "failed translation should return an error")
+	require.NotNil(t, status, "failed status should be returned along with the error")
+	require.True(t, status.Failed(), "returned status should be failed")
+	require.NotEmpty(t, status.DocumentID, "document ID should be returned to look into the failure")
+	require.Equal(t, "Source and target language are equal.", status.ErrorMessage)
+	assert.Contains(t, err.Error(), "document translation failed",
+		"it should contain the error reason")
+	assert.Contains(t, err.Error(), "Source and target language are equal.",
+		"it should contain the returned message")
+	require.Empty(t, out.String(), "nothing should be written on error")
+}
+
+//nolint:paralleltest // do not parallelize due to global variable change
+func TestClient_WaitDocument_context_canceled(t *testing.T) {
+	defer setDocumentPollInterval()()
+
+	cli, teardown := spawnDocumentServer(t, 1000, DocumentStatusDone)
+	defer teardown()
+
+	handle, err := cli.UploadDocument(
+		context.TODO(),
+		strings.NewReader("hello world"),
+		"sample.txt",
+		&DocumentOptions{TargetLang: "DE"},
+	)
+	require.NoError(t, err, "upload should succeed")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	status, err := cli.WaitDocument(ctx, handle)
+
+	require.Error(t, err, "it should stop waiting on context done")
+	require.Nil(t, status, "returned value should be nil on error")
+	require.ErrorIs(t, err, context.DeadlineExceeded,
+		"it should contain the context error")
+}
+
+func TestClient_DownloadDocument_not_ready(t *testing.T) {
+	t.Parallel()
+
+	cli, teardown := spawnDocumentServer(t, 0, DocumentStatusTranslating)
+	defer teardown()
+
+	handle, err := cli.UploadDocument(
+		context.TODO(),
+		strings.NewReader("hello world"),
+		"sample.txt",
+		&DocumentOptions{TargetLang: "DE"},
+	)
+	require.NoError(t, err, "upload should succeed")
+
+	err = cli.DownloadDocument(context.TODO(), handle, new(bytes.Buffer))
+
+	require.Error(t, err, "downloading before done should return an error")
+	assert.Contains(t, err.Error(), "failed to download document",
+		"it should contain the error reason")
+	assert.Contains(t, err.Error(), "Document not ready",
+		"it should contain the returned message")
+}
+
+func TestClient_UploadDocument_bad_input(t *testing.T) {
+	t.Parallel()
+
+	cli, err := New(APIFree, nil)
+	require.NoError(t, err, "failed to create client")
+
+	for index, tt := range []struct {
+		filename         string
+		opts             *DocumentOptions
+		expectErrMessage string
+	}{
+		{"sample.txt", nil, "document options is nil"},
+		{"sample.txt", &DocumentOptions{}, "target_lang is required"},
+		{"sample.txt", &DocumentOptions{TargetLang: "DE", Formality: "rude"}, "unsupported formality"},
+		{"sample.txt", &DocumentOptions{TargetLang: "DE", GlossaryID: "abc"}, "source_lang is required"},
+		{"", &DocumentOptions{TargetLang: "DE"}, "filename is required"},
+	} {
+		handle, err := cli.UploadDocument(context.TODO(), strings.NewReader("hello"), tt.filename, tt.opts)
+
+		require.Error(t, err, "test #%d: it should return an error", index+1)
+		require.Nil(t, handle, "test #%d: returned value should be nil on error", index+1)
+		assert.Contains(t, err.Error(), tt.expectErrMessage,
+			"test #%d: it should contain the error reason", index+1)
+	}
+}
+
+func TestClient_GetDocumentStatus_bad_handle(t *testing.T) {
+	t.Parallel()
+
+	cli, err := New(APIFree, nil)
+	require.NoError(t, err, "failed to create client")
+
+	for index, tt := range []struct {
+		handle           *DocumentHandle
+		expectErrMessage string
+	}{
+		{nil, "document handle is nil"},
+		{&DocumentHandle{DocumentID: "", DocumentKey: "key"}, "document ID is empty"},
+		{&DocumentHandle{DocumentID: "../id", DocumentKey: "key"}, "invalid document ID"},
+		{&DocumentHandle{DocumentID: "id", DocumentKey: ""}, "document key is empty"},
+	} {
+		status, err := cli.GetDocumentStatus(context.TODO(), tt.handle)
+
+		require.Error(t, err, "test #%d: it should return an error", index+1)
+		require.Nil(t, status, "test #%d: returned value should be nil on error", index+1)
+		assert.Contains(t, err.Error(), tt.expectErrMessage,
+			"test #%d: it should contain the error reason", index+1)
+
+		err = cli.DownloadDocument(context.TODO(), tt.handle, new(bytes.Buffer))
+
+		require.Error(t, err, "test #%d: it should return an error", index+1)
+	}
+}
+
+func Test_clampDuration(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, time.Second, clampDuration(time.Millisecond, time.Second, time.Minute))
+	require.Equal(t, time.Minute, clampDuration(time.Hour, time.Second, time.Minute))
+	require.Equal(t, 2*time.Second, clampDuration(2*time.Second, time.Second, time.Minute))
+}
@@ -1,12 +1,15 @@
 package deepl
 
 import (
+	"encoding/json"
+	"io"
+	"log"
 	"net/http"
 	"net/http/httptest"
-	"net/url"
 	"os"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -16,7 +19,7 @@ import (
 // as a dummy response during testing.
 func createTranslateResponse(detectLang string, text string) *TranslateResponse {
 	resp := &TranslateResponse{
-		[]translation{
+		[]Translation{
 			{
 				DetectedSourceLanguage: detectLang,
 				Text:                   text,
@@ -27,21 +30,58 @@ func createTranslateResponse(detectLang string, text string) *TranslateResponse
 	return resp
 }
 
+// newTestClient returns a new client to request the given test server with the
+// dummy API key.
+func newTestClient(t *testing.T, server *httptest.Server) *Client {
+	t.Helper()
+
+	cli, err := New(
+		APICustom,
+		log.New(io.Discard, "", 0),
+		WithBaseURL(server.URL),
+		WithHTTPClient(server.Client()),
+		WithAPIKey(dummyAuthKey),
+	)
+	require.NoError(t, err, "failed to create client of mock server")
+
+	return cli
+}
+
 // spawnTestServer returns a test server and a teardown function.
+//
+// The expectedRawParams is compared with the form-encoded request body for POST
+// requests and with the URL query for the other methods. The API key must be
+// sent via the "Authorization" header.
 func spawnTestServer(
 	t *testing.T,
 	mockResponseHeaderFile,
 	mockResponseBodyFile,
 	expectedMethod,
 	expectedRequestPath,
-	expectedRawQuery string,
+	expectedRawParams string,
 ) (*Client, func()) {
 	t.Helper()
 
 	server := httptest.NewServer(http.HandlerFunc(func(respWriter http.ResponseWriter, req *http.Request) {
 		require.Equal(t, expectedMethod, req.Method, "request method is wrong")
 		require.Equal(t, expectedRequestPath, req.URL.Path, "request path is wrong")
-		require.Equal(t, expectedRawQuery, req.URL.RawQuery, "request query is wrong")
+		require.Equal(t, "DeepL-Auth-Key "+dummyAuthKey, req.Header.Get("Authorization"),
+			"API key should be sent via the Authorization header")
+		require.NotContains(t, req.URL.RawQuery, "auth_key",
+			"API key should not be sent in the URL")
+
+		rawParams := req.URL.RawQuery
+
+		if req.Method == http.MethodPost {
+			require.Empty(t, req.URL.RawQuery, "POST request should not have URL query")
+
+			bodyBytes, err := io.ReadAll(req.Body)
+			require.NoError(t, err, "failed to read request body")
+
+			rawParams = string(bodyBytes)
+		}
+
+		require.Equal(t, expectedRawParams, rawParams, "request parameters are wrong")
 
 		headerBytes, err := os.ReadFile(mockResponseHeaderFile)
 		require.NoError(t, err, "failed to read header '%s'", mockResponseHeaderFile)
@@ -60,17 +100,266 @@ func spawnTestServer(
 		require.NoError(t, err, "failed to write response body")
 	}))
 
-	serverURL, err := url.Parse(server.URL)
-	require.NoError(t, err, "failed to get mock server URL")
+	cli := newTestClient(t, server)
+	teardown := func() {
+		server.Close()
+	}
+
+	return cli, teardown
+}
+
+// spawnEchoServer returns a client of a test server that translates the texts
+// in the request to "<target_lang>:<text>", a teardown function and a pointer to
+// the number of translation requests received by the server.
+//
+// The server also responds to "/v2/languages" with the files in the testdata
+// directory.
+func spawnEchoServer(t *testing.T) (*Client, func(), *int32) {
+	t.Helper()
+
+	var countReq int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(respWriter http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/v2/languages" {
+			bodyBytes, err := os.ReadFile("testdata/GetLanguages/" + req.URL.Query().Get("type") + "-success-body")
+			require.NoError(t, err, "failed to read languages")
+
+			_, err = respWriter.Write(bodyBytes)
+			require.NoError(t, err, "failed to write response body")
+
+			return
+		}
+
+		atomic.AddInt32(&countReq, 1)
+
+		require.NoError(t, req.ParseForm(), "failed to parse request")
+
+		sourceLang := req.Form.Get("source_lang")
+		targetLang := req.Form.Get("target_lang")
+		transResp := new(TranslateResponse)
+
+		for _, text := range req.Form["text"] {
+			transResp.Translations = append(transResp.Translations, Translation{
+				DetectedSourceLanguage: sourceLang,
+				Text:                   targetLang + ":" + text,
+			})
+		}
 
-	cli := &Client{
-		BaseURL:    serverURL,
-		HTTPClient: server.Client(),
-		Logger:     nil,
+		err := json.NewEncoder(respWriter).Encode(transResp)
+		require.NoError(t, err, "failed to write response body")
+	}))
+
+	cli := newTestClient(t, server)
+	teardown := func() {
+		server.Close()
 	}
+
+	return cli, teardown, &countReq
+}
+
+// spawnDocumentServer returns a client of a test server that mimics the document
+// translation API. The uploaded document is "translated" to upper case. The
+// status is "translating" for the first statusBeforeDone polls and then it
+// becomes the given finalStatus.
+func spawnDocumentServer(
+	t *testing.T,
+	statusBeforeDone int,
+	finalStatus DocumentStatusCode,
+) (*Client, func()) {
+	t.Helper()
+
+	const (
+		documentID  = "04DE5AD98A02647D83285A36021911C6"
+		documentKey = "0CB0054F1C132C1625B392EADDA41CB754A742822F6877173029A6C487E7F60A"
+	)
+
+	var (
+		translated []byte
+		countPoll  int32
+	)
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/v2/document", func(respWriter http.ResponseWriter, req *http.Request) {
+		require.Equal(t, http.MethodPost, req.Method, "request method is wrong")
+		require.Equal(t, "DeepL-Auth-Key "+dummyAuthKey, req.Header.Get("Authorization"),
+			"API key should be sent via the Authorization header")
+
+		file, header, err := req.FormFile("file")
+		require.NoError(t, err, "failed to read the uploaded file")
+
+		defer file.Close()
+
+		require.Equal(t, "sample.txt", header.Filename, "uploaded filename is wrong")
+		require.Equal(t, "DE", req.FormValue("target_lang"), "target_lang is wrong")
+
+		content, err := io.ReadAll(file)
+		require.NoError(t, err, "failed to read the uploaded file")
+
+		translated = []byte(strings.ToUpper(string(content)))
+
+		_, err = respWriter.Write([]byte(`{"document_id":"` + documentID + `","document_key":"` + documentKey + `"}`))
+		require.NoError(t, err, "failed to write response body")
+	})
+
+	mux.HandleFunc("/v2/document/"+documentID, func(respWriter http.ResponseWriter, req *http.Request) {
+		require.Empty(t, req.URL.RawQuery, "document key should be sent in the request body")
+		require.Equal(t, documentKey, req.FormValue("document_key"), "document_key is wrong")
+
+		status := &DocumentStatus{
+			DocumentID:       documentID,
+			Status:           finalStatus,
+			SecondsRemaining: 0,
+			BilledCharacters: len(translated),
+		}
+
+		if atomic.AddInt32(&countPoll, 1) <= int32(statusBeforeDone) {
+			status.Status = DocumentStatusTranslating
+			status.SecondsRemaining = 1
+			status.BilledCharacters = 0
+		}
+
+		if status.Failed() {
+			status.ErrorMessage = "Source and target language are equal."
+			status.BilledCharacters = 0
+		}
+
+		require.NoError(t, json.NewEncoder(respWriter).Encode(status), "failed to write response body")
+	})
+
+	mux.HandleFunc("/v2/document/"+documentID+"/result", func(respWriter http.ResponseWriter, req *http.Request) {
+		require.Equal(t, documentKey, req.FormValue("document_key"), "document_key is wrong")
+
+		if finalStatus != DocumentStatusDone {
+			respWriter.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = respWriter.Write([]byte(`{"message":"Document not ready"}`))
+
+			return
+		}
+
+		_, err := respWriter.Write(translated)
+		require.NoError(t, err, "failed to write response body")
+	})
+
+	server := httptest.NewServer(mux)
+
+	cli := newTestClient(t, server)
 	teardown := func() {
 		server.Close()
 	}
 
 	return cli, teardown
 }
+
+// roundTripFunc is an adapter to use a function as an http.RoundTripper. It is
+// used to capture the requests without the network.
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+// RoundTrip calls f(req).
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// scriptedResponse is a response of the scripted test server.
+type scriptedResponse struct {
+	body       string
+	retryAfter string
+	status     int
+}
+
+// spawnScriptedServer returns a client of a test server that responds with the
+// given responses in order, a teardown function and a pointer to the number of
+// requests received. The last response is repeated once the script runs out.
+func spawnScriptedServer(t *testing.T, script ...scriptedResponse) (*Client, func(), *int32) {
+	t.Helper()
+
+	var countReq int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(respWriter http.ResponseWriter, req *http.Request) {
+		index := int(atomic.AddInt32(&countReq, 1)) - 1
+		if index >= len(script) {
+			index = len(script) - 1
+		}
+
+		// The body must be rewound on retries
+		bodyBytes, err := io.ReadAll(req.Body)
+		require.NoError(t, err, "failed to read request body")
+
+		require.Equal(t, req.ContentLength, int64(len(bodyBytes)), "request body is not fully sent")
+
+		if script[index].retryAfter != "" {
+			respWriter.Header().Set("Retry-After", script[index].retryAfter)
+		}
+
+		respWriter.WriteHeader(script[index].status)
+
+		_, err = respWriter.Write([]byte(script[index].body))
+		require.NoError(t, err, "failed to write response body")
+	}))
+
+	cli := newTestClient(t, server)
+	teardown := func() {
+		server.Close()
+	}
+
+	return cli, teardown, &countReq
+}
+
+// spawnQuotaServer returns a client of a test server that mimics the character
+// quota of the account. The translations are echoed as "TARGET:text" and billed
+// to the usage starting from characterCount. It also returns a teardown function
+// and a pointer to the number of usage requests received.
+func spawnQuotaServer(t *testing.T, characterCount, characterLimit int) (*Client, func(), *int32) {
+	t.Helper()
+
+	var (
+		countUsage int32
+		used       = int64(characterCount)
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(respWriter http.ResponseWriter, req *http.Request) {
+		require.NoError(t, req.ParseForm(), "failed to parse request")
+
+		var resp interface{}
+
+		switch req.URL.Path {
+		case "/v2/usage":
+			atomic.AddInt32(&countUsage, 1)
+
+			resp = AccountStatus{
+				CharacterCount: atomic.LoadInt64(&used),
+				CharacterLimit: int64(characterLimit),
+			}
+		case "/v2/translate":
+			transResp := new(TranslateResponse)
+
+			for _, text := range req.Form["text"] {
+				billed := len([]rune(text))
+				atomic.AddInt64(&used, int64(billed))
+
+				trans := Translation{Text: req.Form.Get("target_lang") + ":" + text}
+				if req.Form.Get("show_billed_characters") == "1" {
+					trans.BilledCharacters = billed
+				}
+
+				transResp.Translations = append(transResp.Translations, trans)
+			}
+
+			resp = transResp
+		default:
+			respWriter.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		err := json.NewEncoder(respWriter).Encode(resp)
+		require.NoError(t, err, "failed to write response body")
+	}))
+
+	cli := newTestClient(t, server)
+	teardown := func() {
+		server.Close()
+	}
+
+	return cli, teardown, &countUsage
+}
@@ -111,7 +111,8 @@ func ExampleAPIType() {
 
 //nolint:dupword // duplication in Output is intentional
 func ExampleAPIType_custom_url() {
-	// Force to use the custom URL
+	// Force to use the custom URL for all the clients. This is deprecated, use
+	// WithBaseURL option of New instead to set it per client.
 	deepl.SetCustomURL("http://localhost:8080")
 
 	defer func() {
@@ -142,3 +143,27 @@ func ExampleNew() {
 	fmt.Println(cli.BaseURL)
 	// Output: https://api-free.deepl.com
 }
+
+func ExampleNew_with_options() {
+	// Each client holds its own configuration. Such as a local stub server for
+	// testing and a dedicated API key per tenant.
+	cliStub, err := deepl.New(deepl.APICustom, nil,
+		deepl.WithBaseURL("http://localhost:8080"),
+		deepl.WithUserAgent("MyApp/1.0"),
+		deepl.WithAPIKey("dummy-key:fx"),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	cliProd, err := deepl.New(deepl.APIPro, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(cliStub.BaseURL)
+	fmt.Println(cliProd.BaseURL)
+	// Output:
+	// http://localhost:8080
+	// https://api.deepl.com
+}
@@ -0,0 +1,336 @@
+package deepl
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+//  Types
+// ----------------------------------------------------------------------------
+
+// GlossaryEntriesFormat is an enum type for the format of the glossary entries.
+type GlossaryEntriesFormat string
+
+const (
+	// GlossaryEntriesTSV is the tab-separated values format. Each line contains
+	// a source and a target term separated by a tab.
+	GlossaryEntriesTSV GlossaryEntriesFormat = "tsv"
+	// GlossaryEntriesCSV is the comma-separated values format. Each line contains
+	// a source and a target term. Optionally followed by the source and target
+	// language codes.
+	GlossaryEntriesCSV GlossaryEntriesFormat = "csv"
+)
+
+// Glossary holds the meta information of a glossary.
+type Glossary struct {
+	CreationTime time.Time `json:"creation_time"`
+	GlossaryID   string    `json:"glossary_id"`
+	Name         string    `json:"name"`
+	SourceLang   string    `json:"source_lang"`
+	TargetLang   string    `json:"target_lang"`
+	EntryCount   int       `json:"entry_count"`
+	Ready        bool      `json:"ready"`
+}
+
+// GlossaryLanguagePair is a pair of languages supported by the glossaries.
+type GlossaryLanguagePair struct {
+	SourceLang string `json:"source_lang"`
+	TargetLang string `json:"target_lang"`
+}
+
+// GlossaryEntry is a pair of source and target terms in a glossary.
+type GlossaryEntry struct {
+	Source string
+	Target string
+}
+
+// ----------------------------------------------------------------------------
+//  Type: GlossaryEntries
+// ----------------------------------------------------------------------------
+
+// GlossaryEntries is a list of glossary entries.
+type GlossaryEntries []GlossaryEntry
+
+// ParseGlossaryEntries parses the given data in the given format to a list of
+// glossary entries. Empty lines are ignored.
+func ParseGlossaryEntries(data string, format GlossaryEntriesFormat) (GlossaryEntries, error) {
+	switch format {
+	case GlossaryEntriesTSV:
+		return parseGlossaryEntriesTSV(data)
+	case GlossaryEntriesCSV:
+		return parseGlossaryEntriesCSV(data)
+	}
+
+	return nil, NewErr("unsupported glossary entries format: %q", format)
+}
+
+// Encode returns the entries as a string in the given format.
+func (e GlossaryEntries) Encode(format GlossaryEntriesFormat) (string, error) {
+	if err := e.Validate(); err != nil {
+		return "", WrapIfErr(err, "invalid glossary entries")
+	}
+
+	switch format {
+	case GlossaryEntriesTSV:
+		var builder strings.Builder
+
+		for index, entry := range e {
+			if strings.ContainsAny(entry.Source+entry.Target, "\t") {
+				return "", NewErr("entry #%d contains a tab", index+1)
+			}
+
+			builder.WriteString(entry.Source + "\t" + entry.Target + "\n")
+		}
+
+		return builder.String(), nil
+	case GlossaryEntriesCSV:
+		var buf bytes.Buffer
+
+		writer := csv.NewWriter(&buf)
+
+		for _, entry := range e {
+			_ = writer.Write([]string{entry.Source, entry.Target})
+		}
+
+		writer.Flush()
+
+		return buf.String(), WrapIfErr(writer.Error(), "failed to write CSV")
+	}
+
+	return "", NewErr("unsupported glossary entries format: %q", format)
+}
+
+// Validate returns an error if any of the entries has an empty term or contains
+// a line break.
+func (e GlossaryEntries) Validate() error {
+	for index, entry := range e {
+		if strings.TrimSpace(entry.Source) == "" || strings.TrimSpace(entry.Target) == "" {
+			return NewErr("entry #%d has an empty term", index+1)
+		}
+
+		if strings.ContainsAny(entry.Source+entry.Target, "\r\n") {
+			return NewErr("entry #%d contains a line break", index+1)
+		}
+	}
+
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+//  Methods of Client
+// ----------------------------------------------------------------------------
+
+// CreateGlossary creates a new glossary with the given entries and returns its
+// meta information.
+func (c *Client) CreateGlossary(
+	ctx context.Context,
+	name string,
+	sourceLang string,
+	targetLang string,
+	entries GlossaryEntries,
+) (*Glossary, error) {
+	data, err := entries.Encode(GlossaryEntriesTSV)
+	if err != nil {
+		return nil, WrapIfErr(err, "failed to encode glossary entries")
+	}
+
+	return c.CreateGlossaryFromData(ctx, name, sourceLang, targetLang, data, GlossaryEntriesTSV)
+}
+
+// CreateGlossaryFromData creates a new glossary with the entries in the given
+// format (TSV or CSV) and returns its meta information.
+func (c *Client) CreateGlossaryFromData(
+	ctx context.Context,
+	name string,
+	sourceLang string,
+	targetLang string,
+	data string,
+	format GlossaryEntriesFormat,
+) (*Glossary, error) {
+	if format != GlossaryEntriesTSV && format != GlossaryEntriesCSV {
+		return nil, NewErr("unsupported glossary entries format: %q", format)
+	}
+
+	urlVal := url.Values{}
+
+	urlVal.Set("name", name)
+	urlVal.Set("source_lang", sourceLang)
+	urlVal.Set("target_lang", targetLang)
+	urlVal.Set("entries", data)
+	urlVal.Set("entries_format", string(format))
+
+	// Creating twice would duplicate the glossary
+	ctx = withNonIdempotent(ctx)
+
+	glossary := new(Glossary)
+	if err := c.requestJSON(ctx, http.MethodPost, "v2/glossaries", urlVal, glossary); err != nil {
+		return nil, WrapIfErr(err, "failed to parse response to Glossary")
+	}
+
+	return glossary, nil
+}
+
+// ListGlossaries returns the meta information of all the glossaries.
+func (c *Client) ListGlossaries(ctx context.Context) ([]Glossary, error) {
+	var listResp struct {
+		Glossaries []Glossary `json:"glossaries"`
+	}
+
+	if err := c.requestJSON(ctx, http.MethodGet, "v2/glossaries", nil, &listResp); err != nil {
+		return nil, WrapIfErr(err, "failed to parse response to glossary list")
+	}
+
+	return listResp.Glossaries, nil
+}
+
+// GetGlossary returns the meta information of the glossary with the given ID.
+func (c *Client) GetGlossary(ctx context.Context, glossaryID string) (*Glossary, error) {
+	if err := validateID("glossary ID", glossaryID); err != nil {
+		return nil, err
+	}
+
+	glossary := new(Glossary)
+	if err := c.requestJSON(ctx, http.MethodGet, "v2/glossaries/"+glossaryID, nil, glossary); err != nil {
+		return nil, WrapIfErr(err, "failed to parse response to Glossary")
+	}
+
+	return glossary, nil
+}
+
+// GetGlossaryEntries returns the entries of the glossary with the given ID.
+func (c *Client) GetGlossaryEntries(ctx context.Context, glossaryID string) (GlossaryEntries, error) {
+	if err := validateID("glossary ID", glossaryID); err != nil {
+		return nil, err
+	}
+
+	req, err := c.newRequest(ctx, http.MethodGet, "v2/glossaries/"+glossaryID+"/entries", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Currently, the API only supports TSV format to download
+	req.Header.Set("Accept", "text/tab-separated-values")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := responseRead(resp)
+	if err != nil {
+		return nil, WrapIfErr(err, "failed to read glossary entries")
+	}
+
+	entries, err := ParseGlossaryEntries(string(body), GlossaryEntriesTSV)
+
+	return entries, WrapIfErr(err, "failed to parse glossary entries")
+}
+
+// DeleteGlossary deletes the glossary with the given ID.
+func (c *Client) DeleteGlossary(ctx context.Context, glossaryID string) error {
+	if err := validateID("glossary ID", glossaryID); err != nil {
+		return err
+	}
+
+	req, err := c.newRequest(ctx, http.MethodDelete, "v2/glossaries/"+glossaryID, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	_, err = responseRead(resp)
+
+	return WrapIfErr(err, "failed to delete glossary")
+}
+
+// GetGlossaryLanguagePairs returns the list of language pairs supported by the
+// glossaries.
+func (c *Client) GetGlossaryLanguagePairs(ctx context.Context) ([]GlossaryLanguagePair, error) {
+	var pairsResp struct {
+		SupportedLanguages []GlossaryLanguagePair `json:"supported_languages"`
+	}
+
+	err := c.requestJSON(ctx, http.MethodGet, "v2/glossary-language-pairs", nil, &pairsResp)
+	if err != nil {
+		return nil, WrapIfErr(err, "failed to parse response to glossary language pairs")
+	}
+
+	return pairsResp.SupportedLanguages, nil
+}
+
+// ----------------------------------------------------------------------------
+//  Private Functions
+// ----------------------------------------------------------------------------
+
+// parseGlossaryEntriesCSV parses the entries in CSV format. The optional 3rd and
+// 4th columns (language codes) are ignored.
+func parseGlossaryEntriesCSV(data string) (GlossaryEntries, error) {
+	reader := csv.NewReader(strings.NewReader(data))
+	reader.FieldsPerRecord = -1
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, WrapIfErr(err, "failed to read CSV")
+	}
+
+	entries := make(GlossaryEntries, 0, len(records))
+
+	for index, record := range records {
+		if len(record) < 2 {
+			return nil, NewErr("line #%d has less than 2 columns", index+1)
+		}
+
+		entries = append(entries, GlossaryEntry{Source: record[0], Target: record[1]})
+	}
+
+	return entries, nil
+}
+
+// parseGlossaryEntriesTSV parses the entries in TSV format.
+func parseGlossaryEntriesTSV(data string) (GlossaryEntries, error) {
+	entries := GlossaryEntries{}
+
+	for index, line := range strings.Split(data, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if line == "" {
+			continue
+		}
+
+		terms := strings.Split(line, "\t")
+		if len(terms) != 2 {
+			return nil, NewErr("line #%d must have 2 columns separated by a tab", index+1)
+		}
+
+		entries = append(entries, GlossaryEntry{Source: terms[0], Target: terms[1]})
+	}
+
+	return entries, nil
+}
+
+// validateID returns an error if the ID is empty or can not be used as a path
+// segment of the endpoint. The kind is the name of the ID used in the error.
+func validateID(kind string, id string) error {
+	if id == "" {
+		return NewErr("%s is empty", kind)
+	}
+
+	if strings.ContainsAny(id, "/?#") || id == ".." || id == "." {
+		return NewErr("invalid %s: %q", kind, id)
+	}
+
+	return nil
+}
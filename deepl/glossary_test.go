@@ -0,0 +1,313 @@
+package deepl
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dummyGlossaryID is a dummy glossary ID for testing.
+const dummyGlossaryID = "def3a26b-3e84-45b3-84ae-0c0aaf3525f7"
+
+// ============================================================================
+//  Type: GlossaryEntries
+// ============================================================================
+
+func TestGlossaryEntries_Encode(t *testing.T) {
+	t.Parallel()
+
+	entries := GlossaryEntries{
+		{Source: "Hello", Target: "Hallo"},
+		{Source: "big, bad", Target: `"böse"`},
+	}
+
+	tsv, err := entries.Encode(GlossaryEntriesTSV)
+	require.NoError(t, err)
+	require.Equal(t, "Hello\tHallo\nbig, bad\t\"böse\"\n", tsv,
+		"TSV should be tab-separated")
+
+	csv, err := entries.Encode(GlossaryEntriesCSV)
+	require.NoError(t, err)
+	require.Equal(t, "Hello,Hallo\n\"big, bad\",\"\"\"böse\"\"\"\n", csv,
+		"CSV should quote the terms if needed")
+
+	for _, format := range []GlossaryEntriesFormat{GlossaryEntriesTSV, GlossaryEntriesCSV} {
+		parsed, err := ParseGlossaryEntries(map[GlossaryEntriesFormat]string{
+			GlossaryEntriesTSV: tsv,
+			GlossaryEntriesCSV: csv,
+		}[format], format)
+
+		require.NoError(t, err)
+		require.Equal(t, entries, parsed,
+			"encoded entries should be parsed back as is. format: %s", format)
+	}
+}
+
+func TestGlossaryEntries_Encode_bad_entries(t *testing.T) {
+	t.Parallel()
+
+	for index, tt := range []struct {
+		entries          GlossaryEntries
+		format           GlossaryEntriesFormat
+		expectErrMessage string
+	}{
+		{GlossaryEntries{{"Hello", ""}}, GlossaryEntriesTSV, "entry #1 has an empty term"},
+		{GlossaryEntries{{"a", "b"}, {"Hel\nlo", "Hallo"}}, GlossaryEntriesCSV, "entry #2 contains a line break"},
+		{GlossaryEntries{{"Hel\tlo", "Hallo"}}, GlossaryEntriesTSV, "entry #1 contains a tab"},
+		{GlossaryEntries{{"Hello", "Hallo"}}, "xlsx", "unsupported glossary entries format"},
+	} {
+		act, err := tt.entries.Encode(tt.format)
+
+		require.Error(t, err, "test #%d: it should return an error", index+1)
+		require.Empty(t, act, "test #%d: returned value should be empty on error", index+1)
+		assert.Contains(t, err.Error(), tt.expectErrMessage,
+			"test #%d: it should contain the error reason", index+1)
+	}
+}
+
+func TestParseGlossaryEntries_bad_data(t *testing.T) {
+	t.Parallel()
+
+	for index, tt := range []struct {
+		data             string
+		format           GlossaryEntriesFormat
+		expectErrMessage string
+	}{
+		{"Hello\tHallo\nWorld\n", GlossaryEntriesTSV, "line #2 must have 2 columns"},
+		{"Hello,Hallo\nWorld\n", GlossaryEntriesCSV, "line #2 has less than 2 columns"},
+		{"\"Hello,Hallo\n", GlossaryEntriesCSV, "failed to read CSV"},
+		{"Hello\tHallo\n", "xlsx", "unsupported glossary entries format"},
+	} {
+		act, err := ParseGlossaryEntries(tt.data, tt.format)
+
+		require.Error(t, err, "test #%d: it should return an error", index+1)
+		require.Nil(t, act, "test #%d: returned value should be nil on error", index+1)
+		assert.Contains(t, err.Error(), tt.expectErrMessage,
+			"test #%d: it should contain the error reason", index+1)
+	}
+}
+
+// ============================================================================
+//  Methods of Client
+// ============================================================================
+
+func TestClient_CreateGlossary(t *testing.T) {
+	t.Parallel()
+
+	expectQuery := url.Values{}
+	expectQuery.Set("entries", "Hello\tHallo\nWorld\tWelt\n")
+	expectQuery.Set("entries_format", "tsv")
+	expectQuery.Set("name", "My Glossary")
+	expectQuery.Set("source_lang", "en")
+	expectQuery.Set("target_lang", "de")
+
+	cli, teardown := spawnTestServer(
+		t,
+		"testdata/Glossary/create-success-header",
+		"testdata/Glossary/create-success-body",
+		http.MethodPost,
+		"/v2/glossaries",
+		expectQuery.Encode(),
+	)
+	defer teardown()
+
+	glossary, err := cli.CreateGlossary(context.TODO(), "My Glossary", "en", "de", GlossaryEntries{
+		{Source: "Hello", Target: "Hallo"},
+		{Source: "World", Target: "Welt"},
+	})
+
+	require.NoError(t, err, "response error should be nil on success")
+	require.Equal(t, dummyGlossaryID, glossary.GlossaryID, "response items wrong")
+	require.Equal(t, "My Glossary", glossary.Name, "response items wrong")
+	require.Equal(t, 2, glossary.EntryCount, "response items wrong")
+	require.True(t, glossary.Ready, "response items wrong")
+	require.Equal(t, 2021, glossary.CreationTime.Year(), "response items wrong")
+}
+
+func TestClient_CreateGlossary_bad_input(t *testing.T) {
+	t.Parallel()
+
+	cli, err := New(APIFree, nil)
+	require.NoError(t, err, "failed to create client")
+
+	act, err := cli.CreateGlossary(context.TODO(), "name", "en", "de", GlossaryEntries{{"", ""}})
+
+	require.Error(t, err, "invalid entries should return an error")
+	require.Nil(t, act, "returned value should be nil on error")
+	assert.Contains(t, err.Error(), "failed to encode glossary entries",
+		"it should contain the error reason")
+
+	act, err = cli.CreateGlossaryFromData(context.TODO(), "name", "en", "de", "a\tb", "xlsx")
+
+	require.Error(t, err, "unsupported format should return an error")
+	require.Nil(t, act, "returned value should be nil on error")
+	assert.Contains(t, err.Error(), "unsupported glossary entries format",
+		"it should contain the error reason")
+}
+
+func TestClient_ListGlossaries(t *testing.T) {
+	t.Parallel()
+
+	cli, teardown := spawnTestServer(
+		t,
+		"testdata/Glossary/list-success-header",
+		"testdata/Glossary/list-success-body",
+		http.MethodGet,
+		"/v2/glossaries",
+		"",
+	)
+	defer teardown()
+
+	glossaries, err := cli.ListGlossaries(context.TODO())
+
+	require.NoError(t, err, "response error should be nil on success")
+	require.Len(t, glossaries, 1, "response items wrong")
+	require.Equal(t, dummyGlossaryID, glossaries[0].GlossaryID, "response items wrong")
+}
+
+func TestClient_GetGlossary(t *testing.T) {
+	t.Parallel()
+
+	cli, teardown := spawnTestServer(
+		t,
+		"testdata/Glossary/get-success-header",
+		"testdata/Glossary/get-success-body",
+		http.MethodGet,
+		"/v2/glossaries/"+dummyGlossaryID,
+		"",
+	)
+	defer teardown()
+
+	glossary, err := cli.GetGlossary(context.TODO(), dummyGlossaryID)
+
+	require.NoError(t, err, "response error should be nil on success")
+	require.Equal(t, "en", glossary.SourceLang, "response items wrong")
+	require.Equal(t, "de", glossary.TargetLang, "response items wrong")
+}
+
+func TestClient_GetGlossary_not_found(t *testing.T) {
+	t.Parallel()
+
+	cli, teardown := spawnTestServer(
+		t,
+		"testdata/Glossary/not-found-header",
+		"testdata/Glossary/not-found-body",
+		http.MethodGet,
+		"/v2/glossaries/"+dummyGlossaryID,
+		"",
+	)
+	defer teardown()
+
+	glossary, err := cli.GetGlossary(context.TODO(), dummyGlossaryID)
+
+	require.Error(t, err, "missing glossary should return an error")
+	require.Nil(t, glossary, "returned value should be nil on error")
+	assert.Contains(t, err.Error(), "Glossary not found",
+		"it should contain the returned message")
+}
+
+func TestClient_GetGlossaryEntries(t *testing.T) {
+	t.Parallel()
+
+	cli, teardown := spawnTestServer(
+		t,
+		"testdata/Glossary/entries-success-header",
+		"testdata/Glossary/entries-success-body",
+		http.MethodGet,
+		"/v2/glossaries/"+dummyGlossaryID+"/entries",
+		"",
+	)
+	defer teardown()
+
+	entries, err := cli.GetGlossaryEntries(context.TODO(), dummyGlossaryID)
+
+	require.NoError(t, err, "response error should be nil on success")
+	require.Equal(t, GlossaryEntries{
+		{Source: "Hello", Target: "Hallo"},
+		{Source: "World", Target: "Welt"},
+	}, entries, "response items wrong")
+}
+
+func TestClient_GetGlossaryEntries_not_found(t *testing.T) {
+	t.Parallel()
+
+	cli, teardown := spawnTestServer(
+		t,
+		"testdata/Glossary/not-found-header",
+		"testdata/Glossary/not-found-body",
+		http.MethodGet,
+		"/v2/glossaries/"+dummyGlossaryID+"/entries",
+		"",
+	)
+	defer teardown()
+
+	entries, err := cli.GetGlossaryEntries(context.TODO(), dummyGlossaryID)
+
+	require.Error(t, err, "missing glossary should return an error")
+	require.Nil(t, entries, "returned value should be nil on error")
+	assert.Contains(t, err.Error(), "failed to read glossary entries",
+		"it should contain the error reason")
+	assert.Contains(t, err.Error(), "Not found.",
+		"it should contain the underlying error reason")
+}
+
+func TestClient_DeleteGlossary(t *testing.T) {
+	t.Parallel()
+
+	cli, teardown := spawnTestServer(
+		t,
+		"testdata/Glossary/delete-success-header",
+		"testdata/Glossary/delete-success-body",
+		http.MethodDelete,
+		"/v2/glossaries/"+dummyGlossaryID,
+		"",
+	)
+	defer teardown()
+
+	require.NoError(t, cli.DeleteGlossary(context.TODO(), dummyGlossaryID),
+		"response error should be nil on success")
+}
+
+func TestClient_GetGlossaryLanguagePairs(t *testing.T) {
+	t.Parallel()
+
+	cli, teardown := spawnTestServer(
+		t,
+		"testdata/Glossary/language-pairs-success-header",
+		"testdata/Glossary/language-pairs-success-body",
+		http.MethodGet,
+		"/v2/glossary-language-pairs",
+		"",
+	)
+	defer teardown()
+
+	pairs, err := cli.GetGlossaryLanguagePairs(context.TODO())
+
+	require.NoError(t, err, "response error should be nil on success")
+	require.Equal(t, []GlossaryLanguagePair{
+		{SourceLang: "de", TargetLang: "en"},
+		{SourceLang: "en", TargetLang: "de"},
+	}, pairs, "response items wrong")
+}
+
+func TestClient_glossary_bad_id(t *testing.T) {
+	t.Parallel()
+
+	cli, err := New(APIFree, nil)
+	require.NoError(t, err, "failed to create client")
+
+	for _, glossaryID := range []string{"", "..", "foo/bar"} {
+		_, err := cli.GetGlossary(context.TODO(), glossaryID)
+		require.Error(t, err, "invalid ID %q should return an error", glossaryID)
+
+		_, err = cli.GetGlossaryEntries(context.TODO(), glossaryID)
+		require.Error(t, err, "invalid ID %q should return an error", glossaryID)
+
+		err = cli.DeleteGlossary(context.TODO(), glossaryID)
+		require.Error(t, err, "invalid ID %q should return an error", glossaryID)
+	}
+}
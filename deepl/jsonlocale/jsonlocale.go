@@ -0,0 +1,319 @@
+/*
+Package jsonlocale translates the nested JSON locale files of i18n libraries.
+Such as "en.json" to "ja.json".
+
+	{
+	  "greeting": "Hello, {name}!",
+	  "cart": {
+	    "items": "{{count}} items in your cart"
+	  }
+	}
+
+Only the string leaves are translated. The key order, the nesting and the other
+values are kept as is. The interpolation placeholders, such as "{name}" and
+"{{count}}", are protected from the translation.
+*/
+package jsonlocale
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/KEINOS/go-deepl/deepl"
+)
+
+// Kind is the kind of Value.
+type Kind int
+
+const (
+	// KindLiteral is the numbers, booleans and null. They are kept as is.
+	KindLiteral Kind = iota
+	// KindString is the string. Which is the target of the translation.
+	KindString
+	// KindObject is the object. The members are kept in order.
+	KindObject
+	// KindArray is the array.
+	KindArray
+)
+
+// ----------------------------------------------------------------------------
+//  Type: Value
+// ----------------------------------------------------------------------------
+
+// Value is a JSON value that keeps the order of the object members.
+type Value struct {
+	// Literal is the raw JSON of KindLiteral. Such as "1.50", "true" and "null".
+	Literal string
+	// String is the value of KindString.
+	String string
+	// Members are the members of KindObject in order.
+	Members []Member
+	// Elements are the elements of KindArray.
+	Elements []*Value
+	// Kind is the kind of the value.
+	Kind Kind
+}
+
+// Member is a member of the object.
+type Member struct {
+	Value *Value
+	Key   string
+}
+
+// Parse parses the JSON data to a Value.
+func Parse(data []byte) (*Value, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	value, err := parseValue(decoder)
+	if err != nil {
+		return nil, deepl.WrapIfErr(err, "failed to parse JSON locale")
+	}
+
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return nil, deepl.NewErr("failed to parse JSON locale: unexpected data after the top-level value")
+	}
+
+	return value, nil
+}
+
+// Get returns the member value of the key in the object. It returns nil if the
+// value is not an object or the key is not found.
+func (v *Value) Get(key string) *Value {
+	if v == nil || v.Kind != KindObject {
+		return nil
+	}
+
+	for _, member := range v.Members {
+		if member.Key == key {
+			return member.Value
+		}
+	}
+
+	return nil
+}
+
+// Encode returns the JSON of the value indented with the indent. The HTML
+// characters, such as "<" and "&", are not escaped. Which are common in the
+// locale files.
+func (v *Value) Encode(indent string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	if err := v.encode(&buf, indent, 0); err != nil {
+		return nil, err
+	}
+
+	buf.WriteByte('\n')
+
+	return buf.Bytes(), nil
+}
+
+// Clone returns a deep copy of the value.
+func (v *Value) Clone() *Value {
+	if v == nil {
+		return nil
+	}
+
+	clone := &Value{
+		Kind:    v.Kind,
+		Literal: v.Literal,
+		String:  v.String,
+	}
+
+	if v.Members != nil {
+		clone.Members = make([]Member, len(v.Members))
+		for index, member := range v.Members {
+			clone.Members[index] = Member{Key: member.Key, Value: member.Value.Clone()}
+		}
+	}
+
+	if v.Elements != nil {
+		clone.Elements = make([]*Value, len(v.Elements))
+		for index, element := range v.Elements {
+			clone.Elements[index] = element.Clone()
+		}
+	}
+
+	return clone
+}
+
+// encode writes the JSON of the value to the buf at the depth of the indent.
+func (v *Value) encode(buf *bytes.Buffer, indent string, depth int) error {
+	switch v.Kind {
+	case KindLiteral:
+		buf.WriteString(v.Literal)
+	case KindString:
+		return encodeString(buf, v.String)
+	case KindObject:
+		if len(v.Members) == 0 {
+			buf.WriteString("{}")
+
+			return nil
+		}
+
+		buf.WriteByte('{')
+
+		for index, member := range v.Members {
+			if index > 0 {
+				buf.WriteByte(',')
+			}
+
+			writeNewline(buf, indent, depth+1)
+
+			if err := encodeString(buf, member.Key); err != nil {
+				return err
+			}
+
+			buf.WriteByte(':')
+
+			if indent != "" {
+				buf.WriteByte(' ')
+			}
+
+			if err := member.Value.encode(buf, indent, depth+1); err != nil {
+				return err
+			}
+		}
+
+		writeNewline(buf, indent, depth)
+		buf.WriteByte('}')
+	case KindArray:
+		if len(v.Elements) == 0 {
+			buf.WriteString("[]")
+
+			return nil
+		}
+
+		buf.WriteByte('[')
+
+		for index, element := range v.Elements {
+			if index > 0 {
+				buf.WriteByte(',')
+			}
+
+			writeNewline(buf, indent, depth+1)
+
+			if err := element.encode(buf, indent, depth+1); err != nil {
+				return err
+			}
+		}
+
+		writeNewline(buf, indent, depth)
+		buf.WriteByte(']')
+	}
+
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+//  Private Functions
+// ----------------------------------------------------------------------------
+
+// encodeString writes the JSON string of the text to the buf.
+func encodeString(buf *bytes.Buffer, text string) error {
+	var tmp bytes.Buffer
+
+	encoder := json.NewEncoder(&tmp)
+	encoder.SetEscapeHTML(false)
+
+	if err := encoder.Encode(text); err != nil {
+		return deepl.WrapIfErr(err, "failed to encode string")
+	}
+
+	buf.Write(bytes.TrimRight(tmp.Bytes(), "\n"))
+
+	return nil
+}
+
+// parseValue parses the next value of the decoder.
+func parseValue(decoder *json.Decoder) (*Value, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok := token.(type) {
+	case json.Delim:
+		switch tok {
+		case '{':
+			return parseObject(decoder)
+		case '[':
+			return parseArray(decoder)
+		}
+
+		return nil, deepl.NewErr("unexpected delimiter: %v", tok)
+	case string:
+		return &Value{Kind: KindString, String: tok}, nil
+	case json.Number:
+		return &Value{Kind: KindLiteral, Literal: tok.String()}, nil
+	case bool:
+		literal := "false"
+		if tok {
+			literal = "true"
+		}
+
+		return &Value{Kind: KindLiteral, Literal: literal}, nil
+	}
+
+	return &Value{Kind: KindLiteral, Literal: "null"}, nil
+}
+
+// parseObject parses the members of the object until the closing delimiter.
+func parseObject(decoder *json.Decoder) (*Value, error) {
+	value := &Value{Kind: KindObject, Members: []Member{}}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		key, ok := token.(string)
+		if !ok {
+			return nil, deepl.NewErr("unexpected object key: %v", token)
+		}
+
+		member, err := parseValue(decoder)
+		if err != nil {
+			return nil, err
+		}
+
+		value.Members = append(value.Members, Member{Key: key, Value: member})
+	}
+
+	_, err := decoder.Token() // closing '}'
+
+	return value, err
+}
+
+// parseArray parses the elements of the array until the closing delimiter.
+func parseArray(decoder *json.Decoder) (*Value, error) {
+	value := &Value{Kind: KindArray, Elements: []*Value{}}
+
+	for decoder.More() {
+		element, err := parseValue(decoder)
+		if err != nil {
+			return nil, err
+		}
+
+		value.Elements = append(value.Elements, element)
+	}
+
+	_, err := decoder.Token() // closing ']'
+
+	return value, err
+}
+
+// writeNewline writes a newline and the indent of the depth. Nothing is written
+// if the indent is empty to make the JSON compact.
+func writeNewline(buf *bytes.Buffer, indent string, depth int) {
+	if indent == "" {
+		return
+	}
+
+	buf.WriteByte('\n')
+	buf.WriteString(strings.Repeat(indent, depth))
+}
@@ -0,0 +1,67 @@
+package jsonlocale_test
+
+import (
+	"testing"
+
+	"github.com/KEINOS/go-deepl/deepl/jsonlocale"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_round_trip(t *testing.T) {
+	t.Parallel()
+
+	// The keys are not sorted and the HTML characters are not escaped
+	input := `{
+  "zebra": "Z & <b>bold</b>",
+  "apple": {
+    "nested": "text",
+    "empty": {},
+    "list": [
+      "one",
+      2.50,
+      true,
+      null
+    ]
+  },
+  "emptyList": []
+}
+`
+
+	value, err := jsonlocale.Parse([]byte(input))
+	require.NoError(t, err)
+
+	output, err := value.Encode("  ")
+	require.NoError(t, err)
+	require.Equal(t, input, string(output))
+
+	compact, err := value.Encode("")
+	require.NoError(t, err)
+	require.Equal(t,
+		`{"zebra":"Z & <b>bold</b>","apple":{"nested":"text","empty":{},"list":["one",2.50,true,null]},"emptyList":[]}`+"\n",
+		string(compact))
+}
+
+func TestParse_bad_json(t *testing.T) {
+	t.Parallel()
+
+	for _, input := range []string{
+		``,
+		`{"key": }`,
+		`{"key": "value"`,
+		`{"key": "value"} {}`,
+	} {
+		_, err := jsonlocale.Parse([]byte(input))
+		require.Error(t, err, "input: %s", input)
+	}
+}
+
+func TestValue_Get(t *testing.T) {
+	t.Parallel()
+
+	value, err := jsonlocale.Parse([]byte(`{"a": {"b": "c"}, "list": ["d"]}`))
+	require.NoError(t, err)
+
+	require.Equal(t, "c", value.Get("a").Get("b").String)
+	require.Nil(t, value.Get("unknown").Get("b"), "nil value should return nil")
+	require.Nil(t, value.Get("list").Get("0"), "array should return nil")
+}
@@ -0,0 +1,258 @@
+package jsonlocale
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/KEINOS/go-deepl/deepl"
+	"github.com/KEINOS/go-deepl/deepl/placeholder"
+)
+
+// DefaultIndent is the indent of the target files.
+const DefaultIndent = "  "
+
+// ----------------------------------------------------------------------------
+//  Type: Options
+// ----------------------------------------------------------------------------
+
+// Options are the options of Translate and TranslateFile.
+type Options struct {
+	// TranslateOptions are the options of the translation. TargetLang is
+	// required. TagHandling is overridden since the placeholders are sent as the
+	// XML elements.
+	TranslateOptions deepl.TranslateOptions
+	// Indent is the indent of the target file. Defaults to DefaultIndent.
+	Indent string
+	// Placeholders are the patterns of the placeholders to keep untouched.
+	// Defaults to placeholder.Braces. Such as "{name}" and "{{count}}".
+	Placeholders []*regexp.Regexp
+	// Incremental keeps the values of the keys already in the target and only
+	// translates the missing ones. Otherwise, all the strings are translated.
+	Incremental bool
+}
+
+// Result is the result of the translation.
+type Result struct {
+	// Translated is the number of the strings translated.
+	Translated int
+	// Kept is the number of the values kept from the target in the incremental
+	// mode.
+	Kept int
+}
+
+// ----------------------------------------------------------------------------
+//  Functions
+// ----------------------------------------------------------------------------
+
+// Translate returns the translated copy of the source with the translator. In
+// the incremental mode, the values of the target are kept and the keys only in
+// the target are appended after the keys of the source. The target may be nil.
+//
+// All the strings are requested in a single call of the translator. The Client
+// splits them into the requests within the limits of the API.
+func Translate(
+	ctx context.Context,
+	translator deepl.Translator,
+	source *Value,
+	target *Value,
+	opts Options,
+) (*Value, *Result, error) {
+	if source == nil {
+		return nil, nil, deepl.NewErr("source locale is nil")
+	}
+
+	if !opts.Incremental {
+		target = nil
+	}
+
+	result := new(Result)
+
+	var pending []*Value
+
+	merged := merge(source, target, &pending, result)
+
+	if len(pending) == 0 {
+		return merged, result, nil
+	}
+
+	patterns := opts.Placeholders
+	if patterns == nil {
+		patterns = []*regexp.Regexp{placeholder.Braces}
+	}
+
+	masker := placeholder.NewMasker(patterns...)
+	texts := make([]string, len(pending))
+	tokens := make([][]string, len(pending))
+
+	for index, value := range pending {
+		texts[index], tokens[index] = masker.Mask(value.String)
+	}
+
+	translated, err := placeholder.Translate(ctx, translator, texts, tokens, opts.TranslateOptions)
+	if err != nil {
+		return nil, nil, deepl.WrapIfErr(err, "failed to translate locale")
+	}
+
+	for index, value := range pending {
+		value.String = translated[index]
+	}
+
+	result.Translated = len(pending)
+
+	return merged, result, nil
+}
+
+// TranslateFile translates the source file and writes it to the target file. In
+// the incremental mode, the existing target file is used as the target of
+// Translate. The file is written atomically by renaming a temporary file.
+func TranslateFile(
+	ctx context.Context,
+	translator deepl.Translator,
+	sourcePath string,
+	targetPath string,
+	opts Options,
+) (*Result, error) {
+	source, err := readFile(sourcePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var target *Value
+
+	if opts.Incremental {
+		target, err = readFile(targetPath)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	translated, result, err := Translate(ctx, translator, source, target, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	indent := opts.Indent
+	if indent == "" {
+		indent = DefaultIndent
+	}
+
+	data, err := translated.Encode(indent)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeFile(targetPath, data); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ----------------------------------------------------------------------------
+//  Private Functions
+// ----------------------------------------------------------------------------
+
+// merge returns the copy of the source with the values of the target. The
+// strings to translate are appended to the pending.
+func merge(source *Value, target *Value, pending *[]*Value, result *Result) *Value {
+	if target != nil && (source.Kind != target.Kind || source.Kind == KindString || source.Kind == KindLiteral) {
+		result.Kept++
+
+		return target.Clone()
+	}
+
+	switch source.Kind {
+	case KindString:
+		merged := source.Clone()
+		if strings.TrimSpace(merged.String) != "" {
+			*pending = append(*pending, merged)
+		}
+
+		return merged
+	case KindObject:
+		merged := &Value{Kind: KindObject, Members: make([]Member, 0, len(source.Members))}
+		seen := make(map[string]bool, len(source.Members))
+
+		for _, member := range source.Members {
+			seen[member.Key] = true
+			merged.Members = append(merged.Members, Member{
+				Key:   member.Key,
+				Value: merge(member.Value, target.Get(member.Key), pending, result),
+			})
+		}
+
+		if target != nil {
+			for _, member := range target.Members {
+				if !seen[member.Key] {
+					merged.Members = append(merged.Members, Member{Key: member.Key, Value: member.Value.Clone()})
+				}
+			}
+		}
+
+		return merged
+	case KindArray:
+		merged := &Value{Kind: KindArray, Elements: make([]*Value, 0, len(source.Elements))}
+
+		for index, element := range source.Elements {
+			var targetElement *Value
+			if target != nil && index < len(target.Elements) {
+				targetElement = target.Elements[index]
+			}
+
+			merged.Elements = append(merged.Elements, merge(element, targetElement, pending, result))
+		}
+
+		if target != nil && len(target.Elements) > len(source.Elements) {
+			for _, element := range target.Elements[len(source.Elements):] {
+				merged.Elements = append(merged.Elements, element.Clone())
+			}
+		}
+
+		return merged
+	}
+
+	return source.Clone()
+}
+
+// readFile reads and parses the JSON locale file.
+func readFile(path string) (*Value, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, deepl.WrapIfErr(err, "failed to read locale file")
+	}
+
+	value, err := Parse(data)
+
+	return value, deepl.WrapIfErr(err, "invalid locale file: %q", path)
+}
+
+// writeFile writes the data to the path atomically.
+func writeFile(path string, data []byte) error {
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return deepl.WrapIfErr(err, "failed to create locale file")
+	}
+
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+
+		return deepl.WrapIfErr(err, "failed to write locale file")
+	}
+
+	if err := tmpFile.Close(); err != nil {
+		return deepl.WrapIfErr(err, "failed to close locale file")
+	}
+
+	// The temporary file is created with 0600
+	if err := os.Chmod(tmpFile.Name(), 0o644); err != nil {
+		return deepl.WrapIfErr(err, "failed to change mode of locale file")
+	}
+
+	return deepl.WrapIfErr(os.Rename(tmpFile.Name(), path), "failed to rename locale file")
+}
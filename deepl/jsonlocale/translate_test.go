@@ -0,0 +1,177 @@
+package jsonlocale_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/KEINOS/go-deepl/deepl"
+	"github.com/KEINOS/go-deepl/deepl/deepltest"
+	"github.com/KEINOS/go-deepl/deepl/jsonlocale"
+	"github.com/KEINOS/go-deepl/deepl/placeholder"
+	"github.com/stretchr/testify/require"
+)
+
+const sourceLocale = `{
+  "greeting": "Hello, {name}!",
+  "cart": {
+    "items": "{{count}} items & more",
+    "empty": ""
+  },
+  "steps": [
+    "First",
+    "Second"
+  ],
+  "version": 2
+}
+`
+
+func TestTranslateFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	pathSource := filepath.Join(dir, "en.json")
+	pathTarget := filepath.Join(dir, "ja.json")
+
+	require.NoError(t, os.WriteFile(pathSource, []byte(sourceLocale), 0o600))
+
+	fake := deepltest.NewFakeTranslator()
+
+	result, err := jsonlocale.TranslateFile(context.Background(), fake, pathSource, pathTarget, jsonlocale.Options{
+		TranslateOptions: deepl.TranslateOptions{SourceLang: "EN", TargetLang: "JA"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 4, result.Translated)
+
+	output, err := os.ReadFile(pathTarget)
+	require.NoError(t, err)
+	require.Equal(t, `{
+  "greeting": "[JA] Hello, {name}!",
+  "cart": {
+    "items": "[JA] {{count}} items & more",
+    "empty": ""
+  },
+  "steps": [
+    "[JA] First",
+    "[JA] Second"
+  ],
+  "version": 2
+}
+`, string(output))
+
+	calls := fake.Calls()
+	require.Len(t, calls, 1, "all strings should be translated in a single call")
+	require.Equal(t, deepl.TagHandlingXML, calls[0].Options.TagHandling)
+	require.Equal(t, `<x id="0"/> items &amp; more`, calls[0].Texts[1],
+		"placeholders should be masked and the text should be escaped")
+}
+
+func TestTranslateFile_incremental(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	pathSource := filepath.Join(dir, "en.json")
+	pathTarget := filepath.Join(dir, "de.json")
+
+	require.NoError(t, os.WriteFile(pathSource, []byte(sourceLocale), 0o600))
+	require.NoError(t, os.WriteFile(pathTarget, []byte(`{
+  "obsolete": "Alt",
+  "greeting": "Hallo, {name}!",
+  "steps": ["Erste"]
+}`), 0o600))
+
+	fake := deepltest.NewFakeTranslator()
+
+	result, err := jsonlocale.TranslateFile(context.Background(), fake, pathSource, pathTarget, jsonlocale.Options{
+		TranslateOptions: deepl.TranslateOptions{TargetLang: "DE"},
+		Indent:           "\t",
+		Incremental:      true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, result.Translated)
+	require.Equal(t, 2, result.Kept)
+
+	output, err := os.ReadFile(pathTarget)
+	require.NoError(t, err)
+	require.Equal(t, strings.Join([]string{
+		`{`,
+		`	"greeting": "Hallo, {name}!",`,
+		`	"cart": {`,
+		`		"items": "[DE] {{count}} items & more",`,
+		`		"empty": ""`,
+		`	},`,
+		`	"steps": [`,
+		`		"Erste",`,
+		`		"[DE] Second"`,
+		`	],`,
+		`	"version": 2,`,
+		`	"obsolete": "Alt"`,
+		`}`,
+		``,
+	}, "\n"), string(output))
+
+	// Nothing to translate on the second run
+	result, err = jsonlocale.TranslateFile(context.Background(), fake, pathSource, pathTarget, jsonlocale.Options{
+		TranslateOptions: deepl.TranslateOptions{TargetLang: "DE"},
+		Incremental:      true,
+	})
+	require.NoError(t, err)
+	require.Zero(t, result.Translated)
+	require.Len(t, fake.Calls(), 1, "translator should not be called if nothing to translate")
+}
+
+func TestTranslate_lost_placeholder(t *testing.T) {
+	t.Parallel()
+
+	source, err := jsonlocale.Parse([]byte(`{"greeting": "Hello, {name}!"}`))
+	require.NoError(t, err)
+
+	fake := deepltest.NewFakeTranslator()
+	fake.TranslateFunc = func(string, *deepl.TranslateOptions) string {
+		return "Hallo!"
+	}
+
+	_, _, err = jsonlocale.Translate(context.Background(), fake, source, nil, jsonlocale.Options{
+		TranslateOptions: deepl.TranslateOptions{TargetLang: "DE"},
+	})
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `placeholder "{name}" is lost`)
+}
+
+func TestTranslate_placeholders(t *testing.T) {
+	t.Parallel()
+
+	source, err := jsonlocale.Parse([]byte(`{"files": "%d files in {dir}"}`))
+	require.NoError(t, err)
+
+	fake := deepltest.NewFakeTranslator()
+
+	translated, _, err := jsonlocale.Translate(context.Background(), fake, source, nil, jsonlocale.Options{
+		TranslateOptions: deepl.TranslateOptions{TargetLang: "DE"},
+		Placeholders:     []*regexp.Regexp{placeholder.Printf},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{`<x id="0"/> files in {dir}`}, fake.Calls()[0].Texts,
+		"only the given placeholders should be masked")
+
+	output, err := translated.Encode(jsonlocale.DefaultIndent)
+	require.NoError(t, err)
+	require.Equal(t, "{\n  \"files\": \"[DE] %d files in {dir}\"\n}\n", string(output))
+}
+
+func TestTranslate_errors(t *testing.T) {
+	t.Parallel()
+
+	fake := deepltest.NewFakeTranslator()
+
+	_, _, err := jsonlocale.Translate(context.Background(), fake, nil, nil, jsonlocale.Options{})
+	require.Error(t, err, "nil source should be an error")
+
+	_, err = jsonlocale.TranslateFile(context.Background(), fake, filepath.Join(t.TempDir(), "missing.json"),
+		filepath.Join(t.TempDir(), "de.json"), jsonlocale.Options{})
+	require.Error(t, err, "missing source file should be an error")
+}
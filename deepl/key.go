@@ -0,0 +1,84 @@
+package deepl
+
+import (
+	"context"
+	"os"
+	"strings"
+)
+
+// ----------------------------------------------------------------------------
+//  Interface: KeyProvider
+// ----------------------------------------------------------------------------
+
+// KeyProvider is the interface that provides the DeepL API key to the client.
+//
+// APIKey is called on every request, so the implementation can rotate the key.
+// It must be safe for concurrent use.
+type KeyProvider interface {
+	APIKey(ctx context.Context) (string, error)
+}
+
+// KeyProviderFunc is an adapter to use a function as a KeyProvider. Such as a
+// callback to fetch the key from a secret manager.
+type KeyProviderFunc func(ctx context.Context) (string, error)
+
+// APIKey calls f(ctx).
+func (f KeyProviderFunc) APIKey(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// ----------------------------------------------------------------------------
+//  Constructors
+// ----------------------------------------------------------------------------
+
+// StaticKey returns a KeyProvider that always provides the given API key.
+func StaticKey(apiKey string) KeyProvider {
+	return KeyProviderFunc(func(ctx context.Context) (string, error) {
+		if apiKey == "" {
+			return "", NewErr("the given API key is empty")
+		}
+
+		return apiKey, nil
+	})
+}
+
+// EnvKey returns a KeyProvider that provides the API key from the given
+// environment variable. The variable is read on every call.
+func EnvKey(nameEnv string) KeyProvider {
+	return KeyProviderFunc(func(ctx context.Context) (string, error) {
+		return getAPIKeyFromEnv(nameEnv)
+	})
+}
+
+// FileKey returns a KeyProvider that provides the API key from the given file.
+// The file is read on every call, so the key can be rotated by updating the
+// file. Leading and trailing white spaces are trimmed.
+func FileKey(pathFile string) KeyProvider {
+	return KeyProviderFunc(func(ctx context.Context) (string, error) {
+		keyBytes, err := os.ReadFile(pathFile)
+		if err != nil {
+			return "", WrapIfErr(err, "failed to read API key file")
+		}
+
+		apiKey := strings.TrimSpace(string(keyBytes))
+		if apiKey == "" {
+			return "", NewErr("API key file is empty: %s", pathFile)
+		}
+
+		return apiKey, nil
+	})
+}
+
+// ----------------------------------------------------------------------------
+//  Private Methods
+// ----------------------------------------------------------------------------
+
+// apiKey returns the API key from the KeyProvider of the client. If not set, it
+// falls back to the environment variable named NameEnvKeyAPI.
+func (c *Client) apiKey(ctx context.Context) (string, error) {
+	if c.KeyProvider == nil {
+		return getAPIKey()
+	}
+
+	return c.KeyProvider.APIKey(ctx)
+}
@@ -0,0 +1,131 @@
+package deepl
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  KeyProvider implementations
+// ----------------------------------------------------------------------------
+
+func TestStaticKey(t *testing.T) {
+	t.Parallel()
+
+	apiKey, err := StaticKey(dummyAuthKey).APIKey(context.TODO())
+
+	require.NoError(t, err)
+	require.Equal(t, dummyAuthKey, apiKey)
+
+	apiKey, err = StaticKey("").APIKey(context.TODO())
+
+	require.Error(t, err, "empty key should return an error")
+	require.Empty(t, apiKey, "returned value should be empty on error")
+	assert.Contains(t, err.Error(), "the given API key is empty",
+		"it should contain the error reason")
+}
+
+//nolint:paralleltest // t.Setenv does not allow parallel tests
+func TestEnvKey(t *testing.T) {
+	nameEnv := "DEEPL_API_KEY_" + t.Name()
+
+	apiKey, err := EnvKey(nameEnv).APIKey(context.TODO())
+
+	require.Error(t, err, "undefined env key should return error")
+	require.Empty(t, apiKey, "returned value should be empty on error")
+	assert.Contains(t, err.Error(), "env variable for API key not set: "+nameEnv,
+		"it should contain the error reason")
+
+	t.Setenv(nameEnv, dummyAuthKey)
+
+	apiKey, err = EnvKey(nameEnv).APIKey(context.TODO())
+
+	require.NoError(t, err)
+	require.Equal(t, dummyAuthKey, apiKey)
+}
+
+func TestFileKey(t *testing.T) {
+	t.Parallel()
+
+	pathFile := filepath.Join(t.TempDir(), "deepl.key")
+	provider := FileKey(pathFile)
+
+	// Missing file
+	apiKey, err := provider.APIKey(context.TODO())
+
+	require.Error(t, err, "missing file should return an error")
+	require.Empty(t, apiKey, "returned value should be empty on error")
+	assert.Contains(t, err.Error(), "failed to read API key file",
+		"it should contain the error reason")
+
+	// Empty file
+	require.NoError(t, os.WriteFile(pathFile, []byte(" \n"), 0o600))
+
+	apiKey, err = provider.APIKey(context.TODO())
+
+	require.Error(t, err, "empty file should return an error")
+	require.Empty(t, apiKey, "returned value should be empty on error")
+	assert.Contains(t, err.Error(), "API key file is empty",
+		"it should contain the error reason")
+
+	// Rotated key
+	for _, expect := range []string{"first-key:fx", "second-key:fx"} {
+		require.NoError(t, os.WriteFile(pathFile, []byte(expect+"\n"), 0o600))
+
+		apiKey, err = provider.APIKey(context.TODO())
+
+		require.NoError(t, err)
+		require.Equal(t, expect, apiKey, "the file should be read on every call")
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  Client.KeyProvider
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // do not parallelize due to global variable change
+func TestClient_KeyProvider(t *testing.T) {
+	oldNameEnvKeyAPI := NameEnvKeyAPI
+	defer func() {
+		NameEnvKeyAPI = oldNameEnvKeyAPI
+	}()
+
+	// Make sure the env variable is not used
+	NameEnvKeyAPI = "ENV_VAL_WITH_NO_NAME" + t.Name()
+
+	cli, teardown := spawnTestServer(
+		t,
+		"testdata/GetAccountStatus/success-header",
+		"testdata/GetAccountStatus/success-body",
+		http.MethodPost,
+		"/v2/usage",
+		"",
+	)
+	defer teardown()
+
+	cli.KeyProvider = StaticKey(dummyAuthKey)
+
+	status, err := cli.GetAccountStatus(context.TODO())
+
+	require.NoError(t, err, "the key from the provider should be used")
+	require.Equal(t, int64(30315), status.CharacterCount, "response items wrong")
+
+	cli.KeyProvider = KeyProviderFunc(func(ctx context.Context) (string, error) {
+		return "", NewErr("secret manager is down")
+	})
+
+	status, err = cli.GetAccountStatus(context.TODO())
+
+	require.Error(t, err, "provider error should be returned")
+	require.Nil(t, status, "returned value should be nil on error")
+	assert.Contains(t, err.Error(), "failed to get API key",
+		"it should contain the error reason")
+	assert.Contains(t, err.Error(), "secret manager is down",
+		"it should contain the underlying error reason")
+}
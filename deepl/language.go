@@ -0,0 +1,184 @@
+package deepl
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// ----------------------------------------------------------------------------
+//  Types
+// ----------------------------------------------------------------------------
+
+// LanguageType is an enum type to set whether to list the source or the target
+// languages.
+type LanguageType string
+
+const (
+	// LanguageTypeSource lists the languages that can be used as the source.
+	LanguageTypeSource LanguageType = "source"
+	// LanguageTypeTarget lists the languages that can be used as the target.
+	// It contains the regional variants such as "EN-GB" and "PT-BR".
+	LanguageTypeTarget LanguageType = "target"
+)
+
+// Language holds the information of a language supported by the API.
+type Language struct {
+	// Code is the language code such as "EN", "JA" and "PT-BR".
+	Code string `json:"language"`
+	// Name is the name of the language in English.
+	Name string `json:"name"`
+	// SupportsFormality is true if the language supports the formality option.
+	// Only available for the target languages.
+	SupportsFormality bool `json:"supports_formality"`
+}
+
+// languageCache holds the supported languages fetched from the API for the
+// client-side validation.
+type languageCache struct {
+	byType map[LanguageType][]Language
+	mutex  sync.Mutex
+}
+
+// get returns the cached languages of the type and true if cached.
+func (l *languageCache) get(langType LanguageType) ([]Language, bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	languages, ok := l.byType[langType]
+
+	return languages, ok
+}
+
+// set caches the languages of the type.
+func (l *languageCache) set(langType LanguageType, languages []Language) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.byType == nil {
+		l.byType = map[LanguageType][]Language{}
+	}
+
+	l.byType[langType] = languages
+}
+
+// ----------------------------------------------------------------------------
+//  Methods of Client
+// ----------------------------------------------------------------------------
+
+// GetLanguages returns the list of languages supported by the API for the
+// given type.
+func (c *Client) GetLanguages(ctx context.Context, langType LanguageType) ([]Language, error) {
+	if langType != LanguageTypeSource && langType != LanguageTypeTarget {
+		return nil, NewErr("unsupported language type: %q", langType)
+	}
+
+	urlVal := url.Values{}
+	urlVal.Set("type", string(langType))
+
+	var languages []Language
+
+	if err := c.requestJSON(ctx, http.MethodGet, "v2/languages", urlVal, &languages); err != nil {
+		return nil, WrapIfErr(err, "failed to parse response to Language list")
+	}
+
+	return languages, nil
+}
+
+// ----------------------------------------------------------------------------
+//  Private Methods
+// ----------------------------------------------------------------------------
+
+// cachedLanguages returns the supported languages of the given type. The list is
+// fetched once per client and cached.
+//
+// The cache is not locked while fetching. So a slow fetch does not block the
+// requests with the cached list or the other type. The concurrent requests
+// before the first fetch is done may fetch the list on their own.
+func (c *Client) cachedLanguages(ctx context.Context, langType LanguageType) ([]Language, error) {
+	if languages, ok := c.langCache.get(langType); ok {
+		return languages, nil
+	}
+
+	languages, err := c.GetLanguages(ctx, langType)
+	if err != nil {
+		return nil, err
+	}
+
+	c.langCache.set(langType, languages)
+
+	return languages, nil
+}
+
+// validateLanguages checks the source and target language codes against the
+// languages supported by the API if Client.ValidateLanguages is true. The empty
+// source language is allowed for the auto-detection.
+func (c *Client) validateLanguages(
+	ctx context.Context,
+	sourceLang string,
+	targetLang string,
+	formality Formality,
+) error {
+	if !c.ValidateLanguages {
+		return nil
+	}
+
+	if sourceLang != "" {
+		languages, err := c.cachedLanguages(ctx, LanguageTypeSource)
+		if err != nil {
+			return WrapIfErr(err, "failed to get the source languages")
+		}
+
+		if _, ok := findLanguage(languages, sourceLang); !ok {
+			return NewErr("unsupported source language: %q", sourceLang)
+		}
+	}
+
+	languages, err := c.cachedLanguages(ctx, LanguageTypeTarget)
+	if err != nil {
+		return WrapIfErr(err, "failed to get the target languages")
+	}
+
+	language, ok := findLanguage(languages, targetLang)
+	if !ok {
+		return NewErr("unsupported target language: %q", targetLang)
+	}
+
+	if (formality == FormalityMore || formality == FormalityLess) && !language.SupportsFormality {
+		return NewErr("target language does not support formality: %q", targetLang)
+	}
+
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+//  Private Functions
+// ----------------------------------------------------------------------------
+
+// findLanguage searches the language code in the list case-insensitively.
+//
+// A code without the region matches the first regional variant in the list.
+// E.g. "EN" matches "EN-GB" and "PT" matches "PT-BR" in the target languages.
+func findLanguage(languages []Language, code string) (Language, bool) {
+	if code == "" {
+		return Language{}, false
+	}
+
+	for _, language := range languages {
+		if strings.EqualFold(language.Code, code) {
+			return language, true
+		}
+	}
+
+	if !strings.Contains(code, "-") {
+		for _, language := range languages {
+			if strings.HasPrefix(strings.ToUpper(language.Code), strings.ToUpper(code)+"-") {
+				return language, true
+			}
+		}
+	}
+
+	return Language{}, false
+}
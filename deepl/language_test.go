@@ -0,0 +1,215 @@
+package deepl
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  Client.GetLanguages
+// ----------------------------------------------------------------------------
+
+func TestClient_GetLanguages(t *testing.T) {
+	t.Parallel()
+
+	cli, teardown := spawnTestServer(
+		t,
+		"testdata/GetLanguages/target-success-header",
+		"testdata/GetLanguages/target-success-body",
+		http.MethodGet,
+		"/v2/languages",
+		"type=target",
+	)
+	defer teardown()
+
+	languages, err := cli.GetLanguages(context.TODO(), LanguageTypeTarget)
+
+	require.NoError(t, err, "response error should be nil on success")
+	require.Len(t, languages, 6, "response items wrong")
+	require.Equal(t, Language{
+		Code:              "EN-GB",
+		Name:              "English (British)",
+		SupportsFormality: false,
+	}, languages[1], "response items wrong")
+	require.True(t, languages[0].SupportsFormality, "response items wrong")
+}
+
+func TestClient_GetLanguages_bad_type(t *testing.T) {
+	t.Parallel()
+
+	cli, err := New(APIFree, nil)
+	require.NoError(t, err, "failed to create client")
+
+	languages, err := cli.GetLanguages(context.TODO(), "both")
+
+	require.Error(t, err, "unsupported type should return an error")
+	require.Nil(t, languages, "returned value should be nil on error")
+	assert.Contains(t, err.Error(), `unsupported language type: "both"`,
+		"it should contain the error reason")
+}
+
+// ----------------------------------------------------------------------------
+//  Client.ValidateLanguages
+// ----------------------------------------------------------------------------
+
+func TestClient_ValidateLanguages(t *testing.T) {
+	t.Parallel()
+
+	cli, teardown, countReq := spawnEchoServer(t)
+	defer teardown()
+
+	cli.ValidateLanguages = true
+
+	//nolint:varnamelen // tt is a test case by habit
+	for index, tt := range []struct {
+		opts             TranslateOptions
+		expectErrMessage string
+	}{
+		{TranslateOptions{SourceLang: "EN", TargetLang: "JA"}, ""},
+		{TranslateOptions{SourceLang: "", TargetLang: "en-gb"}, ""},
+		{TranslateOptions{SourceLang: "PT", TargetLang: "PT-BR"}, ""},
+		{TranslateOptions{SourceLang: "JA", TargetLang: "EN"}, ""},
+		{TranslateOptions{SourceLang: "EN", TargetLang: "DE", Formality: FormalityMore}, ""},
+		{TranslateOptions{SourceLang: "EN", TargetLang: "AA"}, `unsupported target language: "AA"`},
+		{TranslateOptions{SourceLang: "EN", TargetLang: ""}, `unsupported target language: ""`},
+		{TranslateOptions{SourceLang: "EN-GB", TargetLang: "JA"}, `unsupported source language: "EN-GB"`},
+		{
+			TranslateOptions{SourceLang: "JA", TargetLang: "EN-US", Formality: FormalityLess},
+			`target language does not support formality: "EN-US"`,
+		},
+	} {
+		opts := tt.opts
+
+		resp, err := cli.Translate(context.TODO(), []string{"hello"}, &opts)
+
+		if tt.expectErrMessage == "" {
+			require.NoError(t, err, "test #%d: supported languages should not return an error", index+1)
+			require.Len(t, resp.Translations, 1, "test #%d: response items wrong", index+1)
+
+			continue
+		}
+
+		require.Error(t, err, "test #%d: unsupported languages should return an error", index+1)
+		require.Nil(t, resp, "test #%d: returned value should be nil on error", index+1)
+		assert.Contains(t, err.Error(), "invalid language",
+			"test #%d: it should contain the error reason", index+1)
+		assert.Contains(t, err.Error(), tt.expectErrMessage,
+			"test #%d: it should contain the underlying error reason", index+1)
+	}
+
+	require.Equal(t, int32(5), *countReq,
+		"requests with unsupported languages should not be sent")
+}
+
+func TestClient_ValidateLanguages_fail_fetch(t *testing.T) {
+	t.Parallel()
+
+	cli, teardown := spawnTestServer(
+		t,
+		"testdata/TranslateText/wrong-apikey-header",
+		"testdata/TranslateText/wrong-apikey-body",
+		http.MethodGet,
+		"/v2/languages",
+		"type=target",
+	)
+	defer teardown()
+
+	cli.ValidateLanguages = true
+
+	resp, err := cli.TranslateSentence(context.TODO(), "hello", "", "JA")
+
+	require.Error(t, err, "failing to fetch the languages should return an error")
+	require.Nil(t, resp, "returned value should be nil on error")
+	assert.Contains(t, err.Error(), "failed to get the target languages",
+		"it should contain the error reason")
+}
+
+func TestClient_ValidateLanguages_slow_fetch(t *testing.T) {
+	t.Parallel()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(respWriter http.ResponseWriter, req *http.Request) {
+		langType := req.URL.Query().Get("type")
+
+		if langType == string(LanguageTypeSource) {
+			close(started)
+			<-release
+		}
+
+		bodyBytes, err := os.ReadFile("testdata/GetLanguages/" + langType + "-success-body")
+		require.NoError(t, err, "failed to read languages")
+
+		_, err = respWriter.Write(bodyBytes)
+		require.NoError(t, err, "failed to write response body")
+	}))
+	defer server.Close()
+
+	cli := newTestClient(t, server)
+
+	errSource := make(chan error, 1)
+
+	go func() {
+		_, err := cli.cachedLanguages(context.Background(), LanguageTypeSource)
+		errSource <- err
+	}()
+
+	<-started
+
+	// The slow fetch of the source languages should not block the target
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	languages, err := cli.cachedLanguages(ctx, LanguageTypeTarget)
+
+	require.NoError(t, err, "other type should not wait for the slow fetch")
+	require.NotEmpty(t, languages)
+
+	close(release)
+
+	require.NoError(t, <-errSource)
+
+	languages, ok := cli.langCache.get(LanguageTypeSource)
+
+	require.True(t, ok, "fetched languages should be cached")
+	require.NotEmpty(t, languages)
+}
+
+// ----------------------------------------------------------------------------
+//  findLanguage
+// ----------------------------------------------------------------------------
+
+func Test_findLanguage(t *testing.T) {
+	t.Parallel()
+
+	languages := []Language{
+		{Code: "DE", Name: "German", SupportsFormality: true},
+		{Code: "EN-GB", Name: "English (British)", SupportsFormality: false},
+		{Code: "EN-US", Name: "English (American)", SupportsFormality: false},
+	}
+
+	for _, tt := range []struct {
+		code       string
+		expectCode string
+	}{
+		{"de", "DE"},
+		{"EN-US", "EN-US"},
+		{"EN", "EN-GB"},
+		{"DE-AT", ""},
+		{"E", ""},
+		{"", ""},
+	} {
+		language, ok := findLanguage(languages, tt.code)
+
+		require.Equal(t, tt.expectCode != "", ok, "code: %q", tt.code)
+		require.Equal(t, tt.expectCode, language.Code, "code: %q", tt.code)
+	}
+}
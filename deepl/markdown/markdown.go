@@ -0,0 +1,758 @@
+/*
+Package markdown translates the prose of the Markdown documents with DeepL while
+keeping the formatting of the documents.
+
+	doc, err := markdown.ParseFile("README.md")
+
+	result, err := markdown.Translate(ctx, cli, doc, markdown.Options{
+		TranslateOptions:  deepl.TranslateOptions{TargetLang: "JA"},
+		FrontMatterFields: []string{"title", "description"},
+	})
+
+	err = doc.WriteFile("README.ja.md")
+
+The document is split into the segments of the prose. Such as the paragraphs,
+the headings, the list items, the table cells and the values of the YAML front
+matter. The rest of the document, such as the code blocks, the HTML blocks, the
+link reference definitions and the keys of the front matter, is written back as
+is.
+*/
+package markdown
+
+import (
+	"bytes"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/KEINOS/go-deepl/deepl"
+)
+
+// Kind is the kind of the segment.
+type Kind int
+
+const (
+	// KindParagraph is the paragraph. Including the paragraphs in the block
+	// quotes and the footnote definitions.
+	KindParagraph Kind = iota
+	// KindHeading is the ATX ("# Title") or setext heading.
+	KindHeading
+	// KindListItem is the first paragraph of the list item.
+	KindListItem
+	// KindTableCell is the cell of the GFM table.
+	KindTableCell
+	// KindFrontMatter is the scalar value of the top-level field of the YAML
+	// front matter.
+	KindFrontMatter
+)
+
+// String returns the name of the kind.
+func (k Kind) String() string {
+	switch k {
+	case KindParagraph:
+		return "paragraph"
+	case KindHeading:
+		return "heading"
+	case KindListItem:
+		return "list item"
+	case KindTableCell:
+		return "table cell"
+	case KindFrontMatter:
+		return "front matter"
+	}
+
+	return "Kind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Styles of the front matter values.
+const (
+	styleRaw = iota
+	stylePlain
+	styleDouble
+	styleSingle
+)
+
+// blockTags are the HTML elements that start the HTML blocks.
+var blockTags = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "details": true,
+	"dialog": true, "div": true, "dl": true, "figure": true, "footer": true, "form": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "header": true,
+	"hr": true, "iframe": true, "nav": true, "ol": true, "p": true, "picture": true,
+	"pre": true, "script": true, "section": true, "style": true, "summary": true,
+	"table": true, "ul": true, "video": true,
+}
+
+// rawTags are the HTML elements whose blocks end at their closing tags instead
+// of a blank line.
+var rawTags = map[string]bool{"pre": true, "script": true, "style": true}
+
+var (
+	patternATXHeading    = regexp.MustCompile(`^ {0,3}#{1,6}(?:[ \t]+|$)`)
+	patternATXClosing    = regexp.MustCompile(`(?:^|[ \t]+)#+[ \t]*$`)
+	patternBlockScalar   = regexp.MustCompile(`^[|>][-+0-9]*[ \t]*(?:#.*)?$`)
+	patternDelimiterRow  = regexp.MustCompile(`^[ \t]*\|?[ \t]*:?-+:?[ \t]*(?:\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+	patternFence         = regexp.MustCompile("^(?:`{3,}[^`]*|~{3,}.*)$")
+	patternField         = regexp.MustCompile(`^([A-Za-z0-9_][A-Za-z0-9_.-]*):(?:[ \t]+|$)`)
+	patternFootnoteDef   = regexp.MustCompile(`^ {0,3}\[\^[^\]]+\]:[ \t]*`)
+	patternHTMLBlock     = regexp.MustCompile(`^<(?:!|\?|/?([A-Za-z][A-Za-z0-9]*)(?:[ \t/>]|$))`)
+	patternLinkDef       = regexp.MustCompile(`^ {0,3}\[[^\]]+\]:[ \t]*\S`)
+	patternListItem      = regexp.MustCompile(`^[ \t]*(?:[-+*]|(\d{1,9})[.)])(?:[ \t]+(\[[ xX]\][ \t]+)?|$)`)
+	patternSetext        = regexp.MustCompile(`^ {0,3}(?:=+|-+)[ \t]*$`)
+	patternThematicBreak = regexp.MustCompile(`^ {0,3}(?:(?:-[ \t]*){3,}|(?:\*[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+)
+
+// ----------------------------------------------------------------------------
+//  Type: Segment
+// ----------------------------------------------------------------------------
+
+// Segment is a part of the prose of the document to translate.
+type Segment struct {
+	// Key is the key of the front matter field. It is empty for the other kinds.
+	Key string
+	// Text is the Markdown text of the segment. The lines after the first one
+	// keep the prefixes of the containers, such as "> " of the block quotes and
+	// the indent of the list items. The values of the front matter are unquoted.
+	Text string
+	// original is the Text when parsed.
+	original string
+	// Kind is the kind of the segment.
+	Kind Kind
+	// start and end are the byte offsets of the segment in the document.
+	start int
+	end   int
+	// style is the quoting style of the front matter value.
+	style int
+}
+
+// ----------------------------------------------------------------------------
+//  Type: Document
+// ----------------------------------------------------------------------------
+
+// Document is a parsed Markdown document. Only the segments are modified and the
+// rest of the data is written back as is.
+type Document struct {
+	// Segments are the segments of the prose in order.
+	Segments []*Segment
+	// data is the original data of the document.
+	data []byte
+}
+
+// Parse parses the Markdown data. The CommonMark blocks, the GFM tables and the
+// YAML front matter delimited with "---" are recognized. Any data is accepted as
+// Markdown.
+func Parse(data []byte) *Document {
+	parser := &parser{data: data, lines: splitLines(data)}
+
+	parser.parseBody(parser.parseFrontMatter())
+
+	return &Document{Segments: parser.segments, data: data}
+}
+
+// ParseFile reads and parses the Markdown file.
+func ParseFile(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, deepl.WrapIfErr(err, "failed to read Markdown file")
+	}
+
+	return Parse(data), nil
+}
+
+// Encode returns the Markdown data of the document with the current text of the
+// segments.
+func (d *Document) Encode() []byte {
+	var (
+		buf  bytes.Buffer
+		last int
+	)
+
+	for _, segment := range d.Segments {
+		buf.Write(d.data[last:segment.start])
+
+		if segment.Text == segment.original {
+			buf.Write(d.data[segment.start:segment.end])
+		} else {
+			buf.WriteString(segment.render())
+		}
+
+		last = segment.end
+	}
+
+	buf.Write(d.data[last:])
+
+	return buf.Bytes()
+}
+
+// WriteFile writes the Markdown data of the document to the path.
+func (d *Document) WriteFile(path string) error {
+	return deepl.WrapIfErr(os.WriteFile(path, d.Encode(), 0o644), "failed to write Markdown file")
+}
+
+// render returns the text of the segment in the style of the segment.
+func (s *Segment) render() string {
+	switch s.style {
+	case stylePlain:
+		if needsQuote(s.Text) {
+			return strconv.Quote(s.Text)
+		}
+	case styleDouble:
+		return strconv.Quote(s.Text)
+	case styleSingle:
+		return "'" + strings.ReplaceAll(s.Text, "'", "''") + "'"
+	}
+
+	return s.Text
+}
+
+// ----------------------------------------------------------------------------
+//  Type: parser
+// ----------------------------------------------------------------------------
+
+// line is the byte offsets of a line of the document.
+type line struct {
+	// start is the offset of the line.
+	start int
+	// end is the offset of the end of the line excluding the line ending.
+	end int
+}
+
+// parser splits the document into the segments.
+type parser struct {
+	// paragraph is the open paragraph. It is nil if no paragraph is open.
+	paragraph *Segment
+	data      []byte
+	lines     []line
+	segments  []*Segment
+	// quoted is true if the open paragraph is in a block quote.
+	quoted bool
+}
+
+// add adds the segment of the range.
+func (p *parser) add(kind Kind, start int, end int) {
+	text := string(p.data[start:end])
+
+	p.segments = append(p.segments, &Segment{Kind: kind, Text: text, original: text, start: start, end: end})
+}
+
+// addCells adds the cells of the table row. The offset is the offset of the row.
+func (p *parser) addCells(offset int, row string) {
+	var (
+		inCode bool
+		start  int
+	)
+
+	flush := func(end int) {
+		cell := row[start:end]
+		trimmed := strings.TrimSpace(cell)
+
+		if trimmed != "" {
+			head := offset + start + strings.Index(cell, trimmed)
+
+			p.add(KindTableCell, head, head+len(trimmed))
+		}
+	}
+
+	for index := 0; index < len(row); index++ {
+		switch row[index] {
+		case '\\':
+			index++
+		case '`':
+			inCode = !inCode
+		case '|':
+			if !inCode {
+				flush(index)
+				start = index + 1
+			}
+		}
+	}
+
+	flush(len(row))
+}
+
+// closeParagraph adds the open paragraph if any.
+func (p *parser) closeParagraph() {
+	if p.paragraph == nil {
+		return
+	}
+
+	p.add(p.paragraph.Kind, p.paragraph.start, p.paragraph.end)
+	p.paragraph = nil
+}
+
+// openParagraph opens a paragraph of the kind at the offset to the end of the
+// line.
+func (p *parser) openParagraph(kind Kind, start int, ln line, quoted bool) {
+	p.paragraph = &Segment{Kind: kind, start: start, end: p.trimEnd(ln)}
+	p.quoted = quoted
+}
+
+// parseBody parses the blocks of the lines from the index.
+//
+//nolint:cyclop,funlen,gocognit,gocyclo // the block structure is parsed in a single pass
+func (p *parser) parseBody(first int) {
+	var (
+		fence   string
+		inHTML  bool
+		inTable bool
+		// htmlEnd is the marker that ends the open HTML block. Such as "-->". The
+		// block ends at a blank line if empty.
+		htmlEnd string
+		blank   = true
+		// lists are the content columns of the open list items. The innermost
+		// is the last one.
+		lists []int
+	)
+
+	for index := first; index < len(p.lines); index++ {
+		ln := p.lines[index]
+		text := string(p.data[ln.start:ln.end])
+		quote := quotePrefix(text)
+		rest := text[quote:]
+		trimmed := strings.TrimLeft(rest, " \t")
+		indent := indentOf(rest)
+		offset := ln.start + quote
+
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]+" \t") == "" {
+				fence = ""
+			}
+
+			continue
+		}
+
+		if inHTML && htmlEnd != "" {
+			if strings.Contains(strings.ToLower(text), htmlEnd) {
+				inHTML, blank = false, false
+			}
+
+			continue
+		}
+
+		if trimmed == "" {
+			p.closeParagraph()
+
+			inHTML, inTable, blank = false, false, true
+
+			continue
+		}
+
+		wasBlank := blank
+		blank = false
+
+		if inHTML {
+			continue
+		}
+
+		if inTable {
+			p.addCells(offset, rest)
+
+			continue
+		}
+
+		isListItem := patternListItem.MatchString(rest)
+
+		if wasBlank && !isListItem {
+			for len(lists) > 0 && indent < lists[len(lists)-1] {
+				lists = lists[:len(lists)-1]
+			}
+		}
+
+		// column is the content column of the innermost list item. The indented
+		// code blocks and the fences are relative to it.
+		column := 0
+		if len(lists) > 0 {
+			column = lists[len(lists)-1]
+		}
+
+		if p.paragraph != nil && quote > 0 && !p.quoted {
+			p.closeParagraph()
+		}
+
+		switch {
+		case patternFence.MatchString(trimmed) && indent < column+4:
+			p.closeParagraph()
+
+			fence = trimmed[:3]
+			for len(fence) < len(trimmed) && trimmed[len(fence)] == fence[0] {
+				fence = trimmed[:len(fence)+1]
+			}
+		case p.paragraph == nil && indent >= column+4:
+			// Indented code block
+		case p.paragraph != nil && patternSetext.MatchString(rest):
+			p.paragraph.Kind = KindHeading
+			p.closeParagraph()
+		case patternThematicBreak.MatchString(rest):
+			p.closeParagraph()
+		case patternATXHeading.MatchString(rest):
+			p.closeParagraph()
+
+			head := patternATXHeading.FindStringIndex(rest)[1]
+			content := strings.TrimRight(patternATXClosing.ReplaceAllString(rest[head:], ""), " \t")
+
+			if content != "" {
+				p.add(KindHeading, offset+head, offset+head+len(content))
+			}
+		case p.paragraph == nil && isHTMLBlock(trimmed):
+			// The end marker may be on the same line. Such as "<!-- note -->".
+			htmlEnd = htmlBlockEnd(trimmed)
+			inHTML = htmlEnd == "" || !strings.Contains(strings.ToLower(trimmed[2:]), htmlEnd)
+		case patternFootnoteDef.MatchString(rest):
+			p.closeParagraph()
+
+			if head := patternFootnoteDef.FindStringIndex(rest)[1]; head < len(rest) {
+				p.openParagraph(KindParagraph, offset+head, ln, quote > 0)
+			}
+		case p.paragraph == nil && patternLinkDef.MatchString(rest):
+			// Link reference definition
+		case p.paragraph == nil && strings.Contains(rest, "|") && p.isDelimiterRow(index+1):
+			p.addCells(offset, rest)
+
+			index++
+			inTable = true
+		case isListItem && (p.paragraph == nil || len(lists) > 0 || canInterrupt(rest)):
+			p.closeParagraph()
+
+			for len(lists) > 0 && indent < lists[len(lists)-1] {
+				lists = lists[:len(lists)-1]
+			}
+
+			lists = append(lists, contentColumn(rest))
+
+			if head := patternListItem.FindStringIndex(rest)[1]; head < len(rest) {
+				p.openParagraph(KindListItem, offset+head, ln, quote > 0)
+			}
+		case p.paragraph != nil:
+			// Continuation line of the paragraph
+			p.paragraph.end = p.trimEnd(ln)
+		default:
+			p.openParagraph(KindParagraph, offset+len(rest)-len(trimmed), ln, quote > 0)
+		}
+	}
+
+	p.closeParagraph()
+}
+
+// parseFrontMatter parses the YAML front matter and returns the index of the
+// first line of the body.
+func (p *parser) parseFrontMatter() int {
+	if len(p.lines) == 0 || strings.TrimPrefix(p.lineText(0), "\ufeff") != "---" {
+		return 0
+	}
+
+	closing := -1
+
+	for index := 1; index < len(p.lines); index++ {
+		if text := p.lineText(index); text == "---" || text == "..." {
+			closing = index
+
+			break
+		}
+	}
+
+	if closing < 0 {
+		return 0
+	}
+
+	for index := 1; index < closing; index++ {
+		index = p.parseField(index, closing)
+	}
+
+	return closing + 1
+}
+
+// parseField parses the top-level field of the front matter at the index and
+// returns the index of the last line of the field. The fields other than the
+// scalars, such as the mappings, the sequences and the multi-line plain
+// scalars, are skipped.
+func (p *parser) parseField(index int, closing int) int {
+	text := p.lineText(index)
+
+	match := patternField.FindStringSubmatchIndex(text)
+	if match == nil {
+		return index
+	}
+
+	key := text[match[2]:match[3]]
+	value := strings.TrimRight(text[match[1]:], " \t")
+	offset := p.lines[index].start + match[1]
+
+	if value == "" {
+		return index
+	}
+
+	switch value[0] {
+	case '|', '>':
+		if patternBlockScalar.MatchString(value) {
+			return p.parseBlockScalar(key, index, closing)
+		}
+	case '"':
+		if end := closingQuote(value); end > 0 {
+			if unquoted, err := strconv.Unquote(value[:end+1]); err == nil {
+				p.addField(key, unquoted, styleDouble, offset, offset+end+1)
+			}
+		}
+	case '\'':
+		if end := closingQuote(value); end > 0 {
+			p.addField(key, strings.ReplaceAll(value[1:end], "''", "'"), styleSingle, offset, offset+end+1)
+		}
+	case '[', '{', '&', '*', '!', '%', '@', '`', '#':
+		// Flow collections, anchors, aliases, tags and reserved indicators
+	default:
+		if position := strings.Index(value, " #"); position >= 0 {
+			value = strings.TrimRight(value[:position], " \t")
+		}
+
+		if index+1 < closing && indentOf(p.lineText(index+1)) > 0 {
+			// Multi-line plain scalar
+			return index
+		}
+
+		p.addField(key, value, stylePlain, offset, offset+len(value))
+	}
+
+	return index
+}
+
+// parseBlockScalar parses the literal ("|") or folded (">") block scalar of the
+// field at the index and returns the index of the last line of the field.
+func (p *parser) parseBlockScalar(key string, index int, closing int) int {
+	first, last := -1, -1
+
+	next := index + 1
+	for ; next < closing; next++ {
+		text := p.lineText(next)
+
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		if indentOf(text) == 0 {
+			break
+		}
+
+		if first < 0 {
+			first = next
+		}
+
+		last = next
+	}
+
+	if first < 0 {
+		return next - 1
+	}
+
+	text := p.lineText(first)
+	start := p.lines[first].start + len(text) - len(strings.TrimLeft(text, " \t"))
+
+	p.add(KindFrontMatter, start, p.trimEnd(p.lines[last]))
+	p.segments[len(p.segments)-1].Key = key
+
+	return next - 1
+}
+
+// addField adds the front matter field of the value in the style.
+func (p *parser) addField(key string, value string, style int, start int, end int) {
+	p.segments = append(p.segments, &Segment{
+		Kind:     KindFrontMatter,
+		Key:      key,
+		Text:     value,
+		original: value,
+		start:    start,
+		end:      end,
+		style:    style,
+	})
+}
+
+// isDelimiterRow returns true if the line at the index is the delimiter row of
+// the table.
+func (p *parser) isDelimiterRow(index int) bool {
+	if index >= len(p.lines) {
+		return false
+	}
+
+	text := p.lineText(index)
+	text = text[quotePrefix(text):]
+
+	return strings.Contains(text, "-") && patternDelimiterRow.MatchString(text)
+}
+
+// lineText returns the text of the line at the index without the line ending.
+func (p *parser) lineText(index int) string {
+	return string(p.data[p.lines[index].start:p.lines[index].end])
+}
+
+// trimEnd returns the offset of the end of the line without the trailing spaces.
+func (p *parser) trimEnd(ln line) int {
+	return ln.start + len(strings.TrimRight(string(p.data[ln.start:ln.end]), " \t"))
+}
+
+// ----------------------------------------------------------------------------
+//  Private Functions
+// ----------------------------------------------------------------------------
+
+// canInterrupt returns true if the list item can interrupt a paragraph out of the
+// lists. Which is the non-empty bullet item or the ordered item starting with 1.
+func canInterrupt(text string) bool {
+	match := patternListItem.FindStringSubmatchIndex(text)
+	if match == nil || match[1] == len(text) {
+		return false
+	}
+
+	return match[2] < 0 || text[match[2]:match[3]] == "1"
+}
+
+// closingQuote returns the index of the closing quote of the quoted scalar or -1
+// if not found.
+func closingQuote(value string) int {
+	quote := value[0]
+
+	for index := 1; index < len(value); index++ {
+		switch {
+		case quote == '"' && value[index] == '\\':
+			index++
+		case value[index] == quote && quote == '\'' && index+1 < len(value) && value[index+1] == '\'':
+			index++
+		case value[index] == quote:
+			return index
+		}
+	}
+
+	return -1
+}
+
+// contentColumn returns the content column of the list item. Which is the width
+// of the marker and the following spaces. The task list box is a part of the
+// content.
+func contentColumn(text string) int {
+	match := patternListItem.FindStringSubmatchIndex(text)
+	if match == nil {
+		return 0
+	}
+
+	end := match[1]
+	if match[4] >= 0 {
+		end = match[4]
+	}
+
+	width := 0
+
+	for _, char := range text[:end] {
+		if char == '\t' {
+			width += 4
+		} else {
+			width++
+		}
+	}
+
+	return width
+}
+
+// indentOf returns the width of the leading spaces of the text. A tab counts as
+// four spaces.
+func indentOf(text string) int {
+	width := 0
+
+	for _, char := range text {
+		switch char {
+		case ' ':
+			width++
+		case '\t':
+			width += 4
+		default:
+			return width
+		}
+	}
+
+	return width
+}
+
+// htmlBlockEnd returns the marker that ends the HTML block started by the line.
+// Such as "-->" of the comments and "</pre>" of the <pre> elements, whose content
+// may have blank lines. It is empty if the block ends at a blank line.
+func htmlBlockEnd(text string) string {
+	switch {
+	case strings.HasPrefix(text, "<!--"):
+		return "-->"
+	case strings.HasPrefix(text, "<?"):
+		return "?>"
+	case strings.HasPrefix(text, "<![CDATA["):
+		return "]]>"
+	case strings.HasPrefix(text, "<!"):
+		return ">"
+	}
+
+	match := patternHTMLBlock.FindStringSubmatch(text)
+	if name := strings.ToLower(match[1]); rawTags[name] && !strings.HasPrefix(text, "</") {
+		return "</" + name + ">"
+	}
+
+	return ""
+}
+
+// isHTMLBlock returns true if the line starts an HTML block. Such as the
+// comments and the block-level elements.
+func isHTMLBlock(text string) bool {
+	match := patternHTMLBlock.FindStringSubmatch(text)
+	if match == nil {
+		return false
+	}
+
+	return match[1] == "" || blockTags[strings.ToLower(match[1])]
+}
+
+// needsQuote returns true if the plain scalar must be quoted to keep the value.
+func needsQuote(value string) bool {
+	if value == "" || value != strings.TrimSpace(value) {
+		return true
+	}
+
+	return strings.ContainsAny(value[:1], "-?:,[]{}#&*!|>'\"%@`") ||
+		strings.Contains(value, ": ") || strings.Contains(value, " #") ||
+		strings.HasSuffix(value, ":") || strings.ContainsAny(value, "\r\n")
+}
+
+// quotePrefix returns the length of the block quote markers of the line. Such
+// as "> " and "> > ".
+func quotePrefix(text string) int {
+	length := 0
+
+	for {
+		rest := text[length:]
+		trimmed := strings.TrimLeft(rest, " ")
+
+		if len(rest)-len(trimmed) > 3 || !strings.HasPrefix(trimmed, ">") {
+			return length
+		}
+
+		length += len(rest) - len(trimmed) + 1
+
+		if strings.HasPrefix(text[length:], " ") {
+			length++
+		}
+	}
+}
+
+// splitLines returns the lines of the data.
+func splitLines(data []byte) []line {
+	var lines []line
+
+	for start := 0; start < len(data); {
+		next := bytes.IndexByte(data[start:], '\n')
+		if next < 0 {
+			lines = append(lines, line{start: start, end: len(data)})
+
+			break
+		}
+
+		end := start + next
+		if end > start && data[end-1] == '\r' {
+			end--
+		}
+
+		lines = append(lines, line{start: start, end: end})
+		start += next + 1
+	}
+
+	return lines
+}
@@ -0,0 +1,158 @@
+package markdown_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/KEINOS/go-deepl/deepl/markdown"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFile(t *testing.T) {
+	t.Parallel()
+
+	doc, err := markdown.ParseFile(filepath.Join("testdata", "sample.md"))
+	require.NoError(t, err)
+
+	type segment struct {
+		Kind markdown.Kind
+		Key  string
+		Text string
+	}
+
+	got := make([]segment, len(doc.Segments))
+	for index, seg := range doc.Segments {
+		got[index] = segment{Kind: seg.Kind, Key: seg.Key, Text: seg.Text}
+	}
+
+	require.Equal(t, []segment{
+		{markdown.KindFrontMatter, "title", "Getting started"},
+		{markdown.KindFrontMatter, "description", `How to install the "deepl" command.`},
+		{markdown.KindFrontMatter, "summary", "It's easy"},
+		{markdown.KindFrontMatter, "slug", "getting-started"},
+		{markdown.KindFrontMatter, "weight", "10"},
+		{markdown.KindFrontMatter, "notes", "First line.\n  Second line."},
+		{markdown.KindHeading, "", "Getting started {#start}"},
+		{markdown.KindParagraph, "", "Install the **command** with `go install` and read the " +
+			"[manual](https://example.com/manual \"Manual\").\nSee https://example.com/faq for more."},
+		{markdown.KindHeading, "", "Setup"},
+		{markdown.KindListItem, "", "Run `deepl usage` to check the quota."},
+		{markdown.KindListItem, "", "Set the `DEEPL_API_KEY`\n  environment variable."},
+		{markdown.KindListItem, "", "First step"},
+		{markdown.KindListItem, "", "Second step"},
+		{markdown.KindParagraph, "", "Quoted text\n> on two lines."},
+		{markdown.KindTableCell, "", "Name"},
+		{markdown.KindTableCell, "", "Description"},
+		{markdown.KindTableCell, "", "`--to`"},
+		{markdown.KindTableCell, "", "Target language"},
+		{markdown.KindTableCell, "", "42"},
+		{markdown.KindTableCell, "", "![Logo](logo.png)"},
+		{markdown.KindParagraph, "", "Last paragraph with a footnote[^1]."},
+		{markdown.KindParagraph, "", "The footnote text."},
+	}, got, "code blocks, HTML blocks, link definitions and non-scalar fields should not be segments")
+}
+
+func TestParseFile_missing(t *testing.T) {
+	t.Parallel()
+
+	_, err := markdown.ParseFile(filepath.Join(t.TempDir(), "missing.md"))
+	require.Error(t, err)
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		input  string
+		expect []string
+	}{
+		{"---\ntitle: no closing\n", []string{"title: no closing"}},
+		{"Lazy\ncontinuation\n* item\n", []string{"Lazy\ncontinuation", "item"}},
+		{"Paragraph\n2. not a list\n", []string{"Paragraph\n2. not a list"}},
+		{"> > nested\n> > quote\n", []string{"nested\n> > quote"}},
+		{"Text\n> quote\n", []string{"Text", "quote"}},
+		{"~~~\ncode\n~~~~\n\n## Title ##\n", []string{"Title"}},
+		{"<!-- comment\ntext -->\n\n<span>inline</span> HTML\n", []string{"<span>inline</span> HTML"}},
+		{"<!--\nLicense header\n\nSecond para of comment\n-->\n", []string{}},
+		{"<pre>\ncode line\n\nmore code\n</pre>\n", []string{}},
+		{"<SCRIPT>\n\nvar a;\n</script>\nAfter\n", []string{"After"}},
+		{"<!-- note -->\nText\n", []string{"Text"}},
+		{"<div>\nblock\n\ntext\n", []string{"text"}},
+		{"Title\n===\n---\n", []string{"Title"}},
+		{"a | b\n--|--\nc\n\nd\n", []string{"a", "b", "c", "d"}},
+		{"Hard  \nbreak\\\nhere", []string{"Hard  \nbreak\\\nhere"}},
+		{"- item\n\n      code\n", []string{"item"}},
+		{"- item\n\n    continued\n", []string{"item", "continued"}},
+		{
+			"1. item\n   - nested\n\n         code\n\n     nested para\n\n   outer para\n",
+			[]string{"item", "nested", "nested para", "outer para"},
+		},
+		{"- [x] task\n\n      code\n", []string{"task"}},
+		{"- item\n\n    ```\n    code\n    ```\n", []string{"item"}},
+	} {
+		doc := markdown.Parse([]byte(test.input))
+
+		texts := make([]string, len(doc.Segments))
+		for index, seg := range doc.Segments {
+			texts[index] = seg.Text
+		}
+
+		require.Equal(t, test.expect, texts, "input: %q", test.input)
+		require.Equal(t, test.input, string(doc.Encode()), "input: %q", test.input)
+	}
+}
+
+func TestDocument_Encode_round_trip(t *testing.T) {
+	t.Parallel()
+
+	data, err := os.ReadFile(filepath.Join("testdata", "sample.md"))
+	require.NoError(t, err)
+
+	require.Equal(t, string(data), string(markdown.Parse(data).Encode()))
+
+	crlf := strings.ReplaceAll(string(data), "\n", "\r\n")
+	doc := markdown.Parse([]byte(crlf))
+
+	require.Len(t, doc.Segments, 22)
+	require.Equal(t, "Quoted text\r\n> on two lines.", doc.Segments[13].Text)
+	require.Equal(t, crlf, string(doc.Encode()))
+}
+
+func TestDocument_Encode_front_matter(t *testing.T) {
+	t.Parallel()
+
+	doc := markdown.Parse([]byte("---\nplain: a\nquote: a\ncolon: a\ndouble: \"a\" # comment\nsingle: 'a'\n---\n"))
+
+	require.Len(t, doc.Segments, 5)
+
+	doc.Segments[0].Text = "b"
+	doc.Segments[1].Text = "'b'"
+	doc.Segments[2].Text = "b: c"
+	doc.Segments[3].Text = "b\n\"c\""
+	doc.Segments[4].Text = "it's"
+
+	require.Equal(t,
+		"---\nplain: b\nquote: \"'b'\"\ncolon: \"b: c\"\ndouble: \"b\\n\\\"c\\\"\" # comment\nsingle: 'it''s'\n---\n",
+		string(doc.Encode()), "values should be quoted as needed")
+}
+
+func TestDocument_WriteFile(t *testing.T) {
+	t.Parallel()
+
+	doc := markdown.Parse([]byte("# Title\n"))
+
+	require.Error(t, doc.WriteFile(filepath.Join(t.TempDir(), "missing", "out.md")))
+}
+
+func TestKind_String(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "paragraph", markdown.KindParagraph.String())
+	require.Equal(t, "heading", markdown.KindHeading.String())
+	require.Equal(t, "list item", markdown.KindListItem.String())
+	require.Equal(t, "table cell", markdown.KindTableCell.String())
+	require.Equal(t, "front matter", markdown.KindFrontMatter.String())
+	require.Equal(t, "Kind(99)", markdown.Kind(99).String())
+}
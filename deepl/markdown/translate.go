@@ -0,0 +1,180 @@
+package markdown
+
+import (
+	"context"
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/KEINOS/go-deepl/deepl"
+	"github.com/KEINOS/go-deepl/deepl/placeholder"
+)
+
+// patternsInline are the patterns of the inline syntax kept untouched in the
+// translation. The text of the links and the alt text of the images are
+// translated while the brackets and the destinations are kept.
+var patternsInline = []*regexp.Regexp{
+	// Backslash escapes. Such as "\*".
+	regexp.MustCompile("\\\\[!-/:-@\\[-`{-~]"),
+	// Code spans. Such as "`go test`" and "``a ` b``".
+	regexp.MustCompile("(?s)``.+?``|`[^`]+`"),
+	// Autolinks, HTML tags and comments. Such as "<https://example.com>",
+	// "<br/>" and "<!-- note -->".
+	regexp.MustCompile(`<[A-Za-z][A-Za-z0-9+.-]*:[^<>\s]*>|</?[A-Za-z][A-Za-z0-9-]*(?:\s[^<>]*)?/?>|(?s:<!--.*?-->)`),
+	// Footnote references. Such as "[^1]".
+	regexp.MustCompile(`\[\^[^\[\]\s]+\]`),
+	// Destinations of the inline and reference links. Such as "](url "title")"
+	// and "][ref]".
+	regexp.MustCompile(`\]\((?:[^()\s]|\([^()\s]*\))*(?:\s+(?:"[^"]*"|'[^']*'|\([^()]*\)))?[ \t]*\)|\]\[[^\[\]]*\]`),
+	// Brackets of the links and the images. Such as "[" and "![".
+	regexp.MustCompile(`!?\[|\]`),
+	// Bare URLs. Such as "https://example.com/path".
+	regexp.MustCompile(`https?://[^\s<>()\[\]]*[^\s<>()\[\].,;:!?'"]`),
+	// Attributes. Such as "{#anchor}" and "{.class}".
+	regexp.MustCompile(`\{[#.][^{}\n]*\}`),
+	// Emphasis and strikethrough delimiters. The underscores within the words
+	// are kept as the text. Such as "snake_case".
+	regexp.MustCompile(`\*+|~~+|\b_+|_+\b`),
+	// Line breaks with the prefixes of the containers of the next line.
+	patternBreak,
+}
+
+var (
+	// patternBreak matches the line breaks with the prefixes of the containers of
+	// the next line. Such as "\n> " and "  \n   ".
+	patternBreak = regexp.MustCompile(`[ \t]*\\?\r?\n[ \t>]*`)
+	// patternElementOnly matches the element of the placeholder.
+	patternElementOnly = regexp.MustCompile(`<x\s+id\s*=\s*"\d+"\s*(?:/>|>\s*</x>)`)
+)
+
+// ----------------------------------------------------------------------------
+//  Type: Options
+// ----------------------------------------------------------------------------
+
+// Options are the options of Translate.
+type Options struct {
+	// TranslateOptions are the options of the translation. TargetLang is
+	// required. TagHandling is overridden since the inline syntax, such as the
+	// code spans and the link destinations, is sent as the XML elements.
+	TranslateOptions deepl.TranslateOptions
+	// FrontMatterFields are the top-level keys of the YAML front matter to
+	// translate. Such as "title" and "description". The other fields are kept.
+	FrontMatterFields []string
+	// Placeholders are the additional patterns to keep untouched. Such as
+	// placeholder.Braces for the template variables.
+	Placeholders []*regexp.Regexp
+}
+
+// Result is the result of Translate.
+type Result struct {
+	// Translated is the number of the segments translated.
+	Translated int
+	// Skipped is the number of the segments skipped. Such as the segments with
+	// no words and the front matter fields not in the FrontMatterFields.
+	Skipped int
+}
+
+// ----------------------------------------------------------------------------
+//  Functions
+// ----------------------------------------------------------------------------
+
+// Translate translates the segments of the document in place with the
+// translator.
+//
+// The inline syntax, such as the code spans, the URLs, the HTML tags and the
+// emphasis delimiters, and the line breaks are kept in the translation. The text
+// of the links and the alt text of the images are translated. The translation
+// fails if any of them is lost.
+//
+// All the segments are requested in a single call of the translator. The Client
+// splits them into the requests within the limits of the API.
+func Translate(ctx context.Context, translator deepl.Translator, doc *Document, opts Options) (*Result, error) {
+	if doc == nil {
+		return nil, deepl.NewErr("Markdown document is nil")
+	}
+
+	if opts.TranslateOptions.TargetLang == "" {
+		return nil, deepl.NewErr("target language is empty")
+	}
+
+	fields := make(map[string]bool, len(opts.FrontMatterFields))
+	for _, key := range opts.FrontMatterFields {
+		fields[key] = true
+	}
+
+	masker := placeholder.NewMasker(append(append([]*regexp.Regexp(nil), opts.Placeholders...), patternsInline...)...)
+	result := new(Result)
+
+	var (
+		pending []*Segment
+		texts   []string
+		tokens  [][]string
+	)
+
+	for _, segment := range doc.Segments {
+		masked, segTokens := masker.MaskBreaks(segment.Text)
+
+		if (segment.Kind == KindFrontMatter && !fields[segment.Key]) || !hasWords(masked) {
+			result.Skipped++
+
+			continue
+		}
+
+		pending = append(pending, segment)
+		texts = append(texts, masked)
+		tokens = append(tokens, segTokens)
+	}
+
+	if len(pending) == 0 {
+		return result, nil
+	}
+
+	translated, err := placeholder.Translate(ctx, translator, texts, tokens, opts.TranslateOptions)
+	if err != nil {
+		return nil, deepl.WrapIfErr(err, "failed to translate Markdown segments")
+	}
+
+	// Update the segments only if all the segments are translated
+	for index, segment := range pending {
+		segment.Text = strings.TrimSpace(translated[index])
+	}
+
+	result.Translated = len(pending)
+
+	return result, nil
+}
+
+// TranslateFile translates the Markdown file and writes it to the target file.
+func TranslateFile(
+	ctx context.Context,
+	translator deepl.Translator,
+	sourcePath string,
+	targetPath string,
+	opts Options,
+) (*Result, error) {
+	doc, err := ParseFile(sourcePath)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := Translate(ctx, translator, doc, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := doc.WriteFile(targetPath); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ----------------------------------------------------------------------------
+//  Private Functions
+// ----------------------------------------------------------------------------
+
+// hasWords returns true if the masked text has any letter to translate.
+func hasWords(masked string) bool {
+	return strings.IndexFunc(html.UnescapeString(patternElementOnly.ReplaceAllString(masked, "")), unicode.IsLetter) >= 0
+}
@@ -0,0 +1,122 @@
+package markdown_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/KEINOS/go-deepl/deepl"
+	"github.com/KEINOS/go-deepl/deepl/deepltest"
+	"github.com/KEINOS/go-deepl/deepl/markdown"
+	"github.com/KEINOS/go-deepl/deepl/placeholder"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslateFile(t *testing.T) {
+	t.Parallel()
+
+	pathTarget := filepath.Join(t.TempDir(), "sample.de.md")
+	fake := deepltest.NewFakeTranslator()
+
+	result, err := markdown.TranslateFile(context.Background(), fake, filepath.Join("testdata", "sample.md"), pathTarget,
+		markdown.Options{
+			TranslateOptions:  deepl.TranslateOptions{SourceLang: "EN", TargetLang: "DE"},
+			FrontMatterFields: []string{"title", "description", "notes"},
+		})
+	require.NoError(t, err)
+	require.Equal(t, &markdown.Result{Translated: 17, Skipped: 5}, result)
+
+	expect, err := os.ReadFile(filepath.Join("testdata", "sample.de.md"))
+	require.NoError(t, err)
+
+	actual, err := os.ReadFile(pathTarget)
+	require.NoError(t, err)
+	require.Equal(t, string(expect), string(actual))
+
+	calls := fake.Calls()
+	require.Len(t, calls, 1, "all the segments should be requested at once")
+	require.Equal(t, deepl.TagHandlingXML, calls[0].Options.TagHandling)
+	require.Contains(t, calls[0].Texts,
+		`Install the <x id="0"/>command<x id="1"/> with <x id="2"/> and read the <x id="3"/>manual<x id="4"/>.`+
+			` <x id="5"/> See <x id="6"/> for more.`,
+		"inline syntax and line breaks should be masked")
+	require.Contains(t, calls[0].Texts, `<x id="0"/>Logo<x id="1"/>`, "alt text of the image should be translated")
+	require.NotContains(t, calls[0].Texts, "It's easy", "fields not in FrontMatterFields should be kept")
+}
+
+func TestTranslate_placeholders(t *testing.T) {
+	t.Parallel()
+
+	doc := markdown.Parse([]byte("Hello, {name}! Use `snake_case` or _emphasis_ and ~~strike~~.\n"))
+	fake := deepltest.NewFakeTranslator()
+
+	_, err := markdown.Translate(context.Background(), fake, doc, markdown.Options{
+		TranslateOptions: deepl.TranslateOptions{TargetLang: "JA"},
+		Placeholders:     []*regexp.Regexp{placeholder.Braces},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, []string{
+		`Hello, <x id="0"/>! Use <x id="1"/> or <x id="2"/>emphasis<x id="3"/> and <x id="4"/>strike<x id="5"/>.`,
+	}, fake.Calls()[0].Texts)
+	require.Equal(t, "[JA] Hello, {name}! Use `snake_case` or _emphasis_ and ~~strike~~.\n", string(doc.Encode()))
+}
+
+func TestTranslate_line_breaks(t *testing.T) {
+	t.Parallel()
+
+	doc := markdown.Parse([]byte("> first `code`\n> second\n"))
+	fake := deepltest.NewFakeTranslator()
+
+	fake.TranslateFunc = func(text string, _ *deepl.TranslateOptions) string {
+		// Move the line break and drop the spaces around it
+		return strings.Replace(text, ` <x id="1"/> second`, `<x id="1"/>zweite`, 1)
+	}
+
+	_, err := markdown.Translate(context.Background(), fake, doc, markdown.Options{
+		TranslateOptions: deepl.TranslateOptions{TargetLang: "DE"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "> first `code`\n> zweite\n", string(doc.Encode()))
+}
+
+func TestTranslate_nothing_to_translate(t *testing.T) {
+	t.Parallel()
+
+	doc := markdown.Parse([]byte("```\ncode\n```\n\n| 1 | `x` |\n|---|---|\n"))
+	fake := deepltest.NewFakeTranslator()
+
+	result, err := markdown.Translate(context.Background(), fake, doc, markdown.Options{
+		TranslateOptions: deepl.TranslateOptions{TargetLang: "DE"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, &markdown.Result{Skipped: 2}, result)
+	require.Empty(t, fake.Calls())
+}
+
+func TestTranslate_errors(t *testing.T) {
+	t.Parallel()
+
+	fake := deepltest.NewFakeTranslator()
+	opts := markdown.Options{TranslateOptions: deepl.TranslateOptions{TargetLang: "DE"}}
+
+	_, err := markdown.Translate(context.Background(), fake, nil, opts)
+	require.Error(t, err, "nil document should be an error")
+
+	doc := markdown.Parse([]byte("# Title\n\nRun `go test`.\n"))
+
+	_, err = markdown.Translate(context.Background(), fake, doc, markdown.Options{})
+	require.Error(t, err, "empty target language should be an error")
+
+	fake.TranslateFunc = func(text string, _ *deepl.TranslateOptions) string {
+		return strings.ReplaceAll(text, `<x id="0"/>`, "")
+	}
+
+	_, err = markdown.Translate(context.Background(), fake, doc, opts)
+	require.Error(t, err, "lost inline code should be an error")
+	require.Equal(t, "# Title\n\nRun `go test`.\n", string(doc.Encode()), "document should not be changed on error")
+	require.Contains(t, err.Error(), "Run `go test`.", "error should tell the source segment")
+}
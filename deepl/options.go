@@ -0,0 +1,247 @@
+package deepl
+
+import (
+	"net/url"
+	"strings"
+)
+
+// ----------------------------------------------------------------------------
+//  Enum Types for TranslateOptions
+// ----------------------------------------------------------------------------
+
+// Formality is an enum type to set whether the translated text should lean
+// towards formal or informal language.
+type Formality string
+
+const (
+	// FormalityDefault uses the default formality of the target language.
+	FormalityDefault Formality = "default"
+	// FormalityMore translates to a more formal language.
+	FormalityMore Formality = "more"
+	// FormalityLess translates to a more informal language.
+	FormalityLess Formality = "less"
+	// FormalityPreferMore translates to a more formal language if available,
+	// otherwise fallback to the default formality.
+	FormalityPreferMore Formality = "prefer_more"
+	// FormalityPreferLess translates to a more informal language if available,
+	// otherwise fallback to the default formality.
+	FormalityPreferLess Formality = "prefer_less"
+)
+
+// SplitSentences is an enum type to set whether the translation engine should
+// first split the input into sentences.
+type SplitSentences string
+
+const (
+	// SplitSentencesOff disables splitting. The input is treated as one sentence.
+	SplitSentencesOff SplitSentences = "0"
+	// SplitSentencesOn splits on punctuation and on newlines (API default).
+	SplitSentencesOn SplitSentences = "1"
+	// SplitSentencesNoNewlines splits on punctuation only, ignoring newlines.
+	SplitSentencesNoNewlines SplitSentences = "nonewlines"
+)
+
+// ModelType is an enum type to set which kind of translation model to use.
+type ModelType string
+
+const (
+	// ModelTypeQualityOptimized uses the highest-quality model and fails if it is
+	// not available for the language pair.
+	ModelTypeQualityOptimized ModelType = "quality_optimized"
+	// ModelTypePreferQualityOptimized uses the highest-quality model if available,
+	// otherwise fallback to the latency optimized model.
+	ModelTypePreferQualityOptimized ModelType = "prefer_quality_optimized"
+	// ModelTypeLatencyOptimized uses the model with the lowest latency.
+	ModelTypeLatencyOptimized ModelType = "latency_optimized"
+)
+
+// TagHandling is an enum type to set which kind of tags should be handled.
+type TagHandling string
+
+const (
+	// TagHandlingXML handles the texts as XML.
+	TagHandlingXML TagHandling = "xml"
+	// TagHandlingHTML handles the texts as HTML.
+	TagHandlingHTML TagHandling = "html"
+)
+
+// ----------------------------------------------------------------------------
+//  Type: Tags
+// ----------------------------------------------------------------------------
+
+// Tags is a list of XML tag names used in the tag handling options.
+type Tags []string
+
+// NewTags returns a new Tags from the given tag names.
+//
+//	deepl.NewTags("par", "title") // equivalent to deepl.Tags{"par", "title"}
+func NewTags(names ...string) Tags {
+	return Tags(names)
+}
+
+// String returns the tag names as a comma-separated list. Which is the format
+// of the API parameters.
+func (t Tags) String() string {
+	return strings.Join(t, ",")
+}
+
+// Validate returns an error if any of the tag names is empty or contains a
+// comma or white spaces.
+func (t Tags) Validate() error {
+	for index, name := range t {
+		if name == "" {
+			return NewErr("tag name #%d is empty", index+1)
+		}
+
+		if strings.ContainsAny(name, ", \t\r\n") {
+			return NewErr("tag name #%d contains a comma or white space: %q", index+1, name)
+		}
+	}
+
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+//  Type: TranslateOptions
+// ----------------------------------------------------------------------------
+
+// TranslateOptions holds the request parameters of the translation API other
+// than the texts to translate.
+//
+// Zero values are not sent to the API, so the API default is used.
+type TranslateOptions struct {
+	// SourceLang is the language code of the texts to translate. If empty, the
+	// API will detect the language automatically.
+	SourceLang string
+	// TargetLang is the language code to translate the texts into. (Required)
+	TargetLang string
+	// Context is an additional text that influences the translation but is not
+	// translated itself. Characters in the context are not billed.
+	Context string
+	// Formality sets whether the translation should lean towards formal or
+	// informal language. Only some target languages support it.
+	Formality Formality
+	// SplitSentences sets whether the input should be split into sentences.
+	SplitSentences SplitSentences
+	// ModelType sets which kind of translation model to use.
+	ModelType ModelType
+	// PreserveFormatting prevents the API from correcting the formatting, such
+	// as punctuation and upper/lower case at the beginning of sentences.
+	PreserveFormatting bool
+	// ShowBilledCharacters requests the API to include the number of billed
+	// characters in each translation of the response.
+	ShowBilledCharacters bool
+	// GlossaryID is the ID of the glossary to use for the translation. The
+	// SourceLang is required if set, and the language pair must match the one
+	// of the glossary.
+	GlossaryID string
+
+	// TagHandling sets which kind of tags should be handled. It is required to
+	// set the other tag handling options below.
+	TagHandling TagHandling
+	// NonSplittingTags is a list of XML tags which never split sentences.
+	NonSplittingTags Tags
+	// SplittingTags is a list of XML tags which always cause splits.
+	SplittingTags Tags
+	// IgnoreTags is a list of XML tags whose content is not translated.
+	IgnoreTags Tags
+	// DisableOutlineDetection disables the automatic detection of the XML
+	// structure. Which is enabled by default in the API. Use it along with
+	// SplittingTags and NonSplittingTags to have full control over the splits.
+	DisableOutlineDetection bool
+}
+
+// Validate returns an error if any of the enum values in the options are not
+// supported.
+func (o *TranslateOptions) Validate() error {
+	switch o.Formality {
+	case "", FormalityDefault, FormalityMore, FormalityLess, FormalityPreferMore, FormalityPreferLess:
+	default:
+		return NewErr("unsupported formality: %q", o.Formality)
+	}
+
+	switch o.SplitSentences {
+	case "", SplitSentencesOff, SplitSentencesOn, SplitSentencesNoNewlines:
+	default:
+		return NewErr("unsupported split_sentences: %q", o.SplitSentences)
+	}
+
+	switch o.ModelType {
+	case "", ModelTypeQualityOptimized, ModelTypePreferQualityOptimized, ModelTypeLatencyOptimized:
+	default:
+		return NewErr("unsupported model_type: %q", o.ModelType)
+	}
+
+	if o.GlossaryID != "" && o.SourceLang == "" {
+		return NewErr("source_lang is required when glossary_id is set")
+	}
+
+	return o.validateTagOptions()
+}
+
+// validateTagOptions returns an error if the tag handling options are invalid.
+func (o *TranslateOptions) validateTagOptions() error {
+	switch o.TagHandling {
+	case TagHandlingXML, TagHandlingHTML:
+	case "":
+		if len(o.NonSplittingTags) != 0 || len(o.SplittingTags) != 0 ||
+			len(o.IgnoreTags) != 0 || o.DisableOutlineDetection {
+			return NewErr("tag handling options are set but tag_handling is empty")
+		}
+
+		return nil
+	default:
+		return NewErr("unsupported tag_handling: %q", o.TagHandling)
+	}
+
+	if err := o.NonSplittingTags.Validate(); err != nil {
+		return WrapIfErr(err, "invalid non_splitting_tags")
+	}
+
+	if err := o.SplittingTags.Validate(); err != nil {
+		return WrapIfErr(err, "invalid splitting_tags")
+	}
+
+	return WrapIfErr(o.IgnoreTags.Validate(), "invalid ignore_tags")
+}
+
+// urlValues returns the options as the request parameters of the translation
+// API. Empty values are omitted except "target_lang" which is required.
+func (o *TranslateOptions) urlValues() url.Values {
+	urlVal := url.Values{}
+
+	urlVal.Set("target_lang", o.TargetLang)
+
+	addIfNotEmpty(urlVal, "source_lang", o.SourceLang)
+	addIfNotEmpty(urlVal, "context", o.Context)
+	addIfNotEmpty(urlVal, "formality", string(o.Formality))
+	addIfNotEmpty(urlVal, "split_sentences", string(o.SplitSentences))
+	addIfNotEmpty(urlVal, "model_type", string(o.ModelType))
+	addIfNotEmpty(urlVal, "glossary_id", o.GlossaryID)
+
+	if o.PreserveFormatting {
+		urlVal.Set("preserve_formatting", "1")
+	}
+
+	if o.ShowBilledCharacters {
+		urlVal.Set("show_billed_characters", "1")
+	}
+
+	addIfNotEmpty(urlVal, "tag_handling", string(o.TagHandling))
+	addIfNotEmpty(urlVal, "non_splitting_tags", o.NonSplittingTags.String())
+	addIfNotEmpty(urlVal, "splitting_tags", o.SplittingTags.String())
+	addIfNotEmpty(urlVal, "ignore_tags", o.IgnoreTags.String())
+
+	if o.DisableOutlineDetection {
+		urlVal.Set("outline_detection", "0")
+	}
+
+	return urlVal
+}
+
+// addIfNotEmpty adds the value to the key only if the value is not empty.
+func addIfNotEmpty(urlVal url.Values, key, value string) {
+	if value != "" {
+		urlVal.Add(key, value)
+	}
+}
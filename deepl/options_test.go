@@ -0,0 +1,228 @@
+package deepl
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  TranslateOptions.Validate
+// ----------------------------------------------------------------------------
+
+//nolint:varnamelen // tt is a test case by habit
+func TestTranslateOptions_Validate(t *testing.T) {
+	t.Parallel()
+
+	for index, tt := range dataTranslateOptionsValidate {
+		tt := tt
+		nameTest := fmt.Sprintf("test #%d: %s", index+1, tt.name)
+
+		t.Run(nameTest, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.input.Validate()
+
+			if tt.expectErrMessage == "" {
+				require.NoError(t, err, "valid options should not return an error")
+
+				return
+			}
+
+			require.Error(t, err, "invalid options should return an error")
+			assert.Contains(t, err.Error(), tt.expectErrMessage,
+				"it should contain the error reason")
+		})
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  TranslateOptions.urlValues
+// ----------------------------------------------------------------------------
+
+func TestTranslateOptions_urlValues(t *testing.T) {
+	t.Parallel()
+
+	t.Run("zero value", func(t *testing.T) {
+		t.Parallel()
+
+		opts := new(TranslateOptions)
+
+		require.Equal(t, "target_lang=", opts.urlValues().Encode(),
+			"zero value should only contain the required target_lang")
+	})
+
+	t.Run("tag handling options", func(t *testing.T) {
+		t.Parallel()
+
+		opts := &TranslateOptions{
+			TargetLang:              "DE",
+			TagHandling:             TagHandlingXML,
+			NonSplittingTags:        NewTags("b", "i"),
+			SplittingTags:           NewTags("par"),
+			IgnoreTags:              NewTags("code", "kbd"),
+			DisableOutlineDetection: true,
+		}
+
+		expect := "ignore_tags=code%2Ckbd&non_splitting_tags=b%2Ci&outline_detection=0" +
+			"&splitting_tags=par&tag_handling=xml&target_lang=DE"
+
+		require.Equal(t, expect, opts.urlValues().Encode(),
+			"tag lists should be comma-separated")
+	})
+
+	t.Run("all options", func(t *testing.T) {
+		t.Parallel()
+
+		opts := &TranslateOptions{
+			SourceLang:           "EN",
+			TargetLang:           "DE",
+			Context:              "greeting",
+			Formality:            FormalityPreferMore,
+			GlossaryID:           "abc",
+			SplitSentences:       SplitSentencesNoNewlines,
+			ModelType:            ModelTypeQualityOptimized,
+			PreserveFormatting:   true,
+			ShowBilledCharacters: true,
+		}
+
+		expect := "context=greeting&formality=prefer_more&glossary_id=abc&model_type=quality_optimized" +
+			"&preserve_formatting=1&show_billed_characters=1&source_lang=EN" +
+			"&split_sentences=nonewlines&target_lang=DE"
+
+		require.Equal(t, expect, opts.urlValues().Encode(),
+			"all the options should be serialized")
+	})
+}
+
+// ----------------------------------------------------------------------------
+//  Tags
+// ----------------------------------------------------------------------------
+
+func TestTags(t *testing.T) {
+	t.Parallel()
+
+	tags := NewTags("par", "title")
+
+	require.Equal(t, Tags{"par", "title"}, tags,
+		"NewTags should keep the order of the given names")
+	require.Equal(t, "par,title", tags.String(),
+		"tags should be a comma-separated list")
+	require.Empty(t, NewTags().String(),
+		"empty tags should be an empty string")
+	require.NoError(t, tags.Validate(),
+		"valid tag names should not return an error")
+}
+
+func TestTags_Validate_bad_name(t *testing.T) {
+	t.Parallel()
+
+	err := NewTags("par", "").Validate()
+
+	require.Error(t, err, "empty tag name should return an error")
+	assert.Contains(t, err.Error(), "tag name #2 is empty",
+		"it should contain the error reason")
+
+	err = NewTags("par,title").Validate()
+
+	require.Error(t, err, "tag name with comma should return an error")
+	assert.Contains(t, err.Error(), "tag name #1 contains a comma or white space",
+		"it should contain the error reason")
+}
+
+// ============================================================================
+//  Data Providers
+// ============================================================================
+
+// dataTranslateOptionsValidate is a data provider for TestTranslateOptions_Validate.
+var dataTranslateOptionsValidate = []struct {
+	name             string
+	input            TranslateOptions
+	expectErrMessage string
+}{
+	{
+		name:  "zero value",
+		input: TranslateOptions{},
+	},
+	{
+		name: "valid enums",
+		input: TranslateOptions{
+			Formality:      FormalityLess,
+			SplitSentences: SplitSentencesOff,
+			ModelType:      ModelTypeLatencyOptimized,
+		},
+	},
+	{
+		name:             "unsupported formality",
+		input:            TranslateOptions{Formality: "polite"},
+		expectErrMessage: `unsupported formality: "polite"`,
+	},
+	{
+		name:             "unsupported split_sentences",
+		input:            TranslateOptions{SplitSentences: "2"},
+		expectErrMessage: `unsupported split_sentences: "2"`,
+	},
+	{
+		name:             "unsupported model_type",
+		input:            TranslateOptions{ModelType: "fast"},
+		expectErrMessage: `unsupported model_type: "fast"`,
+	},
+	{
+		name:  "glossary with source_lang",
+		input: TranslateOptions{SourceLang: "EN", GlossaryID: "abc"},
+	},
+	{
+		name:             "glossary without source_lang",
+		input:            TranslateOptions{GlossaryID: "abc"},
+		expectErrMessage: "source_lang is required when glossary_id is set",
+	},
+	{
+		name: "valid tag handling",
+		input: TranslateOptions{
+			TagHandling:             TagHandlingHTML,
+			IgnoreTags:              NewTags("code"),
+			DisableOutlineDetection: true,
+		},
+	},
+	{
+		name:             "unsupported tag_handling",
+		input:            TranslateOptions{TagHandling: "json"},
+		expectErrMessage: `unsupported tag_handling: "json"`,
+	},
+	{
+		name:             "tag options without tag_handling",
+		input:            TranslateOptions{IgnoreTags: NewTags("code")},
+		expectErrMessage: "tag handling options are set but tag_handling is empty",
+	},
+	{
+		name:             "outline detection without tag_handling",
+		input:            TranslateOptions{DisableOutlineDetection: true},
+		expectErrMessage: "tag handling options are set but tag_handling is empty",
+	},
+	{
+		name: "bad non_splitting_tags",
+		input: TranslateOptions{
+			TagHandling:      TagHandlingXML,
+			NonSplittingTags: NewTags(""),
+		},
+		expectErrMessage: "invalid non_splitting_tags",
+	},
+	{
+		name: "bad splitting_tags",
+		input: TranslateOptions{
+			TagHandling:   TagHandlingXML,
+			SplittingTags: NewTags("a b"),
+		},
+		expectErrMessage: "invalid splitting_tags",
+	},
+	{
+		name: "bad ignore_tags",
+		input: TranslateOptions{
+			TagHandling: TagHandlingXML,
+			IgnoreTags:  NewTags("x,y"),
+		},
+		expectErrMessage: "invalid ignore_tags",
+	},
+}
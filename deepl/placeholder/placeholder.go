@@ -0,0 +1,252 @@
+/*
+Package placeholder protects the parts of the texts, such as the interpolation
+placeholders, from the translation.
+
+The protected parts are replaced with empty XML elements and the rest of the
+text is XML-escaped. The texts must be translated with deepl.TagHandlingXML,
+which keeps the elements untouched while the word order may change.
+
+	masker := placeholder.NewMasker(placeholder.Braces)
+
+	masked, tokens := masker.Mask("Hello, {name}!") // "Hello, <x id=\"0\"/>!"
+
+	opts.TagHandling = deepl.TagHandlingXML
+	// translate the masked text ...
+
+	text, err := placeholder.Unmask(translated, tokens) // "Hallo, {name}!"
+
+Translate does the translation and the restoration of the masked texts with a
+deepl.Translator in one step.
+*/
+package placeholder
+
+import (
+	"context"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/KEINOS/go-deepl/deepl"
+)
+
+// Common patterns of the placeholders.
+var (
+	// Braces matches the placeholders in braces. Such as "{name}", "{0}" and
+	// "{{count}}" of i18next, vue-i18n and ICU simple arguments.
+	Braces = regexp.MustCompile(`\{\{[^{}]+\}\}|\{[^{}\s]+\}`)
+	// Printf matches the printf-style format directives of C, Go and gettext.
+	// Such as "%s", "%5.2f", "%1$s" and "%%". The space flag is not supported to
+	// avoid matching the prose. Such as "50% off".
+	Printf = regexp.MustCompile(`%(?:\d+\$)?[-+#0]*(?:\d+|\*)?(?:\.(?:\d+|\*))?[a-zA-Z%]`)
+)
+
+// elementPattern matches the elements of Mask. The translation may turn the
+// empty-element tag into a start and end tag pair.
+var elementPattern = regexp.MustCompile(`<x\s+id\s*=\s*"(\d+)"\s*(?:/>|>\s*</x>)`)
+
+// ----------------------------------------------------------------------------
+//  Type: Masker
+// ----------------------------------------------------------------------------
+
+// Masker replaces the parts of the texts that match the patterns with the XML
+// elements.
+type Masker struct {
+	pattern *regexp.Regexp
+}
+
+// NewMasker returns a new Masker of the patterns. If no patterns are given, only
+// the XML escaping is done.
+func NewMasker(patterns ...*regexp.Regexp) *Masker {
+	if len(patterns) == 0 {
+		return &Masker{}
+	}
+
+	sources := make([]string, len(patterns))
+	for index, pattern := range patterns {
+		sources[index] = "(?:" + pattern.String() + ")"
+	}
+
+	return &Masker{pattern: regexp.MustCompile(strings.Join(sources, "|"))}
+}
+
+// Mask returns the XML-escaped text with the protected parts replaced by the
+// elements and the protected parts in the order of the element IDs.
+func (m *Masker) Mask(text string) (string, []string) {
+	if m.pattern == nil {
+		return Escape(text), nil
+	}
+
+	var (
+		masked strings.Builder
+		tokens []string
+		last   int
+	)
+
+	for _, loc := range m.pattern.FindAllStringIndex(text, -1) {
+		masked.WriteString(Escape(text[last:loc[0]]))
+		masked.WriteString(`<x id="` + strconv.Itoa(len(tokens)) + `"/>`)
+
+		tokens = append(tokens, text[loc[0]:loc[1]])
+		last = loc[1]
+	}
+
+	masked.WriteString(Escape(text[last:]))
+
+	return masked.String(), tokens
+}
+
+// MaskBreaks is the same as Mask but the elements of the protected parts with
+// the line breaks are surrounded by the spaces to keep the words apart. Such as
+// the line breaks of the subtitles and the Markdown paragraphs. Use UnmaskBreaks
+// to restore the translation.
+func (m *Masker) MaskBreaks(text string) (string, []string) {
+	masked, tokens := m.Mask(text)
+
+	for index, token := range tokens {
+		if strings.Contains(token, "\n") {
+			element := `<x id="` + strconv.Itoa(index) + `"/>`
+			masked = strings.Replace(masked, element, " "+element+" ", 1)
+		}
+	}
+
+	return masked, tokens
+}
+
+// ----------------------------------------------------------------------------
+//  Functions
+// ----------------------------------------------------------------------------
+
+// Escape returns the text with the XML special characters of the text nodes
+// escaped.
+func Escape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// Unmask returns the translated text with the elements replaced by the tokens
+// and the XML escapes resolved. It returns an error if any of the tokens is lost
+// in the translation or an unknown element is found.
+func Unmask(translated string, tokens []string) (string, error) {
+	var (
+		result strings.Builder
+		found  = make([]bool, len(tokens))
+		last   int
+	)
+
+	for _, loc := range elementPattern.FindAllStringSubmatchIndex(translated, -1) {
+		index, err := strconv.Atoi(translated[loc[2]:loc[3]])
+		if err != nil || index >= len(tokens) {
+			return "", deepl.NewErr("unknown placeholder in the translation: %s", translated[loc[0]:loc[1]])
+		}
+
+		result.WriteString(html.UnescapeString(translated[last:loc[0]]))
+		result.WriteString(tokens[index])
+
+		found[index] = true
+		last = loc[1]
+	}
+
+	result.WriteString(html.UnescapeString(translated[last:]))
+
+	for index, ok := range found {
+		if !ok {
+			return "", deepl.NewErr("placeholder %q is lost in the translation: %s", tokens[index], translated)
+		}
+	}
+
+	return result.String(), nil
+}
+
+// Translate translates the texts masked by Mask or MaskBreaks with the
+// translator and returns the translations with the protected parts restored by
+// the tokens of each text. TagHandling of the options is set to XML to keep the
+// elements. It fails if the number of the translations mismatches or any of the
+// protected parts is lost.
+//
+// If the tokens are nil, the texts are not masked but XML by themselves. Such as
+// the inner XML of the XLIFF units. The translations are returned as is then.
+func Translate(
+	ctx context.Context,
+	translator deepl.Translator,
+	texts []string,
+	tokens [][]string,
+	opts deepl.TranslateOptions,
+) ([]string, error) {
+	if tokens != nil && len(tokens) != len(texts) {
+		return nil, deepl.NewErr("number of tokens mismatch. Texts: %d, Tokens: %d", len(texts), len(tokens))
+	}
+
+	opts.TagHandling = deepl.TagHandlingXML
+
+	resp, err := translator.Translate(ctx, texts, &opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.Translations) != len(texts) {
+		return nil, deepl.NewErr("number of translations mismatch. Requested: %d, Returned: %d",
+			len(texts), len(resp.Translations))
+	}
+
+	translated := make([]string, len(texts))
+
+	for index, trans := range resp.Translations {
+		if tokens == nil {
+			translated[index] = trans.Text
+
+			continue
+		}
+
+		text, err := UnmaskBreaks(trans.Text, tokens[index])
+		if err != nil {
+			// The masked text is restored to tell which one
+			source, _ := UnmaskBreaks(texts[index], tokens[index])
+
+			return nil, deepl.WrapIfErr(err, "failed to restore the protected parts of %q", source)
+		}
+
+		translated[index] = text
+	}
+
+	return translated, nil
+}
+
+// UnmaskBreaks is the same as Unmask but the spaces around the elements of the
+// protected parts with the line breaks are removed. Which are added by
+// MaskBreaks.
+func UnmaskBreaks(translated string, tokens []string) (string, error) {
+	var (
+		result   strings.Builder
+		trimNext bool
+		last     int
+	)
+
+	for _, loc := range elementPattern.FindAllStringSubmatchIndex(translated, -1) {
+		index, err := strconv.Atoi(translated[loc[2]:loc[3]])
+		isBreak := err == nil && index < len(tokens) && strings.Contains(tokens[index], "\n")
+
+		before := translated[last:loc[0]]
+		if trimNext {
+			before = strings.TrimLeft(before, " \t")
+		}
+
+		if isBreak {
+			before = strings.TrimRight(before, " \t")
+		}
+
+		result.WriteString(before)
+		result.WriteString(translated[loc[0]:loc[1]])
+
+		trimNext = isBreak
+		last = loc[1]
+	}
+
+	after := translated[last:]
+	if trimNext {
+		after = strings.TrimLeft(after, " \t")
+	}
+
+	result.WriteString(after)
+
+	return Unmask(result.String(), tokens)
+}
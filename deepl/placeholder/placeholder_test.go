@@ -0,0 +1,185 @@
+package placeholder_test
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/KEINOS/go-deepl/deepl"
+	"github.com/KEINOS/go-deepl/deepl/deepltest"
+	"github.com/KEINOS/go-deepl/deepl/placeholder"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMasker_Mask(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		masker         *placeholder.Masker
+		input          string
+		expectedMasked string
+		expectedTokens []string
+	}{
+		{
+			masker:         placeholder.NewMasker(placeholder.Braces),
+			input:          "Hello, {name}! You have {{ count }} <new> messages & more",
+			expectedMasked: `Hello, <x id="0"/>! You have <x id="1"/> &lt;new&gt; messages &amp; more`,
+			expectedTokens: []string{"{name}", "{{ count }}"},
+		},
+		{
+			masker:         placeholder.NewMasker(placeholder.Printf),
+			input:          "%d files (%.1f%%) of %1$s. 50% off",
+			expectedMasked: `<x id="0"/> files (<x id="1"/><x id="2"/>) of <x id="3"/>. 50% off`,
+			expectedTokens: []string{"%d", "%.1f", "%%", "%1$s"},
+		},
+		{
+			masker:         placeholder.NewMasker(placeholder.Braces, placeholder.Printf),
+			input:          "{user} has %d items",
+			expectedMasked: `<x id="0"/> has <x id="1"/> items`,
+			expectedTokens: []string{"{user}", "%d"},
+		},
+		{
+			masker:         placeholder.NewMasker(),
+			input:          "{name} <b>",
+			expectedMasked: "{name} &lt;b&gt;",
+		},
+	} {
+		masked, tokens := test.masker.Mask(test.input)
+
+		require.Equal(t, test.expectedMasked, masked, "input: %s", test.input)
+		require.Equal(t, test.expectedTokens, tokens, "input: %s", test.input)
+
+		// The word order may change in the translation
+		unmasked, err := placeholder.Unmask(masked, tokens)
+
+		require.NoError(t, err)
+		require.Equal(t, test.input, unmasked, "unmask should restore the original")
+	}
+}
+
+func TestMasker_MaskBreaks(t *testing.T) {
+	t.Parallel()
+
+	masker := placeholder.NewMasker(regexp.MustCompile(`[ \t]*\n[ \t]*`), placeholder.Braces)
+
+	masked, tokens := masker.MaskBreaks("Hello,\n{name}!")
+
+	require.Equal(t, `Hello, <x id="0"/> <x id="1"/>!`, masked, "line breaks should be spaced")
+	require.Equal(t, []string{"\n", "{name}"}, tokens)
+
+	// The spaces are changed in the translation
+	unmasked, err := placeholder.UnmaskBreaks("Hallo,  <x id=\"0\"/>\t<x id=\"1\"/> !", tokens)
+
+	require.NoError(t, err)
+	require.Equal(t, "Hallo,\n{name} !", unmasked, "only the spaces around the line breaks should be removed")
+
+	unmasked, err = placeholder.UnmaskBreaks(`<x id="1"/> <x id="0"/> Hallo`, tokens)
+
+	require.NoError(t, err)
+	require.Equal(t, "{name}\nHallo", unmasked, "spaces between the adjacent elements should be removed")
+
+	_, err = placeholder.UnmaskBreaks(`Hallo <x id="1"/> <x id="2"/>`, tokens)
+	require.Error(t, err, "lost and unknown placeholders should be an error")
+}
+
+func TestUnmask(t *testing.T) {
+	t.Parallel()
+
+	tokens := []string{"{name}", "{{count}}"}
+
+	// reordered and the element written as start and end tags
+	unmasked, err := placeholder.Unmask(`<x id="1"></x> Nachrichten für <x id="0" /> &amp; &quot;mehr&quot;`, tokens)
+
+	require.NoError(t, err)
+	require.Equal(t, `{{count}} Nachrichten für {name} & "mehr"`, unmasked)
+}
+
+func TestUnmask_errors(t *testing.T) {
+	t.Parallel()
+
+	tokens := []string{"{name}", "{{count}}"}
+
+	_, err := placeholder.Unmask(`Hallo <x id="0"/>`, tokens)
+	require.Error(t, err, "lost placeholder should be an error")
+	require.Contains(t, err.Error(), `placeholder "{{count}}" is lost`)
+
+	_, err = placeholder.Unmask(`Hallo <x id="0"/> <x id="1"/> <x id="2"/>`, tokens)
+	require.Error(t, err, "unknown placeholder should be an error")
+	require.Contains(t, err.Error(), "unknown placeholder")
+}
+
+func TestTranslate(t *testing.T) {
+	t.Parallel()
+
+	masker := placeholder.NewMasker(placeholder.Braces)
+	masked, tokens := masker.Mask("Hello, {name}!")
+	fake := deepltest.NewFakeTranslator()
+	opts := deepl.TranslateOptions{TargetLang: "DE", TagHandling: deepl.TagHandlingHTML}
+
+	translated, err := placeholder.Translate(context.Background(), fake, []string{masked}, [][]string{tokens}, opts)
+	require.NoError(t, err)
+	require.Equal(t, []string{"[DE] Hello, {name}!"}, translated)
+	require.Equal(t, deepl.TagHandlingXML, fake.Calls()[0].Options.TagHandling, "tag handling should be XML")
+	require.Equal(t, deepl.TagHandlingHTML, opts.TagHandling, "options of the caller should not be changed")
+
+	// The texts without the tokens are returned as translated
+	translated, err = placeholder.Translate(context.Background(), fake, []string{"<b>Hi</b>"}, nil, opts)
+	require.NoError(t, err)
+	require.Equal(t, []string{"[DE] <b>Hi</b>"}, translated)
+}
+
+func TestTranslate_errors(t *testing.T) {
+	t.Parallel()
+
+	texts := []string{`Hello, <x id="0"/>!`}
+	tokens := [][]string{{"{name}"}}
+	opts := deepl.TranslateOptions{TargetLang: "DE"}
+	fake := deepltest.NewFakeTranslator()
+
+	_, err := placeholder.Translate(context.Background(), fake, texts, [][]string{}, opts)
+	require.Error(t, err, "tokens not aligned with the texts should be an error")
+	require.Empty(t, fake.Calls(), "nothing should be requested on the invalid arguments")
+
+	fake.TranslateFunc = func(text string, _ *deepl.TranslateOptions) string {
+		return strings.ReplaceAll(text, `<x id="0"/>`, "")
+	}
+
+	_, err = placeholder.Translate(context.Background(), fake, texts, tokens, opts)
+	require.Error(t, err, "lost placeholder should be an error")
+	require.Contains(t, err.Error(), `"Hello, {name}!"`, "error should tell the source text")
+
+	_, err = placeholder.Translate(context.Background(), shortTranslator{fake}, texts, tokens, opts)
+	require.Error(t, err, "missing translations should be an error")
+	require.Contains(t, err.Error(), "Requested: 1, Returned: 0")
+
+	fake.Err = deepl.ErrQuotaExceeded
+
+	_, err = placeholder.Translate(context.Background(), fake, texts, tokens, opts)
+	require.ErrorIs(t, err, deepl.ErrQuotaExceeded)
+}
+
+// ----------------------------------------------------------------------------
+//  Helper Types
+// ----------------------------------------------------------------------------
+
+// shortTranslator is a deepl.Translator that drops the last translation of the
+// embedded one.
+type shortTranslator struct {
+	*deepltest.FakeTranslator
+}
+
+func (t shortTranslator) Translate(
+	ctx context.Context,
+	texts []string,
+	opts *deepl.TranslateOptions,
+) (*deepl.TranslateResponse, error) {
+	resp, err := t.FakeTranslator.Translate(ctx, texts, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Translations = resp.Translations[:len(resp.Translations)-1]
+
+	return resp, nil
+}
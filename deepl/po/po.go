@@ -0,0 +1,572 @@
+/*
+Package po reads and writes the gettext PO and POT files and fills the
+untranslated entries with DeepL.
+
+	file, err := po.ParseFile("locale/ja/LC_MESSAGES/messages.po")
+
+	result, err := po.Translate(ctx, cli, file, po.Options{
+		TranslateOptions: deepl.TranslateOptions{SourceLang: "EN", TargetLang: "JA"},
+	})
+
+	err = file.WriteFile("locale/ja/LC_MESSAGES/messages.po")
+
+The entries with translations are never overwritten. The machine translations
+are marked with the "fuzzy" flag to be reviewed by humans.
+
+The strings are written without wrapping. Which is the same as "msgcat
+--no-wrap" of the gettext tools.
+*/
+package po
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/KEINOS/go-deepl/deepl"
+)
+
+// FlagFuzzy is the flag of the entries to be reviewed.
+const FlagFuzzy = "fuzzy"
+
+// patternNPlurals matches the number of the plural forms in the header.
+var patternNPlurals = regexp.MustCompile(`nplurals\s*=\s*(\d+)`)
+
+// ----------------------------------------------------------------------------
+//  Type: Entry
+// ----------------------------------------------------------------------------
+
+// Entry is a message of the PO file.
+type Entry struct {
+	// Context is the value of "msgctxt". Which is valid if HasContext is true.
+	Context string
+	// ID is the value of "msgid". It is empty for the header.
+	ID string
+	// IDPlural is the value of "msgid_plural". The entry is a plural message if
+	// not empty.
+	IDPlural string
+	// Str is the value of "msgstr" of the singular message.
+	Str string
+	// StrPlural are the values of "msgstr[n]" of the plural message.
+	StrPlural []string
+	// TranslatorComments are the "# " comments by the translators.
+	TranslatorComments []string
+	// ExtractedComments are the "#." comments extracted from the source code.
+	ExtractedComments []string
+	// References are the "#:" references to the source code.
+	References []string
+	// Flags are the "#," flags. Such as "fuzzy" and "c-format".
+	Flags []string
+	// Previous are the "#|" lines of the previous message as is.
+	Previous []string
+	// Obsolete are the "#~" lines of the obsolete message as is. The entry is
+	// obsolete if not empty.
+	Obsolete []string
+	// HasContext is true if the entry has "msgctxt". Even if it is empty.
+	HasContext bool
+}
+
+// IsHeader returns true if the entry is the header. Which has the empty msgid
+// without the context.
+func (e *Entry) IsHeader() bool {
+	return e.ID == "" && !e.HasContext && len(e.Obsolete) == 0
+}
+
+// IsPlural returns true if the entry is a plural message.
+func (e *Entry) IsPlural() bool {
+	return e.IDPlural != ""
+}
+
+// IsObsolete returns true if the entry is an obsolete message.
+func (e *Entry) IsObsolete() bool {
+	return len(e.Obsolete) != 0
+}
+
+// IsTranslated returns true if any of the msgstr is not empty.
+func (e *Entry) IsTranslated() bool {
+	if e.Str != "" {
+		return true
+	}
+
+	for _, str := range e.StrPlural {
+		if str != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasFlag returns true if the entry has the flag.
+func (e *Entry) HasFlag(flag string) bool {
+	for _, f := range e.Flags {
+		if f == flag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AddFlag adds the flag if the entry does not have it.
+func (e *Entry) AddFlag(flag string) {
+	if !e.HasFlag(flag) {
+		e.Flags = append(e.Flags, flag)
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  Type: File
+// ----------------------------------------------------------------------------
+
+// File is a PO or POT file.
+type File struct {
+	Entries []*Entry
+}
+
+// Parse parses the PO or POT data.
+func Parse(data []byte) (*File, error) {
+	parser := &parser{file: new(File)}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+
+	for scanner.Scan() {
+		parser.lineNum++
+
+		if err := parser.parseLine(strings.TrimRight(scanner.Text(), "\r")); err != nil {
+			return nil, deepl.WrapIfErr(err, "failed to parse PO at line %d", parser.lineNum)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, deepl.WrapIfErr(err, "failed to read PO")
+	}
+
+	parser.flush()
+
+	return parser.file, nil
+}
+
+// ParseFile reads and parses the PO or POT file.
+func ParseFile(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, deepl.WrapIfErr(err, "failed to read PO file")
+	}
+
+	return Parse(data)
+}
+
+// Header returns the header entry. It returns nil if the file has no header.
+func (f *File) Header() *Entry {
+	for _, entry := range f.Entries {
+		if entry.IsHeader() {
+			return entry
+		}
+	}
+
+	return nil
+}
+
+// HeaderField returns the value of the field in the header. Such as "Language"
+// and "Plural-Forms". It returns an empty string if not found.
+func (f *File) HeaderField(name string) string {
+	header := f.Header()
+	if header == nil {
+		return ""
+	}
+
+	for _, line := range strings.Split(header.Str, "\n") {
+		key, value, ok := cutField(line)
+		if ok && strings.EqualFold(key, name) {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// SetHeaderField sets the value of the field in the header. The field is added
+// at the end if not found. The header is created if the file has no header.
+func (f *File) SetHeaderField(name string, value string) {
+	header := f.Header()
+	if header == nil {
+		header = new(Entry)
+		f.Entries = append([]*Entry{header}, f.Entries...)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(header.Str, "\n"), "\n")
+	if header.Str == "" {
+		lines = nil
+	}
+
+	found := false
+
+	for index, line := range lines {
+		if key, _, ok := cutField(line); ok && strings.EqualFold(key, name) {
+			lines[index] = key + ": " + value
+			found = true
+		}
+	}
+
+	if !found {
+		lines = append(lines, name+": "+value)
+	}
+
+	header.Str = strings.Join(lines, "\n") + "\n"
+}
+
+// NPlurals returns the number of the plural forms in the "Plural-Forms" header.
+// It returns zero if not set or invalid. Such as "nplurals=INTEGER" of POT.
+func (f *File) NPlurals() int {
+	match := patternNPlurals.FindStringSubmatch(f.HeaderField("Plural-Forms"))
+	if match == nil {
+		return 0
+	}
+
+	nplurals, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0
+	}
+
+	return nplurals
+}
+
+// Encode returns the PO data of the file.
+func (f *File) Encode() []byte {
+	var buf bytes.Buffer
+
+	for index, entry := range f.Entries {
+		if index > 0 {
+			buf.WriteByte('\n')
+		}
+
+		writeEntry(&buf, entry)
+	}
+
+	return buf.Bytes()
+}
+
+// WriteTo writes the PO data of the file to the writer. It implements
+// io.WriterTo.
+func (f *File) WriteTo(writer io.Writer) (int64, error) {
+	written, err := writer.Write(f.Encode())
+
+	return int64(written), deepl.WrapIfErr(err, "failed to write PO")
+}
+
+// WriteFile writes the PO data of the file to the path.
+func (f *File) WriteFile(path string) error {
+	return deepl.WrapIfErr(os.WriteFile(path, f.Encode(), 0o644), "failed to write PO file")
+}
+
+// ----------------------------------------------------------------------------
+//  Type: parser
+// ----------------------------------------------------------------------------
+
+// parser is the state of Parse.
+type parser struct {
+	file *File
+	// entry is the entry being parsed.
+	entry *Entry
+	// last is the pointer to the string of the last keyword to append the
+	// continued lines.
+	last    *string
+	lineNum int
+	// seenStr is true if the msgstr of the entry is parsed. The next comment or
+	// msgid starts a new entry.
+	seenStr bool
+}
+
+// parseLine parses a line of the PO data.
+func (p *parser) parseLine(line string) error {
+	trimmed := strings.TrimSpace(line)
+
+	switch {
+	case trimmed == "":
+		p.flush()
+
+		return nil
+	case strings.HasPrefix(trimmed, "#~"):
+		entry := p.current(true)
+		entry.Obsolete = append(entry.Obsolete, line)
+		p.last = nil
+
+		return nil
+	case strings.HasPrefix(trimmed, "#"):
+		p.parseComment(trimmed)
+
+		return nil
+	case strings.HasPrefix(trimmed, `"`):
+		if p.last == nil {
+			return deepl.NewErr("string without keyword: %s", trimmed)
+		}
+
+		value, err := unquote(trimmed)
+		if err != nil {
+			return err
+		}
+
+		*p.last += value
+
+		return nil
+	}
+
+	keyword, rest := trimmed, ""
+	if index := strings.IndexAny(trimmed, " \t"); index >= 0 {
+		keyword, rest = trimmed[:index], trimmed[index+1:]
+	}
+
+	value, err := unquote(strings.TrimSpace(rest))
+	if err != nil {
+		return err
+	}
+
+	return p.parseKeyword(keyword, value)
+}
+
+// parseComment parses the comment line.
+func (p *parser) parseComment(line string) {
+	entry := p.current(true)
+	p.last = nil
+
+	if len(line) < 2 {
+		entry.TranslatorComments = append(entry.TranslatorComments, "")
+
+		return
+	}
+
+	content := strings.TrimSpace(line[2:])
+
+	switch line[1] {
+	case '.':
+		entry.ExtractedComments = append(entry.ExtractedComments, content)
+	case ':':
+		entry.References = append(entry.References, content)
+	case ',':
+		for _, flag := range strings.Split(content, ",") {
+			if flag = strings.TrimSpace(flag); flag != "" {
+				entry.Flags = append(entry.Flags, flag)
+			}
+		}
+	case '|':
+		entry.Previous = append(entry.Previous, content)
+	default:
+		entry.TranslatorComments = append(entry.TranslatorComments, strings.TrimSpace(line[1:]))
+	}
+}
+
+// parseKeyword parses the keyword line with the value.
+func (p *parser) parseKeyword(keyword string, value string) error {
+	switch {
+	case keyword == "msgctxt":
+		entry := p.current(true)
+		entry.Context = value
+		entry.HasContext = true
+		p.last = &entry.Context
+	case keyword == "msgid":
+		entry := p.current(true)
+		entry.ID = value
+		p.last = &entry.ID
+	case keyword == "msgid_plural":
+		entry := p.current(false)
+		entry.IDPlural = value
+		p.last = &entry.IDPlural
+	case keyword == "msgstr":
+		entry := p.current(false)
+		entry.Str = value
+		p.last = &entry.Str
+		p.seenStr = true
+	case strings.HasPrefix(keyword, "msgstr[") && strings.HasSuffix(keyword, "]"):
+		index, err := strconv.Atoi(keyword[len("msgstr[") : len(keyword)-1])
+		if err != nil || index < 0 {
+			return deepl.NewErr("invalid plural index: %s", keyword)
+		}
+
+		entry := p.current(false)
+		for len(entry.StrPlural) <= index {
+			entry.StrPlural = append(entry.StrPlural, "")
+		}
+
+		entry.StrPlural[index] = value
+		p.last = &entry.StrPlural[index]
+		p.seenStr = true
+	default:
+		return deepl.NewErr("unknown keyword: %s", keyword)
+	}
+
+	return nil
+}
+
+// current returns the entry being parsed. If starting is true, the line may
+// start a new entry. Such as the comments and msgctxt after msgstr.
+func (p *parser) current(starting bool) *Entry {
+	if p.entry != nil && starting && p.seenStr {
+		p.flush()
+	}
+
+	if p.entry == nil {
+		p.entry = new(Entry)
+	}
+
+	return p.entry
+}
+
+// flush appends the entry being parsed to the file.
+func (p *parser) flush() {
+	if p.entry != nil {
+		p.file.Entries = append(p.file.Entries, p.entry)
+	}
+
+	p.entry = nil
+	p.last = nil
+	p.seenStr = false
+}
+
+// ----------------------------------------------------------------------------
+//  Private Functions
+// ----------------------------------------------------------------------------
+
+// cutField returns the name and the value of the header line "Name: value".
+func cutField(line string) (string, string, bool) {
+	index := strings.Index(line, ":")
+	if index < 0 {
+		return "", "", false
+	}
+
+	return strings.TrimSpace(line[:index]), strings.TrimSpace(line[index+1:]), true
+}
+
+// quote returns the PO string literal of the text.
+func quote(text string) string {
+	return `"` + strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"\n", `\n`,
+		"\t", `\t`,
+		"\r", `\r`,
+	).Replace(text) + `"`
+}
+
+// unquote returns the text of the PO string literal.
+func unquote(literal string) (string, error) {
+	if len(literal) < 2 || literal[0] != '"' || literal[len(literal)-1] != '"' {
+		return "", deepl.NewErr("invalid string: %s", literal)
+	}
+
+	var result strings.Builder
+
+	body := literal[1 : len(literal)-1]
+
+	for index := 0; index < len(body); index++ {
+		char := body[index]
+		if char != '\\' {
+			result.WriteByte(char)
+
+			continue
+		}
+
+		index++
+		if index == len(body) {
+			return "", deepl.NewErr("invalid escape at the end of the string: %s", literal)
+		}
+
+		switch body[index] {
+		case 'n':
+			result.WriteByte('\n')
+		case 't':
+			result.WriteByte('\t')
+		case 'r':
+			result.WriteByte('\r')
+		case 'a':
+			result.WriteByte('\a')
+		case 'b':
+			result.WriteByte('\b')
+		case 'f':
+			result.WriteByte('\f')
+		case 'v':
+			result.WriteByte('\v')
+		default: // such as \\ and \"
+			result.WriteByte(body[index])
+		}
+	}
+
+	return result.String(), nil
+}
+
+// writeEntry writes the entry to the buf.
+func writeEntry(buf *bytes.Buffer, entry *Entry) {
+	for _, comment := range entry.TranslatorComments {
+		buf.WriteString(strings.TrimRight("# "+comment, " ") + "\n")
+	}
+
+	for _, comment := range entry.ExtractedComments {
+		buf.WriteString("#. " + comment + "\n")
+	}
+
+	for _, reference := range entry.References {
+		buf.WriteString("#: " + reference + "\n")
+	}
+
+	if len(entry.Flags) != 0 {
+		buf.WriteString("#, " + strings.Join(entry.Flags, ", ") + "\n")
+	}
+
+	for _, previous := range entry.Previous {
+		buf.WriteString("#| " + previous + "\n")
+	}
+
+	if entry.IsObsolete() {
+		for _, line := range entry.Obsolete {
+			buf.WriteString(line + "\n")
+		}
+
+		return
+	}
+
+	if entry.HasContext {
+		writeString(buf, "msgctxt", entry.Context)
+	}
+
+	writeString(buf, "msgid", entry.ID)
+
+	if !entry.IsPlural() {
+		writeString(buf, "msgstr", entry.Str)
+
+		return
+	}
+
+	writeString(buf, "msgid_plural", entry.IDPlural)
+
+	strPlural := entry.StrPlural
+	if len(strPlural) == 0 {
+		strPlural = []string{"", ""}
+	}
+
+	for index, str := range strPlural {
+		writeString(buf, "msgstr["+strconv.Itoa(index)+"]", str)
+	}
+}
+
+// writeString writes the keyword line of the text. The multi-line text is split
+// into the lines after the newlines as the gettext tools do.
+func writeString(buf *bytes.Buffer, keyword string, text string) {
+	if !strings.Contains(strings.TrimSuffix(text, "\n"), "\n") {
+		buf.WriteString(keyword + " " + quote(text) + "\n")
+
+		return
+	}
+
+	buf.WriteString(keyword + ` ""` + "\n")
+
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if line != "" {
+			buf.WriteString(quote(line) + "\n")
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package po_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/KEINOS/go-deepl/deepl/po"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFile(t *testing.T) {
+	t.Parallel()
+
+	file, err := po.ParseFile(filepath.Join("testdata", "messages.pot"))
+	require.NoError(t, err)
+	require.Len(t, file.Entries, 7)
+
+	header := file.Header()
+	require.NotNil(t, header)
+	require.True(t, header.HasFlag(po.FlagFuzzy))
+	require.Equal(t, "sample 1.0", file.HeaderField("Project-Id-Version"))
+	require.Empty(t, file.HeaderField("Language"))
+	require.Zero(t, file.NPlurals(), "placeholder of POT should be invalid")
+
+	hello := file.Entries[1]
+	require.Equal(t, "Hello, %s!", hello.ID)
+	require.Equal(t, []string{"TRANSLATORS: greeting on the top page"}, hello.ExtractedComments)
+	require.Equal(t, []string{"main.c:10"}, hello.References)
+	require.Equal(t, []string{"c-format"}, hello.Flags)
+	require.False(t, hello.IsTranslated())
+
+	open := file.Entries[2]
+	require.True(t, open.HasContext)
+	require.Equal(t, "menu", open.Context)
+
+	require.True(t, file.Entries[3].IsTranslated())
+
+	plural := file.Entries[4]
+	require.True(t, plural.IsPlural())
+	require.Equal(t, "%d files", plural.IDPlural)
+	require.Equal(t, []string{"", ""}, plural.StrPlural)
+
+	require.Equal(t, "First line\nSecond line \"quoted\"\n", file.Entries[5].ID)
+
+	obsolete := file.Entries[6]
+	require.True(t, obsolete.IsObsolete())
+	require.False(t, obsolete.IsHeader())
+}
+
+func TestFile_Encode_round_trip(t *testing.T) {
+	t.Parallel()
+
+	data, err := os.ReadFile(filepath.Join("testdata", "messages.pot"))
+	require.NoError(t, err)
+
+	file, err := po.Parse(data)
+	require.NoError(t, err)
+
+	require.Equal(t, string(data), string(file.Encode()))
+
+	var buf bytes.Buffer
+
+	written, err := file.WriteTo(&buf)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(data)), written)
+}
+
+func TestParse_without_blank_lines(t *testing.T) {
+	t.Parallel()
+
+	file, err := po.Parse([]byte(
+		"msgid \"one\"\nmsgstr \"eins\"\n# comment\nmsgctxt \"ctx\"\nmsgid \"two\"\nmsgstr \"\"\n",
+	))
+	require.NoError(t, err)
+	require.Len(t, file.Entries, 2)
+	require.Equal(t, "eins", file.Entries[0].Str)
+	require.Equal(t, []string{"comment"}, file.Entries[1].TranslatorComments)
+	require.Equal(t, "ctx", file.Entries[1].Context)
+	require.Equal(t, "two", file.Entries[1].ID)
+}
+
+func TestParse_errors(t *testing.T) {
+	t.Parallel()
+
+	for _, input := range []string{
+		`msgid Hello`,
+		`msgid "Hello`,
+		`"orphan string"`,
+		`msgfoo "Hello"`,
+		`msgstr[x] "Hello"`,
+		`msgid "trailing escape\"`,
+	} {
+		_, err := po.Parse([]byte(input))
+		require.Error(t, err, "input: %s", input)
+	}
+
+	_, err := po.ParseFile(filepath.Join(t.TempDir(), "missing.po"))
+	require.Error(t, err)
+}
+
+func TestFile_SetHeaderField(t *testing.T) {
+	t.Parallel()
+
+	file := new(po.File)
+
+	file.SetHeaderField("Language", "ja")
+	file.SetHeaderField("Plural-Forms", "nplurals=1; plural=0;")
+	file.SetHeaderField("language", "de")
+
+	require.Equal(t, "de", file.HeaderField("Language"))
+	require.Equal(t, 1, file.NPlurals())
+	require.Equal(t, "msgid \"\"\nmsgstr \"\"\n\"Language: de\\n\"\n\"Plural-Forms: nplurals=1; plural=0;\\n\"\n",
+		string(file.Encode()))
+}
@@ -0,0 +1,365 @@
+package po
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/KEINOS/go-deepl/deepl"
+	"github.com/KEINOS/go-deepl/deepl/placeholder"
+)
+
+// DefaultPluralForms is the "Plural-Forms" header used for the target languages
+// not in the PluralForms table.
+const DefaultPluralForms = "nplurals=2; plural=(n != 1);"
+
+// defaultNPlurals is the number of the plural forms of DefaultPluralForms.
+const defaultNPlurals = 2
+
+// PluralForms are the "Plural-Forms" headers of the DeepL target languages by
+// the upper-case language codes. Such as "JA" and "PT-BR". The ones of the
+// regional variants fall back to the language. Such as "EN-US" to "EN".
+var PluralForms = map[string]string{
+	"AR":    "nplurals=6; plural=(n==0 ? 0 : n==1 ? 1 : n==2 ? 2 : n%100>=3 && n%100<=10 ? 3 : n%100>=11 ? 4 : 5);",
+	"BG":    DefaultPluralForms,
+	"CS":    "nplurals=3; plural=(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2;",
+	"DA":    DefaultPluralForms,
+	"DE":    DefaultPluralForms,
+	"EL":    DefaultPluralForms,
+	"EN":    DefaultPluralForms,
+	"ES":    DefaultPluralForms,
+	"ET":    DefaultPluralForms,
+	"FI":    DefaultPluralForms,
+	"FR":    "nplurals=2; plural=(n > 1);",
+	"HU":    DefaultPluralForms,
+	"ID":    "nplurals=1; plural=0;",
+	"IT":    DefaultPluralForms,
+	"JA":    "nplurals=1; plural=0;",
+	"KO":    "nplurals=1; plural=0;",
+	"LT":    "nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && (n%100<10 || n%100>=20) ? 1 : 2);",
+	"LV":    "nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n != 0 ? 1 : 2);",
+	"NB":    DefaultPluralForms,
+	"NL":    DefaultPluralForms,
+	"PL":    "nplurals=3; plural=(n==1 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);",
+	"PT":    DefaultPluralForms,
+	"PT-BR": "nplurals=2; plural=(n > 1);",
+	"RO":    "nplurals=3; plural=(n==1 ? 0 : (n==0 || (n%100 > 0 && n%100 < 20)) ? 1 : 2);",
+	"RU":    "nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);",
+	"SK":    "nplurals=3; plural=(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2;",
+	"SL":    "nplurals=4; plural=(n%100==1 ? 0 : n%100==2 ? 1 : n%100==3 || n%100==4 ? 2 : 3);",
+	"SV":    DefaultPluralForms,
+	"TR":    DefaultPluralForms,
+	"UK":    "nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);",
+	"ZH":    "nplurals=1; plural=0;",
+}
+
+// ----------------------------------------------------------------------------
+//  Type: Options
+// ----------------------------------------------------------------------------
+
+// Options are the options of Translate.
+type Options struct {
+	// TranslateOptions are the options of the translation. TargetLang is
+	// required. Context is prepended to the msgctxt and the comments of each
+	// entry. TagHandling is overridden to mask the directives of the format
+	// strings.
+	TranslateOptions deepl.TranslateOptions
+	// PluralForms is the "Plural-Forms" header to set if the file does not have
+	// a valid one. Such as POT files. Defaults to the one of the target language
+	// in the PluralForms table.
+	PluralForms string
+	// Placeholders are the patterns of the placeholders to keep untouched in the
+	// entries with the format flags. Such as "c-format" and "python-format".
+	// Defaults to placeholder.Printf and placeholder.Braces.
+	Placeholders []*regexp.Regexp
+}
+
+// Result is the result of Translate.
+type Result struct {
+	// Translated is the number of the entries translated.
+	Translated int
+	// Skipped is the number of the entries skipped since they are translated.
+	Skipped int
+}
+
+// ----------------------------------------------------------------------------
+//  Functions
+// ----------------------------------------------------------------------------
+
+// Translate fills the empty msgstr of the entries of the file in place with the
+// translator. The translated entries are marked with the "fuzzy" flag. The
+// entries with any translation, the obsolete entries and the header are never
+// modified, except the "Language" and "Plural-Forms" fields of the header are
+// set if missing.
+//
+// The msgctxt and the comments of the entry are sent as the context of the
+// translation. The entries with the same context are translated in a single
+// call of the translator.
+//
+// For the plural messages, msgstr[0] is translated from msgid and the others
+// from msgid_plural. If the target language has only one form, such as Japanese,
+// msgstr[0] is translated from msgid_plural.
+func Translate(ctx context.Context, translator deepl.Translator, file *File, opts Options) (*Result, error) {
+	if file == nil {
+		return nil, deepl.NewErr("PO file is nil")
+	}
+
+	if opts.TranslateOptions.TargetLang == "" {
+		return nil, deepl.NewErr("target language is empty")
+	}
+
+	nplurals := setupHeader(file, opts)
+	result := new(Result)
+
+	groups, order := groupEntries(file, result)
+	if len(order) == 0 {
+		return result, nil
+	}
+
+	patterns := opts.Placeholders
+	if patterns == nil {
+		patterns = []*regexp.Regexp{placeholder.Printf, placeholder.Braces}
+	}
+
+	masker := placeholder.NewMasker(patterns...)
+	plainMasker := placeholder.NewMasker()
+
+	for _, entryContext := range order {
+		entries := groups[entryContext]
+
+		transOpts := opts.TranslateOptions
+		transOpts.Context = strings.TrimSpace(transOpts.Context + "\n" + entryContext)
+
+		translated, err := translateEntries(ctx, translator, entries, transOpts, masker, plainMasker)
+		if err != nil {
+			return nil, err
+		}
+
+		for index, entry := range entries {
+			fill(entry, translated[index], nplurals)
+		}
+
+		result.Translated += len(entries)
+	}
+
+	return result, nil
+}
+
+// TranslateFile translates the PO or POT file and writes it to the target file.
+// To update an existing PO file from a newer POT file, merge them with the
+// "msgmerge" of the gettext tools first and translate the merged file.
+func TranslateFile(
+	ctx context.Context,
+	translator deepl.Translator,
+	sourcePath string,
+	targetPath string,
+	opts Options,
+) (*Result, error) {
+	file, err := ParseFile(sourcePath)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := Translate(ctx, translator, file, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := file.WriteFile(targetPath); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ----------------------------------------------------------------------------
+//  Private Functions
+// ----------------------------------------------------------------------------
+
+// contextOf returns the context of the entry for the translation. Which is the
+// msgctxt and the comments.
+func contextOf(entry *Entry) string {
+	parts := make([]string, 0, 1+len(entry.ExtractedComments)+len(entry.TranslatorComments))
+
+	if entry.Context != "" {
+		parts = append(parts, entry.Context)
+	}
+
+	for _, comment := range append(append([]string(nil), entry.ExtractedComments...), entry.TranslatorComments...) {
+		if comment = strings.TrimSpace(comment); comment != "" {
+			parts = append(parts, comment)
+		}
+	}
+
+	return strings.Join(parts, "\n")
+}
+
+// fill sets the translations of the msgid and the msgid_plural to the entry.
+func fill(entry *Entry, translated []string, nplurals int) {
+	entry.AddFlag(FlagFuzzy)
+
+	if !entry.IsPlural() {
+		entry.Str = translated[0]
+
+		return
+	}
+
+	entry.StrPlural = make([]string, nplurals)
+
+	if nplurals == 1 {
+		entry.StrPlural[0] = translated[1]
+
+		return
+	}
+
+	entry.StrPlural[0] = translated[0]
+	for index := 1; index < nplurals; index++ {
+		entry.StrPlural[index] = translated[1]
+	}
+}
+
+// groupEntries returns the untranslated entries grouped by the context and the
+// contexts in the order of appearance.
+func groupEntries(file *File, result *Result) (map[string][]*Entry, []string) {
+	groups := make(map[string][]*Entry)
+	order := []string{}
+
+	for _, entry := range file.Entries {
+		if entry.IsHeader() || entry.IsObsolete() || entry.ID == "" {
+			continue
+		}
+
+		if entry.IsTranslated() {
+			result.Skipped++
+
+			continue
+		}
+
+		entryContext := contextOf(entry)
+		if _, ok := groups[entryContext]; !ok {
+			order = append(order, entryContext)
+		}
+
+		groups[entryContext] = append(groups[entryContext], entry)
+	}
+
+	return groups, order
+}
+
+// hasFormatFlag returns true if the entry has any of the format flags. Such as
+// "c-format" but not "no-c-format".
+func hasFormatFlag(entry *Entry) bool {
+	for _, flag := range entry.Flags {
+		if strings.HasSuffix(flag, "-format") && !strings.HasPrefix(flag, "no-") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setupHeader sets the "Language" and "Plural-Forms" fields of the header if
+// missing and returns the number of the plural forms.
+func setupHeader(file *File, opts Options) int {
+	targetLang := strings.ToUpper(opts.TranslateOptions.TargetLang)
+
+	if file.HeaderField("Language") == "" {
+		// Such as "PT-BR" to "pt_BR"
+		lang := strings.ToLower(targetLang)
+		if index := strings.Index(lang, "-"); index >= 0 {
+			lang = lang[:index] + "_" + strings.ToUpper(lang[index+1:])
+		}
+
+		file.SetHeaderField("Language", lang)
+	}
+
+	if nplurals := file.NPlurals(); nplurals > 0 {
+		return nplurals
+	}
+
+	pluralForms := opts.PluralForms
+	if pluralForms == "" {
+		pluralForms = pluralFormsOf(targetLang)
+	}
+
+	file.SetHeaderField("Plural-Forms", pluralForms)
+
+	if nplurals := file.NPlurals(); nplurals > 0 {
+		return nplurals
+	}
+
+	return defaultNPlurals
+}
+
+// pluralFormsOf returns the "Plural-Forms" of the upper-case target language.
+func pluralFormsOf(targetLang string) string {
+	if pluralForms, ok := PluralForms[targetLang]; ok {
+		return pluralForms
+	}
+
+	if index := strings.Index(targetLang, "-"); index >= 0 {
+		if pluralForms, ok := PluralForms[targetLang[:index]]; ok {
+			return pluralForms
+		}
+	}
+
+	return DefaultPluralForms
+}
+
+// translateEntries returns the translations of the msgid and the msgid_plural of
+// the entries.
+func translateEntries(
+	ctx context.Context,
+	translator deepl.Translator,
+	entries []*Entry,
+	opts deepl.TranslateOptions,
+	masker *placeholder.Masker,
+	plainMasker *placeholder.Masker,
+) ([][]string, error) {
+	var (
+		texts  []string
+		tokens [][]string
+		owners []int
+		// affixes are the leading and trailing spaces, such as "\n", kept out of
+		// the translation. The gettext tools check them to be the same as msgid.
+		affixes [][2]string
+	)
+
+	for index, entry := range entries {
+		entryMasker := plainMasker
+		if hasFormatFlag(entry) {
+			entryMasker = masker
+		}
+
+		sources := []string{entry.ID}
+		if entry.IsPlural() {
+			sources = append(sources, entry.IDPlural)
+		}
+
+		for _, source := range sources {
+			core := strings.TrimSpace(source)
+			start := strings.Index(source, core)
+
+			masked, entryTokens := entryMasker.Mask(core)
+
+			texts = append(texts, masked)
+			tokens = append(tokens, entryTokens)
+			owners = append(owners, index)
+			affixes = append(affixes, [2]string{source[:start], source[start+len(core):]})
+		}
+	}
+
+	texts, err := placeholder.Translate(ctx, translator, texts, tokens, opts)
+	if err != nil {
+		return nil, deepl.WrapIfErr(err, "failed to translate PO entries")
+	}
+
+	translated := make([][]string, len(entries))
+
+	for index, text := range texts {
+		text = affixes[index][0] + strings.TrimSpace(text) + affixes[index][1]
+		translated[owners[index]] = append(translated[owners[index]], text)
+	}
+
+	return translated, nil
+}
@@ -0,0 +1,186 @@
+package po_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/KEINOS/go-deepl/deepl"
+	"github.com/KEINOS/go-deepl/deepl/deepltest"
+	"github.com/KEINOS/go-deepl/deepl/po"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslateFile(t *testing.T) {
+	t.Parallel()
+
+	pathTarget := filepath.Join(t.TempDir(), "de.po")
+	fake := deepltest.NewFakeTranslator()
+
+	result, err := po.TranslateFile(context.Background(), fake, filepath.Join("testdata", "messages.pot"), pathTarget,
+		po.Options{
+			TranslateOptions: deepl.TranslateOptions{SourceLang: "EN", TargetLang: "DE"},
+		})
+	require.NoError(t, err)
+	require.Equal(t, 4, result.Translated)
+	require.Equal(t, 1, result.Skipped)
+
+	file, err := po.ParseFile(pathTarget)
+	require.NoError(t, err)
+
+	require.Equal(t, "de", file.HeaderField("Language"))
+	require.Equal(t, po.DefaultPluralForms, file.HeaderField("Plural-Forms"))
+
+	hello := file.Entries[1]
+	require.Equal(t, "[DE] Hello, %s!", hello.Str)
+	require.Equal(t, []string{"c-format", po.FlagFuzzy}, hello.Flags)
+
+	require.Equal(t, "[DE] Open", file.Entries[2].Str)
+
+	translated := file.Entries[3]
+	require.Equal(t, "Bereits übersetzt", translated.Str, "human translation should not be overwritten")
+	require.False(t, translated.HasFlag(po.FlagFuzzy))
+
+	require.Equal(t, []string{"[DE] %d file", "[DE] %d files"}, file.Entries[4].StrPlural)
+	require.Equal(t, "[DE] First line\nSecond line \"quoted\"\n", file.Entries[5].Str,
+		"trailing newline should be kept")
+	require.True(t, file.Entries[6].IsObsolete())
+
+	// The entries are grouped by the context
+	calls := fake.Calls()
+	require.Len(t, calls, 3)
+	require.Equal(t, "TRANSLATORS: greeting on the top page", calls[0].Options.Context)
+	require.Equal(t, []string{`Hello, <x id="0"/>!`}, calls[0].Texts, "c-format placeholders should be masked")
+	require.Equal(t, "menu", calls[1].Options.Context)
+	require.Empty(t, calls[2].Options.Context)
+	require.Equal(t, []string{`<x id="0"/> file`, `<x id="0"/> files`, "First line\nSecond line \"quoted\""},
+		calls[2].Texts, "spaces around the text should not be sent")
+	require.Equal(t, deepl.TagHandlingXML, calls[2].Options.TagHandling)
+
+	// Nothing to translate on the second run
+	result, err = po.TranslateFile(context.Background(), fake, pathTarget, pathTarget, po.Options{
+		TranslateOptions: deepl.TranslateOptions{TargetLang: "DE"},
+	})
+	require.NoError(t, err)
+	require.Zero(t, result.Translated)
+	require.Equal(t, 5, result.Skipped)
+	require.Len(t, fake.Calls(), 3)
+}
+
+func TestTranslate_single_plural_form(t *testing.T) {
+	t.Parallel()
+
+	data, err := os.ReadFile(filepath.Join("testdata", "messages.pot"))
+	require.NoError(t, err)
+
+	file, err := po.Parse(data)
+	require.NoError(t, err)
+
+	_, err = po.Translate(context.Background(), deepltest.NewFakeTranslator(), file, po.Options{
+		TranslateOptions: deepl.TranslateOptions{TargetLang: "JA"},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, "ja", file.HeaderField("Language"))
+	require.Equal(t, 1, file.NPlurals())
+	require.Equal(t, []string{"[JA] %d files"}, file.Entries[4].StrPlural,
+		"msgstr[0] should be translated from msgid_plural")
+}
+
+func TestTranslate_plural_forms(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		targetLang  string
+		pluralForms string
+		expected    int
+	}{
+		{targetLang: "PT-BR", expected: 2},
+		{targetLang: "EN-GB", expected: 2},
+		{targetLang: "RU", expected: 3},
+		{targetLang: "XX", expected: 2},
+		{targetLang: "XX", pluralForms: "nplurals=4; plural=0;", expected: 4},
+	} {
+		file, err := po.Parse([]byte("msgid \"%d file\"\nmsgid_plural \"%d files\"\nmsgstr[0] \"\"\n"))
+		require.NoError(t, err)
+
+		_, err = po.Translate(context.Background(), deepltest.NewFakeTranslator(), file, po.Options{
+			TranslateOptions: deepl.TranslateOptions{TargetLang: test.targetLang},
+			PluralForms:      test.pluralForms,
+		})
+		require.NoError(t, err)
+		require.Equal(t, test.expected, file.NPlurals(), "target: %s", test.targetLang)
+		require.Len(t, file.Entries[1].StrPlural, test.expected, "target: %s", test.targetLang)
+	}
+
+	require.Equal(t, "pt_BR", func() string {
+		file := new(po.File)
+
+		_, err := po.Translate(context.Background(), deepltest.NewFakeTranslator(), file, po.Options{
+			TranslateOptions: deepl.TranslateOptions{TargetLang: "pt-br"},
+		})
+		require.NoError(t, err)
+
+		return file.HeaderField("Language")
+	}())
+}
+
+func TestTranslate_errors(t *testing.T) {
+	t.Parallel()
+
+	fake := deepltest.NewFakeTranslator()
+	opts := po.Options{TranslateOptions: deepl.TranslateOptions{TargetLang: "DE"}}
+
+	_, err := po.Translate(context.Background(), fake, nil, opts)
+	require.Error(t, err, "nil file should be an error")
+
+	file, err := po.Parse([]byte("#, c-format\nmsgid \"%s items\"\nmsgstr \"\"\n"))
+	require.NoError(t, err)
+
+	_, err = po.Translate(context.Background(), fake, file, po.Options{})
+	require.Error(t, err, "empty target language should be an error")
+
+	fake.TranslateFunc = func(text string, _ *deepl.TranslateOptions) string {
+		return strings.ReplaceAll(text, `<x id="0"/>`, "")
+	}
+
+	_, err = po.Translate(context.Background(), fake, file, opts)
+	require.Error(t, err, "lost placeholder should be an error")
+	require.Empty(t, file.Entries[1].Str, "entry should not be filled on error")
+	require.False(t, file.Entries[1].HasFlag(po.FlagFuzzy), "entry should not be marked on error")
+}
+
+func TestTranslate_format_flags(t *testing.T) {
+	t.Parallel()
+
+	file, err := po.Parse([]byte(strings.Join([]string{
+		`#, python-format`,
+		`msgid "{count} of %s"`,
+		`msgstr ""`,
+		``,
+		`#, no-c-format`,
+		`msgid "100% {sure}"`,
+		`msgstr ""`,
+		``,
+		`msgid "%s <b>"`,
+		`msgstr ""`,
+		``,
+	}, "\n")))
+	require.NoError(t, err)
+
+	fake := deepltest.NewFakeTranslator()
+
+	_, err = po.Translate(context.Background(), fake, file, po.Options{
+		TranslateOptions: deepl.TranslateOptions{TargetLang: "DE", Context: "Web shop"},
+	})
+	require.NoError(t, err)
+
+	calls := fake.Calls()
+	require.Len(t, calls, 1)
+	require.Equal(t, "Web shop", calls[0].Options.Context)
+	require.Equal(t, []string{`<x id="0"/> of <x id="1"/>`, "100% {sure}", "%s &lt;b&gt;"}, calls[0].Texts,
+		"only the entries with the format flags should be masked")
+	require.Equal(t, "[DE] %s <b>", file.Entries[3].Str)
+}
@@ -0,0 +1,348 @@
+package deepl
+
+import (
+	"context"
+	"io"
+	"math"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// ----------------------------------------------------------------------------
+//  Type: RateLimit
+// ----------------------------------------------------------------------------
+
+// RateLimit configures the client-side rate limiter shared across all the
+// methods of a client. Zero values disable each limit.
+//
+// Requests that exceed the limits wait until they are allowed or the request
+// context is done.
+type RateLimit struct {
+	// RequestsPerSecond is the rate of the HTTP requests including the retries.
+	RequestsPerSecond float64
+	// RequestBurst is the number of requests that can be sent at once. Defaults
+	// to RequestsPerSecond rounded up (at least 1).
+	RequestBurst int
+	// CharactersPerSecond is the rate of the characters to translate.
+	CharactersPerSecond float64
+	// CharacterBurst is the number of characters that can be sent at once.
+	// Defaults to CharactersPerSecond rounded up (at least 1).
+	CharacterBurst int
+	// MaxInFlight is the maximum number of requests being processed at the same
+	// time.
+	MaxInFlight int
+}
+
+// LimiterStats is the snapshot of the rate limiter state for metrics.
+type LimiterStats struct {
+	// RequestTokens and CharacterTokens are the currently available tokens. They
+	// are negative while paying back a request larger than the burst.
+	RequestTokens   float64
+	CharacterTokens float64
+	// TotalWait is the accumulated time the requests waited for the limiter.
+	TotalWait time.Duration
+	// InFlight is the number of requests being processed.
+	InFlight int
+	// MaxInFlight is the configured limit of InFlight. Zero means unlimited.
+	MaxInFlight int
+	// Waiting is the number of requests waiting for the limiter.
+	Waiting int
+}
+
+// WithRateLimit sets the client-side rate limiter and the concurrency cap of
+// the client.
+func WithRateLimit(limit RateLimit) Option {
+	return func(c *Client) error {
+		if limit.RequestsPerSecond < 0 || limit.CharactersPerSecond < 0 ||
+			limit.RequestBurst < 0 || limit.CharacterBurst < 0 || limit.MaxInFlight < 0 {
+			return NewErr("rate limits must not be negative")
+		}
+
+		c.limiter = newRateLimiter(limit)
+
+		return nil
+	}
+}
+
+// LimiterStats returns the current state of the rate limiter. It returns zero
+// values if no rate limit is set.
+func (c *Client) LimiterStats() LimiterStats {
+	if c.limiter == nil {
+		return LimiterStats{}
+	}
+
+	return c.limiter.stats()
+}
+
+// ----------------------------------------------------------------------------
+//  Type: rateLimiter
+// ----------------------------------------------------------------------------
+
+// rateLimiter combines the token buckets of the requests and the characters
+// with the semaphore of the in-flight requests.
+type rateLimiter struct {
+	requests   *tokenBucket
+	characters *tokenBucket
+	semaphore  chan struct{}
+	totalWait  time.Duration
+	waiting    int
+	mutex      sync.Mutex
+}
+
+// newRateLimiter returns a new rateLimiter of the given limit.
+func newRateLimiter(limit RateLimit) *rateLimiter {
+	limiter := new(rateLimiter)
+
+	if limit.RequestsPerSecond > 0 {
+		limiter.requests = newTokenBucket(limit.RequestsPerSecond, limit.RequestBurst)
+	}
+
+	if limit.CharactersPerSecond > 0 {
+		limiter.characters = newTokenBucket(limit.CharactersPerSecond, limit.CharacterBurst)
+	}
+
+	if limit.MaxInFlight > 0 {
+		limiter.semaphore = make(chan struct{}, limit.MaxInFlight)
+	}
+
+	return limiter
+}
+
+// acquire waits until a request with the given number of characters is allowed
+// and returns the function to release the in-flight slot. It is safe to call on
+// a nil limiter.
+//
+// The in-flight slot is acquired first so that the queued requests do not spend
+// the tokens before they can run. The tokens taken are refunded if the context
+// is done while waiting.
+func (l *rateLimiter) acquire(ctx context.Context, characters int) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	l.addWaiting(1)
+	defer l.addWaiting(-1)
+
+	timeStart := time.Now()
+	defer l.addWait(timeStart)
+
+	release := func() {}
+
+	if l.semaphore != nil {
+		select {
+		case <-ctx.Done():
+			return nil, WrapIfErr(ctx.Err(), "canceled while waiting for the in-flight slot")
+		case l.semaphore <- struct{}{}:
+		}
+
+		var once sync.Once
+
+		release = func() {
+			once.Do(func() { <-l.semaphore })
+		}
+	}
+
+	if err := l.requests.wait(ctx, 1); err != nil {
+		release()
+
+		return nil, err
+	}
+
+	if err := l.characters.wait(ctx, float64(characters)); err != nil {
+		l.requests.refund(1)
+		release()
+
+		return nil, err
+	}
+
+	return release, nil
+}
+
+func (l *rateLimiter) addWaiting(delta int) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.waiting += delta
+}
+
+func (l *rateLimiter) addWait(timeStart time.Time) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.totalWait += time.Since(timeStart)
+}
+
+// stats returns the snapshot of the limiter state.
+func (l *rateLimiter) stats() LimiterStats {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return LimiterStats{
+		RequestTokens:   l.requests.available(),
+		CharacterTokens: l.characters.available(),
+		TotalWait:       l.totalWait,
+		InFlight:        len(l.semaphore),
+		MaxInFlight:     cap(l.semaphore),
+		Waiting:         l.waiting,
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  Type: tokenBucket
+// ----------------------------------------------------------------------------
+
+// tokenBucket is a simple token bucket. Requests larger than the burst are
+// allowed once the bucket is full, leaving the tokens negative so that the
+// following requests pay back the debt.
+type tokenBucket struct {
+	last   time.Time
+	rate   float64
+	burst  float64
+	tokens float64
+	mutex  sync.Mutex
+}
+
+// newTokenBucket returns a full token bucket.
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if burst <= 0 {
+		burst = int(math.Ceil(rate))
+	}
+
+	return &tokenBucket{
+		last:   time.Now(),
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+	}
+}
+
+// wait blocks until n tokens are taken or the context is done. It is safe to
+// call on a nil bucket.
+func (b *tokenBucket) wait(ctx context.Context, n float64) error {
+	if b == nil || n <= 0 {
+		return nil
+	}
+
+	for {
+		delay := b.take(n)
+		if delay == 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return WrapIfErr(ctx.Err(), "canceled while waiting for the rate limiter")
+		case <-timer.C:
+		}
+	}
+}
+
+// take takes n tokens if available and returns zero. Otherwise, it returns the
+// estimated delay until they are available.
+func (b *tokenBucket) take(n float64) time.Duration {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.refill()
+
+	need := math.Min(n, b.burst)
+	if b.tokens >= need {
+		b.tokens -= n
+
+		return 0
+	}
+
+	delay := time.Duration((need - b.tokens) / b.rate * float64(time.Second))
+	if delay <= 0 {
+		delay = time.Millisecond
+	}
+
+	return delay
+}
+
+// refund returns n tokens taken by wait to the bucket. It is safe to call on a
+// nil bucket.
+func (b *tokenBucket) refund(n float64) {
+	if b == nil || n <= 0 {
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.refill()
+
+	b.tokens = math.Min(b.burst, b.tokens+n)
+}
+
+// refill adds the tokens accumulated since the last refill.
+func (b *tokenBucket) refill() {
+	now := time.Now()
+
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+}
+
+// available returns the current number of tokens. It returns zero on a nil
+// bucket.
+func (b *tokenBucket) available() float64 {
+	if b == nil {
+		return 0
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.refill()
+
+	return b.tokens
+}
+
+// ----------------------------------------------------------------------------
+//  Private Functions
+// ----------------------------------------------------------------------------
+
+// ctxKeyCharacters is the context key of the number of characters to translate
+// in the request.
+type ctxKeyCharacters struct{}
+
+// withCharacters returns a context with the number of characters to be counted
+// by the rate limiter.
+func withCharacters(ctx context.Context, count int) context.Context {
+	return context.WithValue(ctx, ctxKeyCharacters{}, count)
+}
+
+// countCharacters returns the total number of characters in the texts.
+func countCharacters(texts []string) int {
+	count := 0
+	for _, text := range texts {
+		count += utf8.RuneCountInString(text)
+	}
+
+	return count
+}
+
+// charactersFromContext returns the number of characters set by withCharacters.
+func charactersFromContext(ctx context.Context) int {
+	count, _ := ctx.Value(ctxKeyCharacters{}).(int)
+
+	return count
+}
+
+// releaseOnClose is an io.ReadCloser that calls the release function on close.
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+}
+
+// Close closes the underlying body and releases the in-flight slot.
+func (r *releaseOnClose) Close() error {
+	defer r.release()
+
+	return r.ReadCloser.Close()
+}
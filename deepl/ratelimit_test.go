@@ -0,0 +1,265 @@
+package deepl
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  tokenBucket
+// ----------------------------------------------------------------------------
+
+func Test_tokenBucket_take(t *testing.T) {
+	t.Parallel()
+
+	bucket := newTokenBucket(10, 2)
+
+	require.Zero(t, bucket.take(1))
+	require.Zero(t, bucket.take(1))
+	require.Greater(t, bucket.take(1), time.Duration(0), "empty bucket should return the delay")
+}
+
+func Test_tokenBucket_take_larger_than_burst(t *testing.T) {
+	t.Parallel()
+
+	bucket := newTokenBucket(10, 5)
+
+	require.Zero(t, bucket.take(8), "request larger than the burst should be allowed on a full bucket")
+	require.Less(t, bucket.available(), 0.0, "tokens should be in debt")
+	require.Greater(t, bucket.take(1), 300*time.Millisecond, "the debt should be paid back")
+}
+
+func Test_tokenBucket_nil(t *testing.T) {
+	t.Parallel()
+
+	var bucket *tokenBucket
+
+	require.NoError(t, bucket.wait(context.Background(), 100))
+	require.Zero(t, bucket.available())
+}
+
+// ----------------------------------------------------------------------------
+//  rateLimiter
+// ----------------------------------------------------------------------------
+
+func Test_rateLimiter_acquire_refund_on_cancel(t *testing.T) {
+	t.Parallel()
+
+	limiter := newRateLimiter(RateLimit{
+		RequestsPerSecond:   1,
+		CharactersPerSecond: 0.001,
+		CharacterBurst:      10,
+		MaxInFlight:         1,
+	})
+
+	require.Zero(t, limiter.characters.take(10), "failed to drain the character tokens")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	release, err := limiter.acquire(ctx, 5)
+
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Nil(t, release)
+
+	stats := limiter.stats()
+	require.InDelta(t, 1.0, stats.RequestTokens, 0.001, "request token should be refunded")
+	require.Zero(t, stats.InFlight, "in-flight slot should be released")
+}
+
+func Test_rateLimiter_acquire_queued_keep_tokens(t *testing.T) {
+	t.Parallel()
+
+	limiter := newRateLimiter(RateLimit{RequestsPerSecond: 0.001, RequestBurst: 2, MaxInFlight: 1})
+
+	release, err := limiter.acquire(context.Background(), 0)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err = limiter.acquire(ctx, 0)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.InDelta(t, 1.0, limiter.stats().RequestTokens, 0.001,
+		"queued request should not spend the request tokens")
+
+	release()
+
+	release, err = limiter.acquire(context.Background(), 0)
+	require.NoError(t, err, "released slot should be acquired with the remaining token")
+
+	release()
+}
+
+// ----------------------------------------------------------------------------
+//  WithRateLimit
+// ----------------------------------------------------------------------------
+
+func TestWithRateLimit_negative(t *testing.T) {
+	t.Parallel()
+
+	for _, limit := range []RateLimit{
+		{RequestsPerSecond: -1},
+		{CharactersPerSecond: -1},
+		{MaxInFlight: -1},
+	} {
+		_, err := New(APIFree, nil, WithRateLimit(limit))
+
+		require.Error(t, err, "negative limit should be an error: %+v", limit)
+		assert.Contains(t, err.Error(), "rate limits must not be negative")
+	}
+}
+
+func TestClient_LimiterStats_no_limit(t *testing.T) {
+	t.Parallel()
+
+	cli, err := New(APIFree, nil)
+	require.NoError(t, err)
+
+	require.Equal(t, LimiterStats{}, cli.LimiterStats())
+}
+
+func TestClient_rate_limit_requests(t *testing.T) {
+	t.Parallel()
+
+	cli, teardown, countReq := spawnEchoServer(t)
+	defer teardown()
+
+	require.NoError(t, WithRateLimit(RateLimit{RequestsPerSecond: 20, RequestBurst: 1})(cli))
+
+	timeStart := time.Now()
+
+	for i := 0; i < 3; i++ {
+		_, err := cli.TranslateSentence(context.Background(), "Hello", "EN", "DE")
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, int32(3), atomic.LoadInt32(countReq))
+	require.GreaterOrEqual(t, time.Since(timeStart), 90*time.Millisecond,
+		"2nd and 3rd requests should wait for 50ms each")
+	require.Greater(t, cli.LimiterStats().TotalWait, time.Duration(0))
+}
+
+func TestClient_rate_limit_characters(t *testing.T) {
+	t.Parallel()
+
+	cli, teardown, _ := spawnEchoServer(t)
+	defer teardown()
+
+	require.NoError(t, WithRateLimit(RateLimit{CharactersPerSecond: 1, CharacterBurst: 10})(cli))
+
+	_, err := cli.TranslateTexts(context.Background(), []string{"Hello", "World!"}, "EN", "DE")
+	require.NoError(t, err)
+
+	stats := cli.LimiterStats()
+	require.InDelta(t, -1.0, stats.CharacterTokens, 0.1, "11 characters should be taken from the burst of 10")
+	require.Zero(t, stats.RequestTokens, "requests should not be limited")
+
+	// The next request must wait for more than a second
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = cli.TranslateSentence(ctx, "Hello", "EN", "DE")
+	require.Error(t, err)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "canceled while waiting for the rate limiter")
+}
+
+func TestClient_rate_limit_max_in_flight(t *testing.T) {
+	t.Parallel()
+
+	var (
+		inFlight    int32
+		maxInFlight int32
+	)
+
+	cli, err := New(APICustom, nil,
+		WithBaseURL("http://deepl.example.com"),
+		WithAPIKey(dummyAuthKey),
+		WithRateLimit(RateLimit{MaxInFlight: 2}),
+		WithHTTPClient(&http.Client{
+			Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				current := atomic.AddInt32(&inFlight, 1)
+				defer atomic.AddInt32(&inFlight, -1)
+
+				for {
+					prev := atomic.LoadInt32(&maxInFlight)
+					if current <= prev || atomic.CompareAndSwapInt32(&maxInFlight, prev, current) {
+						break
+					}
+				}
+
+				time.Sleep(20 * time.Millisecond)
+
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader(bodyUsage)),
+					Header:     make(http.Header),
+					Request:    req,
+				}, nil
+			}),
+		}),
+	)
+	require.NoError(t, err)
+
+	var waitGroup sync.WaitGroup
+
+	for i := 0; i < 6; i++ {
+		waitGroup.Add(1)
+
+		go func() {
+			defer waitGroup.Done()
+
+			_, err := cli.GetAccountStatus(context.Background())
+			assert.NoError(t, err)
+		}()
+	}
+
+	waitGroup.Wait()
+
+	require.Equal(t, int32(2), atomic.LoadInt32(&maxInFlight), "at most 2 requests should be in flight")
+
+	stats := cli.LimiterStats()
+	require.Zero(t, stats.InFlight, "all the slots should be released")
+	require.Equal(t, 2, stats.MaxInFlight)
+	require.Zero(t, stats.Waiting)
+}
+
+func TestClient_rate_limit_in_flight_until_body_closed(t *testing.T) {
+	t.Parallel()
+
+	cli, teardown, _ := spawnEchoServer(t)
+	defer teardown()
+
+	require.NoError(t, WithRateLimit(RateLimit{MaxInFlight: 1})(cli))
+
+	req, err := cli.newRequest(context.Background(), http.MethodPost, "v2/translate", nil)
+	require.NoError(t, err)
+
+	resp, err := cli.do(req)
+	require.NoError(t, err)
+	require.Equal(t, 1, cli.LimiterStats().InFlight, "slot should be held until the body is closed")
+
+	// The second request can not get the slot
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	req2, err := cli.newRequest(ctx, http.MethodPost, "v2/usage", nil)
+	require.NoError(t, err)
+
+	_, err = cli.do(req2)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "canceled while waiting for the in-flight slot")
+
+	require.NoError(t, resp.Body.Close())
+	require.Zero(t, cli.LimiterStats().InFlight)
+}
@@ -0,0 +1,227 @@
+package deepl
+
+import (
+	"context"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+//  Type: RetryPolicy
+// ----------------------------------------------------------------------------
+
+// RetryPolicy configures the automatic retries of the requests that failed with
+// "too many requests" (429, 529), server errors (5xx) or transient network
+// errors.
+//
+// The delay before each retry grows exponentially from BaseDelay up to MaxDelay.
+// If the response has the "Retry-After" header, the longer of the two is used.
+// It never waits beyond the deadline of the request context.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts including the first one.
+	// Zero or one disables the retries.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry.
+	BaseDelay time.Duration
+	// MaxDelay is the upper limit of the exponential backoff.
+	MaxDelay time.Duration
+	// Jitter is the ratio (0.0 to 1.0) of the delay to randomize. Which spreads
+	// the retries of concurrent requests. E.g. 0.2 waits 80% to 100% of the delay.
+	Jitter float64
+	// RetryNonIdempotent enables the retries of the requests that may have side
+	// effects if sent twice. Such as document uploads and glossary creation.
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy returns the recommended retry policy. Which tries up to 5
+// times with the delay from 1 to 30 seconds and 20% of jitter.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:        5,
+		BaseDelay:          1 * time.Second,
+		MaxDelay:           30 * time.Second,
+		Jitter:             0.2,
+		RetryNonIdempotent: false,
+	}
+}
+
+// WithRetryPolicy sets the retry policy of the client. By default, the client
+// does not retry.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) error {
+		if policy.Jitter < 0 || policy.Jitter > 1 {
+			return NewErr("jitter must be between 0 and 1: %v", policy.Jitter)
+		}
+
+		if policy.BaseDelay < 0 || policy.MaxDelay < 0 {
+			return NewErr("delays must not be negative")
+		}
+
+		c.RetryPolicy = &policy
+
+		return nil
+	}
+}
+
+// backoff returns the delay before the retry of the given attempt (1 origin).
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	delay := float64(p.BaseDelay) * math.Pow(2, float64(attempt-1))
+
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+
+	if p.Jitter > 0 {
+		//nolint:gosec // no need to be cryptographically secure
+		delay -= delay * p.Jitter * rand.Float64()
+	}
+
+	return time.Duration(delay)
+}
+
+// ----------------------------------------------------------------------------
+//  Private Functions
+// ----------------------------------------------------------------------------
+
+// ctxKeyNonIdempotent is the context key to mark the request as non-idempotent.
+type ctxKeyNonIdempotent struct{}
+
+// withNonIdempotent returns a context that marks the request as non-idempotent.
+// Such requests are not retried unless RetryPolicy.RetryNonIdempotent is set.
+func withNonIdempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ctxKeyNonIdempotent{}, true)
+}
+
+// isRetryableStatus returns true if the status code is worth retrying.
+func isRetryableStatus(status int) bool {
+	return (&APIError{StatusCode: status}).Retryable()
+}
+
+// parseRetryAfter returns the delay of the "Retry-After" header value. Which is
+// either the seconds or the HTTP date. It returns zero if not set or invalid.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+
+	return 0
+}
+
+// ----------------------------------------------------------------------------
+//  Private Methods
+// ----------------------------------------------------------------------------
+
+// doWithRetry sends the request and retries it according to the RetryPolicy of
+// the client. On the last attempt, the response is returned as is so that the
+// caller can parse the error response.
+func (c *Client) doWithRetry(req *http.Request) (*http.Response, error) {
+	policy := c.RetryPolicy
+
+	maxAttempts := 1
+	if policy != nil && policy.MaxAttempts > 1 {
+		maxAttempts = policy.MaxAttempts
+	}
+
+	// The body that can not be rewound can be sent only once
+	if req.Body != nil && req.GetBody == nil {
+		maxAttempts = 1
+	}
+
+	if nonIdempotent, _ := req.Context().Value(ctxKeyNonIdempotent{}).(bool); nonIdempotent &&
+		(policy == nil || !policy.RetryNonIdempotent) {
+		maxAttempts = 1
+	}
+
+	// Characters are counted only once regardless of the retries
+	characters := charactersFromContext(req.Context())
+
+	for attempt := 1; ; attempt++ {
+		release, err := c.limiter.acquire(req.Context(), characters)
+		if err != nil {
+			return nil, err
+		}
+
+		characters = 0
+
+		resp, err := c.HTTPClient.Do(req)
+		if err != nil {
+			release()
+		} else {
+			resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: release}
+		}
+
+		isLast := attempt >= maxAttempts || req.Context().Err() != nil
+		if isLast || (err == nil && !isRetryableStatus(resp.StatusCode)) {
+			return resp, WrapIfErr(err, "failed to send http request")
+		}
+
+		delay := policy.backoff(attempt)
+
+		reason := "network error"
+		if err == nil {
+			reason = resp.Status
+
+			if retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); retryAfter > delay {
+				delay = retryAfter
+			}
+		}
+
+		// Give up if the delay exceeds the deadline of the request
+		if deadline, ok := req.Context().Deadline(); ok && time.Now().Add(delay).After(deadline) {
+			return resp, WrapIfErr(err, "failed to send http request")
+		}
+
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+
+		if c.Logger != nil {
+			c.Logger.Printf("retrying %s %s in %v (attempt %d/%d): %s",
+				req.Method, req.URL.Path, delay, attempt+1, maxAttempts, reason)
+		}
+
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+
+			return nil, WrapIfErr(req.Context().Err(), "failed to send http request")
+		case <-timer.C:
+		}
+
+		if req, err = rewindRequest(req); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// rewindRequest returns a copy of the request with the body rewound to be sent
+// again.
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	newReq := req.Clone(req.Context())
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, WrapIfErr(err, "failed to rewind the request body")
+		}
+
+		newReq.Body = body
+	}
+
+	return newReq, nil
+}
@@ -0,0 +1,246 @@
+package deepl
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testRetryPolicy is a retry policy with short delays for testing.
+var testRetryPolicy = RetryPolicy{
+	MaxAttempts:        3,
+	BaseDelay:          time.Millisecond,
+	MaxDelay:           5 * time.Millisecond,
+	Jitter:             0,
+	RetryNonIdempotent: false,
+}
+
+const (
+	bodyUsage     = `{"character_count":10,"character_limit":20}`
+	bodyTranslate = `{"translations":[{"detected_source_language":"EN","text":"Hallo"}]}`
+	bodyErr       = `{"message":"scripted error"}`
+)
+
+// ----------------------------------------------------------------------------
+//  RetryPolicy
+// ----------------------------------------------------------------------------
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	t.Parallel()
+
+	policy := RetryPolicy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+
+	require.Equal(t, time.Second, policy.backoff(1))
+	require.Equal(t, 2*time.Second, policy.backoff(2))
+	require.Equal(t, 4*time.Second, policy.backoff(3))
+	require.Equal(t, 5*time.Second, policy.backoff(4), "it should be capped by MaxDelay")
+
+	policy.Jitter = 0.5
+
+	for i := 0; i < 100; i++ {
+		delay := policy.backoff(1)
+
+		require.GreaterOrEqual(t, delay, 500*time.Millisecond)
+		require.LessOrEqual(t, delay, time.Second)
+	}
+}
+
+func TestWithRetryPolicy_bad_policy(t *testing.T) {
+	t.Parallel()
+
+	_, err := New(APIFree, nil, WithRetryPolicy(RetryPolicy{Jitter: 1.5}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "jitter must be between 0 and 1")
+
+	_, err = New(APIFree, nil, WithRetryPolicy(RetryPolicy{BaseDelay: -1}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "delays must not be negative")
+
+	cli, err := New(APIFree, nil, WithRetryPolicy(DefaultRetryPolicy()))
+	require.NoError(t, err)
+	require.Equal(t, 5, cli.RetryPolicy.MaxAttempts)
+}
+
+func Test_parseRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2023, 6, 5, 10, 0, 0, 0, time.UTC)
+
+	require.Equal(t, 3*time.Second, parseRetryAfter("3", now))
+	require.Equal(t, 30*time.Second, parseRetryAfter("Mon, 05 Jun 2023 10:00:30 GMT", now))
+	require.Zero(t, parseRetryAfter("Mon, 05 Jun 2023 09:00:00 GMT", now), "past date should be zero")
+	require.Zero(t, parseRetryAfter("", now))
+	require.Zero(t, parseRetryAfter("soon", now))
+	require.Zero(t, parseRetryAfter("-1", now))
+}
+
+// ----------------------------------------------------------------------------
+//  Client with RetryPolicy
+// ----------------------------------------------------------------------------
+
+func TestClient_retry_success(t *testing.T) {
+	t.Parallel()
+
+	cli, teardown, countReq := spawnScriptedServer(t,
+		scriptedResponse{status: http.StatusServiceUnavailable, body: bodyErr},
+		scriptedResponse{status: http.StatusTooManyRequests, body: bodyErr, retryAfter: "0"},
+		scriptedResponse{status: http.StatusOK, body: bodyTranslate},
+	)
+	defer teardown()
+
+	require.NoError(t, WithRetryPolicy(testRetryPolicy)(cli))
+
+	resp, err := cli.TranslateSentence(context.TODO(), "Hello", "EN", "DE")
+
+	require.NoError(t, err, "it should succeed after the retries")
+	require.Equal(t, "Hallo", resp.Translations[0].Text)
+	require.Equal(t, int32(3), *countReq, "it should be requested 3 times")
+}
+
+func TestClient_retry_exhausted(t *testing.T) {
+	t.Parallel()
+
+	cli, teardown, countReq := spawnScriptedServer(t,
+		scriptedResponse{status: http.StatusBadGateway, body: bodyErr},
+	)
+	defer teardown()
+
+	require.NoError(t, WithRetryPolicy(testRetryPolicy)(cli))
+
+	status, err := cli.GetAccountStatus(context.TODO())
+
+	require.Error(t, err, "it should fail after the max attempts")
+	require.Nil(t, status)
+	require.ErrorIs(t, err, ErrServerError, "the last error response should be returned")
+	require.Equal(t, int32(3), *countReq, "it should be requested MaxAttempts times")
+}
+
+func TestClient_retry_not_retryable(t *testing.T) {
+	t.Parallel()
+
+	cli, teardown, countReq := spawnScriptedServer(t,
+		scriptedResponse{status: StatusQuotaExceeded, body: bodyErr},
+		scriptedResponse{status: http.StatusOK, body: bodyUsage},
+	)
+	defer teardown()
+
+	require.NoError(t, WithRetryPolicy(testRetryPolicy)(cli))
+
+	_, err := cli.GetAccountStatus(context.TODO())
+
+	require.ErrorIs(t, err, ErrQuotaExceeded)
+	require.Equal(t, int32(1), *countReq, "quota exceeded should not be retried")
+}
+
+func TestClient_retry_disabled_by_default(t *testing.T) {
+	t.Parallel()
+
+	cli, teardown, countReq := spawnScriptedServer(t,
+		scriptedResponse{status: http.StatusServiceUnavailable, body: bodyErr},
+		scriptedResponse{status: http.StatusOK, body: bodyUsage},
+	)
+	defer teardown()
+
+	_, err := cli.GetAccountStatus(context.TODO())
+
+	require.ErrorIs(t, err, ErrServiceUnavailable)
+	require.Equal(t, int32(1), *countReq, "no retry without RetryPolicy")
+}
+
+func TestClient_retry_non_idempotent(t *testing.T) {
+	t.Parallel()
+
+	for _, optIn := range []bool{false, true} {
+		cli, teardown, countReq := spawnScriptedServer(t,
+			scriptedResponse{status: http.StatusServiceUnavailable, body: bodyErr},
+			scriptedResponse{status: http.StatusOK, body: `{"document_id":"ID","document_key":"KEY"}`},
+		)
+
+		policy := testRetryPolicy
+		policy.RetryNonIdempotent = optIn
+
+		require.NoError(t, WithRetryPolicy(policy)(cli))
+
+		handle, err := cli.UploadDocument(context.TODO(), strings.NewReader("hello"), "a.txt",
+			&DocumentOptions{TargetLang: "DE"})
+
+		if optIn {
+			require.NoError(t, err, "upload should be retried if opted in")
+			require.Equal(t, "ID", handle.DocumentID)
+			require.Equal(t, int32(2), *countReq)
+		} else {
+			require.ErrorIs(t, err, ErrServiceUnavailable)
+			require.Equal(t, int32(1), *countReq, "upload should not be retried by default")
+		}
+
+		teardown()
+	}
+}
+
+func TestClient_retry_respects_deadline(t *testing.T) {
+	t.Parallel()
+
+	cli, teardown, countReq := spawnScriptedServer(t,
+		scriptedResponse{status: http.StatusTooManyRequests, body: bodyErr, retryAfter: "60"},
+		scriptedResponse{status: http.StatusOK, body: bodyUsage},
+	)
+	defer teardown()
+
+	require.NoError(t, WithRetryPolicy(testRetryPolicy)(cli))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	timeStart := time.Now()
+
+	_, err := cli.GetAccountStatus(ctx)
+
+	require.ErrorIs(t, err, ErrTooManyRequests,
+		"it should give up if Retry-After exceeds the deadline")
+	require.Less(t, time.Since(timeStart), time.Second, "it should not wait")
+	require.Equal(t, int32(1), *countReq)
+}
+
+func TestClient_retry_network_error(t *testing.T) {
+	t.Parallel()
+
+	countReq := 0
+
+	cli, err := New(APICustom, log.New(io.Discard, "", 0),
+		WithBaseURL("http://deepl.test"),
+		WithAPIKey(dummyAuthKey),
+		WithRetryPolicy(testRetryPolicy),
+		WithHTTPClient(&http.Client{
+			Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				countReq++
+
+				if countReq < 3 {
+					return nil, errors.New("connection reset by peer")
+				}
+
+				return &http.Response{
+					Status:     "200 OK",
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader(bodyUsage)),
+					Header:     http.Header{},
+					Request:    req,
+				}, nil
+			}),
+		}),
+	)
+	require.NoError(t, err)
+
+	status, err := cli.GetAccountStatus(context.TODO())
+
+	require.NoError(t, err, "transient network errors should be retried")
+	require.Equal(t, int64(20), status.CharacterLimit)
+	require.Equal(t, 3, countReq)
+}
@@ -0,0 +1,326 @@
+/*
+Package subtitle reads and writes the SRT and WebVTT subtitle files and
+translates the cues with DeepL.
+
+	file, err := subtitle.ParseFile("movie.en.srt")
+
+	result, err := subtitle.Translate(ctx, cli, file, subtitle.Options{
+		TranslateOptions: deepl.TranslateOptions{SourceLang: "EN", TargetLang: "DE"},
+	})
+
+	err = file.WriteFile("movie.de.srt")
+
+Only the text of the cues is translated. The number of the cues, the IDs, the
+timings, the cue settings, the styling tags and the line breaks are kept.
+*/
+package subtitle
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KEINOS/go-deepl/deepl"
+)
+
+// Format is the format of the subtitle file.
+type Format int
+
+const (
+	// FormatSRT is the SubRip format.
+	FormatSRT Format = iota
+	// FormatWebVTT is the WebVTT format.
+	FormatWebVTT
+)
+
+// String returns the name of the format.
+func (f Format) String() string {
+	if f == FormatWebVTT {
+		return "WebVTT"
+	}
+
+	return "SRT"
+}
+
+// bom is the byte order mark of UTF-8.
+const bom = "\ufeff"
+
+// ----------------------------------------------------------------------------
+//  Type: Cue
+// ----------------------------------------------------------------------------
+
+// Cue is a subtitle cue.
+type Cue struct {
+	// ID is the sequence number of SRT or the identifier of WebVTT. It may be
+	// empty.
+	ID string
+	// Timing is the timing line as is. Such as "00:00:01,000 --> 00:00:02,500"
+	// and "00:01.000 --> 00:02.500 align:start". It is written back as is.
+	Timing string
+	// Text is the text of the cue. The lines are joined with "\n".
+	Text string
+	// Blocks are the non-cue blocks of WebVTT before the cue as is. Such as the
+	// NOTE, STYLE and REGION blocks.
+	Blocks []string
+	// Start is the start time parsed from the Timing.
+	Start time.Duration
+	// End is the end time parsed from the Timing.
+	End time.Duration
+}
+
+// ----------------------------------------------------------------------------
+//  Type: File
+// ----------------------------------------------------------------------------
+
+// File is a subtitle file.
+type File struct {
+	// Header is the header block of WebVTT. Such as "WEBVTT" and "WEBVTT - Title".
+	Header string
+	// Cues are the cues in order.
+	Cues []*Cue
+	// Trailer are the non-cue blocks of WebVTT after the last cue.
+	Trailer []string
+	// Format is the format of the file.
+	Format Format
+	// CRLF is true if the file uses "\r\n" as the line ending.
+	CRLF bool
+	// BOM is true if the file starts with the byte order mark.
+	BOM bool
+}
+
+// Parse parses the SRT or WebVTT data. The format is detected by the "WEBVTT"
+// header.
+func Parse(data []byte) (*File, error) {
+	text := string(data)
+	file := &File{
+		BOM:  strings.HasPrefix(text, bom),
+		CRLF: strings.Contains(text, "\r\n"),
+	}
+
+	text = strings.TrimPrefix(text, bom)
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	blocks := splitBlocks(text)
+
+	if len(blocks) > 0 && isWebVTTHeader(blocks[0]) {
+		file.Format = FormatWebVTT
+		file.Header = strings.Join(blocks[0], "\n")
+		blocks = blocks[1:]
+	}
+
+	var pending []string
+
+	for index, block := range blocks {
+		if file.Format == FormatWebVTT && isWebVTTBlock(block) {
+			pending = append(pending, strings.Join(block, "\n"))
+
+			continue
+		}
+
+		cue, err := parseCue(block)
+		if err != nil {
+			return nil, deepl.WrapIfErr(err, "failed to parse %s block #%d", file.Format, index+1)
+		}
+
+		cue.Blocks = pending
+		pending = nil
+
+		file.Cues = append(file.Cues, cue)
+	}
+
+	file.Trailer = pending
+
+	return file, nil
+}
+
+// ParseFile reads and parses the SRT or WebVTT file.
+func ParseFile(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, deepl.WrapIfErr(err, "failed to read subtitle file")
+	}
+
+	return Parse(data)
+}
+
+// Encode returns the subtitle data of the file.
+func (f *File) Encode() []byte {
+	var blocks []string
+
+	if f.Format == FormatWebVTT {
+		header := f.Header
+		if header == "" {
+			header = "WEBVTT"
+		}
+
+		blocks = append(blocks, header)
+	}
+
+	for _, cue := range f.Cues {
+		blocks = append(blocks, cue.Blocks...)
+
+		var lines []string
+
+		if cue.ID != "" {
+			lines = append(lines, cue.ID)
+		}
+
+		lines = append(lines, cue.Timing)
+
+		if cue.Text != "" {
+			lines = append(lines, cue.Text)
+		}
+
+		blocks = append(blocks, strings.Join(lines, "\n"))
+	}
+
+	blocks = append(blocks, f.Trailer...)
+
+	text := strings.Join(blocks, "\n\n") + "\n"
+	if f.CRLF {
+		text = strings.ReplaceAll(text, "\n", "\r\n")
+	}
+
+	if f.BOM {
+		text = bom + text
+	}
+
+	return []byte(text)
+}
+
+// WriteFile writes the subtitle data of the file to the path.
+func (f *File) WriteFile(path string) error {
+	return deepl.WrapIfErr(os.WriteFile(path, f.Encode(), 0o644), "failed to write subtitle file")
+}
+
+// ----------------------------------------------------------------------------
+//  Functions
+// ----------------------------------------------------------------------------
+
+// ParseTiming parses the timing line of the cue and returns the start and the
+// end times. The timestamps of both SRT ("00:00:01,000") and WebVTT ("00:01.000"
+// and "00:00:01.000") are supported. The cue settings of WebVTT are ignored.
+func ParseTiming(timing string) (time.Duration, time.Duration, error) {
+	fields := strings.Fields(timing)
+	if len(fields) < 3 || fields[1] != "-->" { //nolint:gomnd // start, arrow and end
+		return 0, 0, deepl.NewErr("invalid timing: %q", timing)
+	}
+
+	start, err := parseTimestamp(fields[0])
+	if err != nil {
+		return 0, 0, err
+	}
+
+	end, err := parseTimestamp(fields[2])
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if end < start {
+		return 0, 0, deepl.NewErr("end time is before start time: %q", timing)
+	}
+
+	return start, end, nil
+}
+
+// ----------------------------------------------------------------------------
+//  Private Functions
+// ----------------------------------------------------------------------------
+
+// isWebVTTBlock returns true if the block is a non-cue block of WebVTT.
+func isWebVTTBlock(block []string) bool {
+	first := block[0]
+
+	for _, keyword := range []string{"NOTE", "STYLE", "REGION"} {
+		if first == keyword || strings.HasPrefix(first, keyword+" ") || strings.HasPrefix(first, keyword+"\t") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isWebVTTHeader returns true if the block is the header of WebVTT.
+func isWebVTTHeader(block []string) bool {
+	first := block[0]
+
+	return first == "WEBVTT" || strings.HasPrefix(first, "WEBVTT ") || strings.HasPrefix(first, "WEBVTT\t")
+}
+
+// parseCue parses the lines of the cue block.
+func parseCue(block []string) (*Cue, error) {
+	cue := new(Cue)
+
+	if !strings.Contains(block[0], "-->") {
+		cue.ID = block[0]
+		block = block[1:]
+	}
+
+	if len(block) == 0 || !strings.Contains(block[0], "-->") {
+		return nil, deepl.NewErr("timing line not found")
+	}
+
+	start, end, err := ParseTiming(block[0])
+	if err != nil {
+		return nil, err
+	}
+
+	cue.Timing = block[0]
+	cue.Start = start
+	cue.End = end
+	cue.Text = strings.Join(block[1:], "\n")
+
+	return cue, nil
+}
+
+// parseTimestamp parses the timestamp of SRT or WebVTT.
+func parseTimestamp(timestamp string) (time.Duration, error) {
+	parts := strings.Split(strings.Replace(timestamp, ",", ".", 1), ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, deepl.NewErr("invalid timestamp: %q", timestamp)
+	}
+
+	if len(parts) == 2 { //nolint:gomnd // WebVTT without hours
+		parts = append([]string{"0"}, parts...)
+	}
+
+	hours, errHours := strconv.Atoi(parts[0])
+	minutes, errMinutes := strconv.Atoi(parts[1])
+	seconds, errSeconds := strconv.ParseFloat(parts[2], 64)
+
+	if errHours != nil || errMinutes != nil || errSeconds != nil || minutes >= 60 || seconds >= 60 {
+		return 0, deepl.NewErr("invalid timestamp: %q", timestamp)
+	}
+
+	return time.Duration(hours)*time.Hour +
+		time.Duration(minutes)*time.Minute +
+		time.Duration(seconds*float64(time.Second)).Round(time.Millisecond), nil
+}
+
+// splitBlocks returns the lines of the blocks separated by the blank lines.
+func splitBlocks(text string) [][]string {
+	var (
+		blocks [][]string
+		block  []string
+	)
+
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			if block != nil {
+				blocks = append(blocks, block)
+				block = nil
+			}
+
+			continue
+		}
+
+		block = append(block, line)
+	}
+
+	if block != nil {
+		blocks = append(blocks, block)
+	}
+
+	return blocks
+}
@@ -0,0 +1,109 @@
+package subtitle_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/KEINOS/go-deepl/deepl/subtitle"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFile_srt(t *testing.T) {
+	t.Parallel()
+
+	file, err := subtitle.ParseFile(filepath.Join("testdata", "sample.srt"))
+	require.NoError(t, err)
+
+	require.Equal(t, subtitle.FormatSRT, file.Format)
+	require.Equal(t, "SRT", file.Format.String())
+	require.True(t, file.CRLF)
+	require.Len(t, file.Cues, 3)
+
+	require.Equal(t, "1", file.Cues[0].ID)
+	require.Equal(t, "00:00:01,000 --> 00:00:03,500", file.Cues[0].Timing)
+	require.Equal(t, time.Second, file.Cues[0].Start)
+	require.Equal(t, 3500*time.Millisecond, file.Cues[0].End)
+	require.Equal(t, "<i>Hello,</i>\nmy friend.", file.Cues[0].Text)
+	require.Equal(t, `{\an8}How are you?`, file.Cues[1].Text)
+}
+
+func TestParseFile_webvtt(t *testing.T) {
+	t.Parallel()
+
+	file, err := subtitle.ParseFile(filepath.Join("testdata", "sample.vtt"))
+	require.NoError(t, err)
+
+	require.Equal(t, subtitle.FormatWebVTT, file.Format)
+	require.Equal(t, "WebVTT", file.Format.String())
+	require.Equal(t, "WEBVTT - Sample", file.Header)
+	require.Len(t, file.Cues, 2)
+
+	require.Equal(t, "intro", file.Cues[0].ID)
+	require.Equal(t, []string{"STYLE\n::cue { color: yellow }"}, file.Cues[0].Blocks)
+	require.Equal(t, "00:01.000 --> 00:03.000 align:start position:10%", file.Cues[0].Timing)
+	require.Equal(t, time.Second, file.Cues[0].Start)
+
+	require.Empty(t, file.Cues[1].ID)
+	require.Equal(t, []string{"NOTE split sentence across cues"}, file.Cues[1].Blocks)
+	require.Equal(t, 3500*time.Millisecond, file.Cues[1].Start)
+
+	require.Equal(t, []string{"NOTE end"}, file.Trailer)
+}
+
+func TestFile_Encode_round_trip(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"sample.srt", "sample.vtt"} {
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		require.NoError(t, err)
+
+		file, err := subtitle.Parse(data)
+		require.NoError(t, err)
+
+		require.Equal(t, string(data), string(file.Encode()), "file: %s", name)
+	}
+}
+
+func TestParse_bom(t *testing.T) {
+	t.Parallel()
+
+	data := "\ufeffWEBVTT\n\n00:01.000 --> 00:02.000\nHello\n"
+
+	file, err := subtitle.Parse([]byte(data))
+	require.NoError(t, err)
+	require.True(t, file.BOM)
+	require.Equal(t, subtitle.FormatWebVTT, file.Format)
+	require.Equal(t, data, string(file.Encode()))
+}
+
+func TestParse_errors(t *testing.T) {
+	t.Parallel()
+
+	for _, input := range []string{
+		"1\nHello\n",
+		"1\n00:00:01,000 -> 00:00:02,000\nHello\n",
+		"1\n00:00:01,000 --> 00:00:xx,000\nHello\n",
+		"1\n00:00:03,000 --> 00:00:02,000\nHello\n",
+		"1\n00:61:00,000 --> 00:62:00,000\nHello\n",
+		"1\n1:2:3:4 --> 00:00:02,000\nHello\n",
+		"WEBVTT\n\nintro\n",
+	} {
+		_, err := subtitle.Parse([]byte(input))
+		require.Error(t, err, "input: %q", input)
+	}
+
+	_, err := subtitle.ParseFile(filepath.Join(t.TempDir(), "missing.srt"))
+	require.Error(t, err)
+}
+
+func TestParseTiming(t *testing.T) {
+	t.Parallel()
+
+	start, end, err := subtitle.ParseTiming("01:02:03.456 --> 01:02:04.000 line:0")
+
+	require.NoError(t, err)
+	require.Equal(t, time.Hour+2*time.Minute+3456*time.Millisecond, start)
+	require.Equal(t, time.Hour+2*time.Minute+4*time.Second, end)
+}
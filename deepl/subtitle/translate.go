@@ -0,0 +1,207 @@
+package subtitle
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/KEINOS/go-deepl/deepl"
+	"github.com/KEINOS/go-deepl/deepl/placeholder"
+)
+
+// Defaults of Options.
+const (
+	// DefaultBatchSize is the number of the cues translated in a request.
+	DefaultBatchSize = 10
+	// DefaultContextCues is the number of the cues before and after the batch
+	// sent as the context.
+	DefaultContextCues = 2
+)
+
+var (
+	// patternMarkup matches the styling tags and the line breaks to keep. Such
+	// as "<i>", "</font>", "<c.yellow>", "<v Roger>", "<00:00:01.000>" and the
+	// override tags "{\an8}".
+	patternMarkup = regexp.MustCompile(`<[^<>\n]*>|\{\\[^{}\n]*\}|[ \t]*\n[ \t]*`)
+	// patternTag matches the tags to remove from the context.
+	patternTag = regexp.MustCompile(`<[^<>\n]*>|\{\\[^{}\n]*\}`)
+)
+
+// ----------------------------------------------------------------------------
+//  Type: Options
+// ----------------------------------------------------------------------------
+
+// Options are the options of Translate.
+type Options struct {
+	// TranslateOptions are the options of the translation. TargetLang is
+	// required. Context is prepended to the context of the neighbouring cues.
+	// TagHandling is overridden to keep the styling tags and the line breaks at
+	// their positions.
+	TranslateOptions deepl.TranslateOptions
+	// BatchSize is the number of the consecutive cues translated in a request.
+	// Defaults to DefaultBatchSize.
+	BatchSize int
+	// ContextCues is the number of the cues before and after the batch sent as
+	// the context along with the cues of the batch. Defaults to
+	// DefaultContextCues. Set a negative value to send no context.
+	ContextCues int
+}
+
+// Result is the result of Translate.
+type Result struct {
+	// Translated is the number of the cues translated.
+	Translated int
+}
+
+// ----------------------------------------------------------------------------
+//  Functions
+// ----------------------------------------------------------------------------
+
+// Translate translates the text of the cues of the file in place with the
+// translator. The cues without the text are skipped.
+//
+// The consecutive cues are translated in batches. The text of the cues of the
+// batch and their neighbours is sent as the context so that the sentences split
+// into multiple cues are translated coherently. Since the context is not billed,
+// a smaller BatchSize improves the quality at the cost of more requests.
+//
+// The styling tags and the line breaks are kept at their positions in the
+// translation. The translation fails if any of them is lost.
+func Translate(ctx context.Context, translator deepl.Translator, file *File, opts Options) (*Result, error) {
+	if file == nil {
+		return nil, deepl.NewErr("subtitle file is nil")
+	}
+
+	if opts.TranslateOptions.TargetLang == "" {
+		return nil, deepl.NewErr("target language is empty")
+	}
+
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	contextCues := opts.ContextCues
+	if contextCues == 0 {
+		contextCues = DefaultContextCues
+	}
+
+	var pending []int
+
+	// Keep the source text for the context since the cues are updated in place
+	sources := make([]string, len(file.Cues))
+
+	for index, cue := range file.Cues {
+		sources[index] = cue.Text
+
+		if strings.TrimSpace(cue.Text) != "" {
+			pending = append(pending, index)
+		}
+	}
+
+	result := new(Result)
+
+	for start := 0; start < len(pending); start += batchSize {
+		end := start + batchSize
+		if end > len(pending) {
+			end = len(pending)
+		}
+
+		batch := pending[start:end]
+
+		transOpts := opts.TranslateOptions
+
+		if contextCues > 0 {
+			transOpts.Context = strings.TrimSpace(transOpts.Context + "\n" +
+				contextOf(sources, batch[0]-contextCues, batch[len(batch)-1]+contextCues+1))
+		}
+
+		if err := translateCues(ctx, translator, file.Cues, batch, transOpts); err != nil {
+			return nil, err
+		}
+
+		result.Translated += len(batch)
+	}
+
+	return result, nil
+}
+
+// TranslateFile translates the subtitle file and writes it to the target file.
+func TranslateFile(
+	ctx context.Context,
+	translator deepl.Translator,
+	sourcePath string,
+	targetPath string,
+	opts Options,
+) (*Result, error) {
+	file, err := ParseFile(sourcePath)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := Translate(ctx, translator, file, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := file.WriteFile(targetPath); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ----------------------------------------------------------------------------
+//  Private Functions
+// ----------------------------------------------------------------------------
+
+// contextOf returns the plain text of the source texts of the cues in the range.
+func contextOf(sources []string, start int, end int) string {
+	if start < 0 {
+		start = 0
+	}
+
+	if end > len(sources) {
+		end = len(sources)
+	}
+
+	texts := make([]string, 0, end-start)
+
+	for _, source := range sources[start:end] {
+		text := strings.Join(strings.Fields(patternTag.ReplaceAllString(source, "")), " ")
+		if text != "" {
+			texts = append(texts, text)
+		}
+	}
+
+	return strings.Join(texts, "\n")
+}
+
+// translateCues translates the text of the cues at the indexes.
+func translateCues(
+	ctx context.Context,
+	translator deepl.Translator,
+	cues []*Cue,
+	indexes []int,
+	opts deepl.TranslateOptions,
+) error {
+	masker := placeholder.NewMasker(patternMarkup)
+	texts := make([]string, len(indexes))
+	tokens := make([][]string, len(indexes))
+
+	for i, index := range indexes {
+		texts[i], tokens[i] = masker.MaskBreaks(cues[index].Text)
+	}
+
+	translated, err := placeholder.Translate(ctx, translator, texts, tokens, opts)
+	if err != nil {
+		return deepl.WrapIfErr(err, "failed to translate the cues #%d to #%d", indexes[0]+1, indexes[len(indexes)-1]+1)
+	}
+
+	// Update the cues only if all the cues of the batch are translated
+	for i, index := range indexes {
+		cues[index].Text = strings.TrimSpace(translated[i])
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package subtitle_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/KEINOS/go-deepl/deepl"
+	"github.com/KEINOS/go-deepl/deepl/deepltest"
+	"github.com/KEINOS/go-deepl/deepl/subtitle"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslateFile_srt(t *testing.T) {
+	t.Parallel()
+
+	pathTarget := filepath.Join(t.TempDir(), "sample.de.srt")
+	fake := deepltest.NewFakeTranslator()
+
+	result, err := subtitle.TranslateFile(context.Background(), fake, filepath.Join("testdata", "sample.srt"), pathTarget,
+		subtitle.Options{
+			TranslateOptions: deepl.TranslateOptions{SourceLang: "EN", TargetLang: "DE"},
+			BatchSize:        2,
+			ContextCues:      1,
+		})
+	require.NoError(t, err)
+	require.Equal(t, 3, result.Translated)
+
+	translated, err := os.ReadFile(pathTarget)
+	require.NoError(t, err)
+	require.Equal(t, strings.Join([]string{
+		"1",
+		"00:00:01,000 --> 00:00:03,500",
+		"[DE] <i>Hello,</i>",
+		"my friend.",
+		"",
+		"2",
+		"00:00:04,000 --> 00:00:06,000",
+		`[DE] {\an8}How are you?`,
+		"",
+		"3",
+		"00:00:06,500 --> 00:00:08,000",
+		"[DE] Fine & you?",
+		"",
+	}, "\r\n"), string(translated))
+
+	calls := fake.Calls()
+	require.Len(t, calls, 2, "cues should be translated in batches")
+	require.Equal(t, []string{
+		`<x id="0"/>Hello,<x id="1"/> <x id="2"/> my friend.`,
+		`<x id="0"/>How are you?`,
+	}, calls[0].Texts, "tags and line breaks should be masked")
+	require.Equal(t, deepl.TagHandlingXML, calls[0].Options.TagHandling)
+	require.Equal(t, "Hello, my friend.\nHow are you?\nFine & you?", calls[0].Options.Context,
+		"batch and the next cue should be the context")
+	require.Equal(t, []string{"Fine &amp; you?"}, calls[1].Texts)
+	require.Equal(t, "How are you?\nFine & you?", calls[1].Options.Context,
+		"previous cue and the batch should be the context")
+}
+
+func TestTranslate_webvtt(t *testing.T) {
+	t.Parallel()
+
+	file, err := subtitle.ParseFile(filepath.Join("testdata", "sample.vtt"))
+	require.NoError(t, err)
+
+	fake := deepltest.NewFakeTranslator()
+
+	_, err = subtitle.Translate(context.Background(), fake, file, subtitle.Options{
+		TranslateOptions: deepl.TranslateOptions{TargetLang: "JA", Context: "A movie about a park."},
+		ContextCues:      -1,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, file.Cues, 2)
+	require.Equal(t, "[JA] <v Roger>We are going\nto the park.", file.Cues[0].Text)
+	require.Equal(t, "[JA] <c.yellow>Do you</c> want to come?", file.Cues[1].Text)
+	require.Equal(t, "00:01.000 --> 00:03.000 align:start position:10%", file.Cues[0].Timing)
+
+	calls := fake.Calls()
+	require.Len(t, calls, 1)
+	require.Equal(t, "A movie about a park.", calls[0].Options.Context, "no context of the cues should be sent")
+}
+
+func TestTranslate_errors(t *testing.T) {
+	t.Parallel()
+
+	fake := deepltest.NewFakeTranslator()
+	opts := subtitle.Options{TranslateOptions: deepl.TranslateOptions{TargetLang: "DE"}}
+
+	_, err := subtitle.Translate(context.Background(), fake, nil, opts)
+	require.Error(t, err, "nil file should be an error")
+
+	file, err := subtitle.ParseFile(filepath.Join("testdata", "sample.srt"))
+	require.NoError(t, err)
+
+	_, err = subtitle.Translate(context.Background(), fake, file, subtitle.Options{})
+	require.Error(t, err, "empty target language should be an error")
+
+	fake.TranslateFunc = func(text string, _ *deepl.TranslateOptions) string {
+		return strings.ReplaceAll(text, `<x id="1"/>`, "")
+	}
+
+	_, err = subtitle.Translate(context.Background(), fake, file, opts)
+	require.Error(t, err, "lost tag should be an error")
+	require.Equal(t, "<i>Hello,</i>\nmy friend.", file.Cues[0].Text, "cue should not be changed on error")
+}
+
+func TestTranslate_defaults(t *testing.T) {
+	t.Parallel()
+
+	file := new(subtitle.File)
+	for index := 0; index < subtitle.DefaultBatchSize+2; index++ {
+		file.Cues = append(file.Cues, &subtitle.Cue{Text: fmt.Sprintf("Line %d", index)})
+	}
+
+	// The cues with no text, such as the ones for the sound effects removed, are
+	// kept as is
+	file.Cues[1].Text = " \n "
+
+	fake := deepltest.NewFakeTranslator()
+
+	result, err := subtitle.Translate(context.Background(), fake, file, subtitle.Options{
+		TranslateOptions: deepl.TranslateOptions{TargetLang: "DE"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, subtitle.DefaultBatchSize+1, result.Translated)
+	require.Equal(t, " \n ", file.Cues[1].Text)
+	require.Equal(t, "[DE] Line 11", file.Cues[11].Text)
+
+	calls := fake.Calls()
+	require.Len(t, calls, 2)
+	require.Len(t, calls[0].Texts, subtitle.DefaultBatchSize)
+	require.Equal(t, []string{"Line 11"}, calls[1].Texts)
+	require.Equal(t, "Line 9\nLine 10\nLine 11", calls[1].Options.Context,
+		"DefaultContextCues of the cues before the batch should be the context")
+}
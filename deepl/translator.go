@@ -0,0 +1,367 @@
+package deepl
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+//  Type: Translator
+// ----------------------------------------------------------------------------
+
+// Translator is the interface of the translation backends. Client implements it.
+//
+// Depend on Translator rather than *Client to replace the backend in tests with
+// a fake, such as deepltest.FakeTranslator, or to compose the behavior with the
+// decorators. Such as NewCachedTranslator, NewRetryTranslator,
+// NewLoggingTranslator and NewFallbackTranslator.
+type Translator interface {
+	// Translate translates the texts with the options. A single text can be
+	// translated as a slice of one element. The translations of the response
+	// must be aligned index-for-index with the texts.
+	Translate(ctx context.Context, texts []string, opts *TranslateOptions) (*TranslateResponse, error)
+	// GetLanguages returns the supported languages of the given type.
+	GetLanguages(ctx context.Context, langType LanguageType) ([]Language, error)
+	// GetAccountStatus returns the usage of the account.
+	GetAccountStatus(ctx context.Context) (*AccountStatus, error)
+}
+
+// Ensure the implementations of Translator.
+var (
+	_ Translator = (*Client)(nil)
+	_ Translator = (*CachedTranslator)(nil)
+	_ Translator = (*RetryTranslator)(nil)
+	_ Translator = (*LoggingTranslator)(nil)
+	_ Translator = (*FallbackTranslator)(nil)
+)
+
+// ----------------------------------------------------------------------------
+//  Type: CachedTranslator
+// ----------------------------------------------------------------------------
+
+// CachedTranslator is a Translator that caches the translations of the next
+// Translator. See WithCache for the details of the cache.
+type CachedTranslator struct {
+	next   Translator
+	cache  *translationCache
+	logger *log.Logger
+}
+
+// NewCachedTranslator returns a new CachedTranslator of next with the backend.
+// If the backend is nil, an in-memory cache with no capacity limit and no
+// expiration is used. The errors of the backend are logged to the logger if not
+// nil.
+func NewCachedTranslator(next Translator, backend CacheBackend, logger *log.Logger) *CachedTranslator {
+	if backend == nil {
+		backend = NewMemoryCache(0, 0)
+	}
+
+	return &CachedTranslator{
+		next:   next,
+		cache:  &translationCache{backend: backend},
+		logger: logger,
+	}
+}
+
+// Translate is the implementation of Translator. Only the texts not in the cache
+// are requested to the next Translator.
+func (t *CachedTranslator) Translate(
+	ctx context.Context,
+	texts []string,
+	opts *TranslateOptions,
+) (*TranslateResponse, error) {
+	if opts == nil {
+		return nil, NewErr("translate options is nil")
+	}
+
+	result := &TranslateResponse{
+		Translations: make([]Translation, len(texts)),
+	}
+
+	pending := t.cache.lookup(ctx, t.logger, texts, opts, result.Translations)
+	if len(pending) == 0 {
+		return result, nil
+	}
+
+	pendingTexts := make([]string, len(pending))
+	for i, index := range pending {
+		pendingTexts[i] = texts[index]
+	}
+
+	transResp, err := t.next.Translate(ctx, pendingTexts, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(transResp.Translations) != len(pendingTexts) {
+		return nil, NewErr("number of translations mismatch. Requested: %d, Returned: %d",
+			len(pendingTexts), len(transResp.Translations))
+	}
+
+	for i, trans := range transResp.Translations {
+		result.Translations[pending[i]] = trans
+		t.cache.store(ctx, t.logger, pendingTexts[i], opts, trans)
+	}
+
+	return result, nil
+}
+
+// GetLanguages is the implementation of Translator. It is not cached.
+func (t *CachedTranslator) GetLanguages(ctx context.Context, langType LanguageType) ([]Language, error) {
+	return t.next.GetLanguages(ctx, langType)
+}
+
+// GetAccountStatus is the implementation of Translator. It is not cached.
+func (t *CachedTranslator) GetAccountStatus(ctx context.Context) (*AccountStatus, error) {
+	return t.next.GetAccountStatus(ctx)
+}
+
+// Stats returns the hit and miss counts of the cache.
+func (t *CachedTranslator) Stats() CacheStats {
+	return t.cache.stats()
+}
+
+// ----------------------------------------------------------------------------
+//  Type: RetryTranslator
+// ----------------------------------------------------------------------------
+
+// RetryTranslator is a Translator that retries the calls of the next Translator.
+// Use NewRetryTranslator to create one.
+type RetryTranslator struct {
+	next   Translator
+	policy RetryPolicy
+}
+
+// NewRetryTranslator returns a new RetryTranslator that retries the calls to
+// next with the policy if they fail with the retryable errors. See IsRetryable.
+//
+// Unlike WithRetryPolicy, it works with any Translator but the "Retry-After"
+// header is not available.
+func NewRetryTranslator(next Translator, policy RetryPolicy) *RetryTranslator {
+	return &RetryTranslator{next: next, policy: policy}
+}
+
+// Translate is the implementation of Translator.
+func (t *RetryTranslator) Translate(
+	ctx context.Context,
+	texts []string,
+	opts *TranslateOptions,
+) (resp *TranslateResponse, err error) {
+	err = t.retry(ctx, func() error {
+		resp, err = t.next.Translate(ctx, texts, opts)
+
+		return err
+	})
+
+	return resp, err
+}
+
+// GetLanguages is the implementation of Translator.
+func (t *RetryTranslator) GetLanguages(
+	ctx context.Context,
+	langType LanguageType,
+) (languages []Language, err error) {
+	err = t.retry(ctx, func() error {
+		languages, err = t.next.GetLanguages(ctx, langType)
+
+		return err
+	})
+
+	return languages, err
+}
+
+// GetAccountStatus is the implementation of Translator.
+func (t *RetryTranslator) GetAccountStatus(ctx context.Context) (status *AccountStatus, err error) {
+	err = t.retry(ctx, func() error {
+		status, err = t.next.GetAccountStatus(ctx)
+
+		return err
+	})
+
+	return status, err
+}
+
+// retry calls the function until it succeeds, fails with a non-retryable error
+// or the attempts run out.
+func (t *RetryTranslator) retry(ctx context.Context, call func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := call()
+		if err == nil || attempt >= t.policy.MaxAttempts || !IsRetryable(err) {
+			return err
+		}
+
+		delay := t.policy.backoff(attempt)
+
+		// Give up if the delay exceeds the deadline
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+			return err
+		}
+
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return WrapIfErr(ctx.Err(), "stopped retrying. Last error: %v", err)
+		case <-timer.C:
+		}
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  Type: LoggingTranslator
+// ----------------------------------------------------------------------------
+
+// LoggingTranslator is a Translator that logs the calls of the next Translator.
+// Use NewLoggingTranslator to create one.
+type LoggingTranslator struct {
+	next   Translator
+	logger *log.Logger
+}
+
+// NewLoggingTranslator returns a new LoggingTranslator that logs the calls to
+// next with the elapsed time and the error if any. The texts are not logged.
+func NewLoggingTranslator(next Translator, logger *log.Logger) *LoggingTranslator {
+	if logger == nil {
+		logger = newDefaultLogger()
+	}
+
+	return &LoggingTranslator{next: next, logger: logger}
+}
+
+// Translate is the implementation of Translator.
+func (t *LoggingTranslator) Translate(
+	ctx context.Context,
+	texts []string,
+	opts *TranslateOptions,
+) (*TranslateResponse, error) {
+	timeStart := time.Now()
+	resp, err := t.next.Translate(ctx, texts, opts)
+
+	targetLang := ""
+	if opts != nil {
+		targetLang = opts.TargetLang
+	}
+
+	t.log(timeStart, err, "Translate texts=%d target_lang=%s", len(texts), targetLang)
+
+	return resp, err
+}
+
+// GetLanguages is the implementation of Translator.
+func (t *LoggingTranslator) GetLanguages(ctx context.Context, langType LanguageType) ([]Language, error) {
+	timeStart := time.Now()
+	languages, err := t.next.GetLanguages(ctx, langType)
+
+	t.log(timeStart, err, "GetLanguages type=%s", langType)
+
+	return languages, err
+}
+
+// GetAccountStatus is the implementation of Translator.
+func (t *LoggingTranslator) GetAccountStatus(ctx context.Context) (*AccountStatus, error) {
+	timeStart := time.Now()
+	status, err := t.next.GetAccountStatus(ctx)
+
+	t.log(timeStart, err, "GetAccountStatus")
+
+	return status, err
+}
+
+// log logs the call with the elapsed time since timeStart and the error.
+func (t *LoggingTranslator) log(timeStart time.Time, err error, format string, args ...interface{}) {
+	args = append(args, time.Since(timeStart))
+
+	if err != nil {
+		t.logger.Printf(format+" elapsed=%v error=%v", append(args, err)...)
+
+		return
+	}
+
+	t.logger.Printf(format+" elapsed=%v", args...)
+}
+
+// ----------------------------------------------------------------------------
+//  Type: FallbackTranslator
+// ----------------------------------------------------------------------------
+
+// FallbackTranslator is a Translator that falls back to the next ones on the
+// retryable errors and the exceeded quota. Use NewFallbackTranslator to create
+// one.
+type FallbackTranslator struct {
+	translators []Translator
+}
+
+// NewFallbackTranslator returns a new FallbackTranslator that calls the
+// translators in order until one succeeds. Such as the Pro account falling back
+// to the Free one when the quota is exceeded.
+//
+// It only falls back if the error is retryable (see IsRetryable) or the quota
+// is exceeded (ErrQuotaExceeded). The other errors, such as the bad requests,
+// would fail again on the next translators and are returned as is. It does not
+// fall back if the context is done.
+func NewFallbackTranslator(primary Translator, fallbacks ...Translator) *FallbackTranslator {
+	return &FallbackTranslator{translators: append([]Translator{primary}, fallbacks...)}
+}
+
+// Translate is the implementation of Translator.
+func (t *FallbackTranslator) Translate(
+	ctx context.Context,
+	texts []string,
+	opts *TranslateOptions,
+) (resp *TranslateResponse, err error) {
+	err = t.each(ctx, func(translator Translator) error {
+		resp, err = translator.Translate(ctx, texts, opts)
+
+		return err
+	})
+
+	return resp, err
+}
+
+// GetLanguages is the implementation of Translator.
+func (t *FallbackTranslator) GetLanguages(
+	ctx context.Context,
+	langType LanguageType,
+) (languages []Language, err error) {
+	err = t.each(ctx, func(translator Translator) error {
+		languages, err = translator.GetLanguages(ctx, langType)
+
+		return err
+	})
+
+	return languages, err
+}
+
+// GetAccountStatus is the implementation of Translator. It returns the status
+// of the first Translator that succeeds.
+func (t *FallbackTranslator) GetAccountStatus(ctx context.Context) (status *AccountStatus, err error) {
+	err = t.each(ctx, func(translator Translator) error {
+		status, err = translator.GetAccountStatus(ctx)
+
+		return err
+	})
+
+	return status, err
+}
+
+// each calls the function with the translators in order until one succeeds or
+// fails with the error not to fall back.
+func (t *FallbackTranslator) each(ctx context.Context, call func(translator Translator) error) error {
+	var err error
+
+	for _, translator := range t.translators {
+		if err = call(translator); err == nil {
+			return nil
+		}
+
+		if ctx.Err() != nil || !(IsRetryable(err) || errors.Is(err, ErrQuotaExceeded)) {
+			return err
+		}
+	}
+
+	return WrapIfErr(err, "all of the %d translators failed", len(t.translators))
+}
@@ -0,0 +1,320 @@
+package deepl
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubTranslator is a Translator that translates the texts to "TARGET:text" or
+// fails with the errors in order. The last error is repeated. A nil error
+// means success.
+type stubTranslator struct {
+	errs  []error
+	calls int32
+}
+
+func (s *stubTranslator) nextErr() error {
+	index := int(atomic.AddInt32(&s.calls, 1)) - 1
+	if len(s.errs) == 0 {
+		return nil
+	}
+
+	if index >= len(s.errs) {
+		index = len(s.errs) - 1
+	}
+
+	return s.errs[index]
+}
+
+func (s *stubTranslator) Translate(_ context.Context, texts []string, opts *TranslateOptions) (*TranslateResponse, error) {
+	if err := s.nextErr(); err != nil {
+		return nil, err
+	}
+
+	resp := new(TranslateResponse)
+	for _, text := range texts {
+		resp.Translations = append(resp.Translations, Translation{Text: opts.TargetLang + ":" + text})
+	}
+
+	return resp, nil
+}
+
+func (s *stubTranslator) GetLanguages(context.Context, LanguageType) ([]Language, error) {
+	if err := s.nextErr(); err != nil {
+		return nil, err
+	}
+
+	return []Language{{Code: "DE", Name: "German"}}, nil
+}
+
+func (s *stubTranslator) GetAccountStatus(context.Context) (*AccountStatus, error) {
+	if err := s.nextErr(); err != nil {
+		return nil, err
+	}
+
+	return &AccountStatus{CharacterCount: 1, CharacterLimit: 10}, nil
+}
+
+var (
+	errTransient = &APIError{StatusCode: 503, Message: "scripted error"}
+	errFatal     = &APIError{StatusCode: 403, Message: "scripted error"}
+	optsDE       = &TranslateOptions{TargetLang: "DE"}
+)
+
+// ----------------------------------------------------------------------------
+//  IsRetryable
+// ----------------------------------------------------------------------------
+
+func TestIsRetryable(t *testing.T) {
+	t.Parallel()
+
+	require.False(t, IsRetryable(nil))
+	require.True(t, IsRetryable(WrapIfErr(errTransient, "wrapped")))
+	require.False(t, IsRetryable(errFatal))
+	require.True(t, IsRetryable(WrapIfErr(&net.OpError{Op: "dial", Err: errors.New("refused")}, "wrapped")))
+	require.False(t, IsRetryable(WrapIfErr(context.DeadlineExceeded, "wrapped")))
+	require.False(t, IsRetryable(errors.New("unknown")))
+}
+
+// ----------------------------------------------------------------------------
+//  CachedTranslator
+// ----------------------------------------------------------------------------
+
+func TestCachedTranslator(t *testing.T) {
+	t.Parallel()
+
+	stub := new(stubTranslator)
+	translator := NewCachedTranslator(stub, NewMemoryCache(0, 0), nil)
+
+	_, err := translator.Translate(context.Background(), []string{"Hello"}, optsDE)
+	require.NoError(t, err)
+
+	resp, err := translator.Translate(context.Background(), []string{"World", "Hello"}, optsDE)
+	require.NoError(t, err)
+	require.Equal(t, "DE:World", resp.Translations[0].Text)
+	require.Equal(t, "DE:Hello", resp.Translations[1].Text)
+
+	_, err = translator.Translate(context.Background(), []string{"Hello", "World"}, optsDE)
+	require.NoError(t, err)
+
+	require.Equal(t, int32(2), atomic.LoadInt32(&stub.calls), "all cached call should not reach the next")
+	require.Equal(t, CacheStats{Hits: 3, Misses: 2}, translator.Stats())
+
+	_, err = translator.Translate(context.Background(), nil, nil)
+	require.Error(t, err)
+
+	languages, err := translator.GetLanguages(context.Background(), LanguageTypeTarget)
+	require.NoError(t, err)
+	require.Len(t, languages, 1)
+
+	status, err := translator.GetAccountStatus(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(1), status.CharacterCount)
+}
+
+func TestCachedTranslator_nil_backend(t *testing.T) {
+	t.Parallel()
+
+	stub := new(stubTranslator)
+	translator := NewCachedTranslator(stub, nil, nil)
+
+	for range []int{1, 2} {
+		resp, err := translator.Translate(context.Background(), []string{"Hello"}, optsDE)
+		require.NoError(t, err, "nil backend should not panic")
+		require.Equal(t, "DE:Hello", resp.Translations[0].Text)
+	}
+
+	require.Equal(t, int32(1), atomic.LoadInt32(&stub.calls), "nil backend should cache in memory")
+}
+
+func TestCachedTranslator_error(t *testing.T) {
+	t.Parallel()
+
+	translator := NewCachedTranslator(&stubTranslator{errs: []error{errFatal}}, NewMemoryCache(0, 0), nil)
+
+	_, err := translator.Translate(context.Background(), []string{"Hello"}, optsDE)
+	require.ErrorIs(t, err, ErrForbidden)
+}
+
+// ----------------------------------------------------------------------------
+//  NewRetryTranslator
+// ----------------------------------------------------------------------------
+
+func TestNewRetryTranslator(t *testing.T) {
+	t.Parallel()
+
+	stub := &stubTranslator{errs: []error{errTransient, errTransient, nil}}
+	translator := NewRetryTranslator(stub, testRetryPolicy)
+
+	resp, err := translator.Translate(context.Background(), []string{"Hello"}, optsDE)
+	require.NoError(t, err)
+	require.Equal(t, "DE:Hello", resp.Translations[0].Text)
+	require.Equal(t, int32(3), atomic.LoadInt32(&stub.calls))
+}
+
+func TestNewRetryTranslator_gives_up(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		name       string
+		errs       []error
+		expectCall int32
+	}{
+		{name: "attempts run out", errs: []error{errTransient}, expectCall: 3},
+		{name: "not retryable", errs: []error{errFatal}, expectCall: 1},
+	} {
+		stub := &stubTranslator{errs: test.errs}
+		translator := NewRetryTranslator(stub, testRetryPolicy)
+
+		_, err := translator.GetLanguages(context.Background(), LanguageTypeSource)
+		require.Error(t, err, test.name)
+
+		_, err = translator.GetAccountStatus(context.Background())
+		require.Error(t, err, test.name)
+		require.Equal(t, test.expectCall*2, atomic.LoadInt32(&stub.calls), test.name)
+	}
+}
+
+func TestNewRetryTranslator_context_deadline(t *testing.T) {
+	t.Parallel()
+
+	policy := testRetryPolicy
+	policy.BaseDelay = time.Hour
+	policy.MaxDelay = time.Hour
+
+	stub := &stubTranslator{errs: []error{errTransient}}
+	translator := NewRetryTranslator(stub, policy)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	_, err := translator.Translate(ctx, []string{"Hello"}, optsDE)
+	require.ErrorIs(t, err, ErrServiceUnavailable, "it should give up with the last error")
+	require.Equal(t, int32(1), atomic.LoadInt32(&stub.calls))
+
+	// Canceled while waiting
+	policy.BaseDelay = 10 * time.Second
+	policy.MaxDelay = 10 * time.Second
+	translator = NewRetryTranslator(stub, policy)
+
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel2)
+
+	_, err = translator.Translate(ctx2, []string{"Hello"}, optsDE)
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Contains(t, err.Error(), "stopped retrying")
+}
+
+// ----------------------------------------------------------------------------
+//  NewLoggingTranslator
+// ----------------------------------------------------------------------------
+
+func TestNewLoggingTranslator(t *testing.T) {
+	t.Parallel()
+
+	var logOut strings.Builder
+
+	translator := NewLoggingTranslator(
+		&stubTranslator{errs: []error{nil, nil, errFatal}},
+		log.New(&logOut, "", 0),
+	)
+
+	_, err := translator.Translate(context.Background(), []string{"Secret", "Text"}, optsDE)
+	require.NoError(t, err)
+
+	_, err = translator.GetLanguages(context.Background(), LanguageTypeTarget)
+	require.NoError(t, err)
+
+	_, err = translator.GetAccountStatus(context.Background())
+	require.Error(t, err)
+
+	lines := strings.Split(strings.TrimSpace(logOut.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[0], "Translate texts=2 target_lang=DE elapsed=")
+	assert.Contains(t, lines[1], "GetLanguages type=target elapsed=")
+	assert.Contains(t, lines[2], "GetAccountStatus elapsed=")
+	assert.Contains(t, lines[2], "error=Authorization failed.")
+	assert.NotContains(t, logOut.String(), "Secret", "texts should not be logged")
+
+	require.NotNil(t, NewLoggingTranslator(new(stubTranslator), nil), "nil logger should use the default")
+}
+
+// ----------------------------------------------------------------------------
+//  NewFallbackTranslator
+// ----------------------------------------------------------------------------
+
+func TestNewFallbackTranslator(t *testing.T) {
+	t.Parallel()
+
+	primary := &stubTranslator{errs: []error{&APIError{StatusCode: StatusQuotaExceeded}}}
+	fallback := new(stubTranslator)
+	translator := NewFallbackTranslator(primary, fallback)
+
+	resp, err := translator.Translate(context.Background(), []string{"Hello"}, optsDE)
+	require.NoError(t, err)
+	require.Equal(t, "DE:Hello", resp.Translations[0].Text)
+
+	_, err = translator.GetLanguages(context.Background(), LanguageTypeTarget)
+	require.NoError(t, err)
+
+	_, err = translator.GetAccountStatus(context.Background())
+	require.NoError(t, err)
+
+	require.Equal(t, int32(3), atomic.LoadInt32(&primary.calls))
+	require.Equal(t, int32(3), atomic.LoadInt32(&fallback.calls))
+}
+
+func TestNewFallbackTranslator_all_failed(t *testing.T) {
+	t.Parallel()
+
+	translator := NewFallbackTranslator(
+		&stubTranslator{errs: []error{errTransient}},
+		&stubTranslator{errs: []error{&APIError{StatusCode: StatusQuotaExceeded}}},
+	)
+
+	_, err := translator.Translate(context.Background(), []string{"Hello"}, optsDE)
+	require.Error(t, err)
+	require.ErrorIs(t, err, ErrQuotaExceeded, "it should return the last error")
+	assert.Contains(t, err.Error(), "all of the 2 translators failed")
+}
+
+func TestNewFallbackTranslator_no_fallback(t *testing.T) {
+	t.Parallel()
+
+	for _, errPrimary := range []error{
+		&APIError{StatusCode: 400, Message: "scripted error"},
+		errFatal,
+	} {
+		primary := &stubTranslator{errs: []error{errPrimary}}
+		fallback := new(stubTranslator)
+
+		_, err := NewFallbackTranslator(primary, fallback).Translate(context.Background(), []string{"Hello"}, optsDE)
+
+		require.ErrorIs(t, err, errPrimary, "the error should be returned as is")
+		assert.NotContains(t, err.Error(), "translators failed")
+		require.Zero(t, atomic.LoadInt32(&fallback.calls), "it should not fall back on non-retryable errors")
+	}
+}
+
+func TestNewFallbackTranslator_context_done(t *testing.T) {
+	t.Parallel()
+
+	primary := &stubTranslator{errs: []error{context.Canceled}}
+	fallback := new(stubTranslator)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := NewFallbackTranslator(primary, fallback).Translate(ctx, []string{"Hello"}, optsDE)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Zero(t, atomic.LoadInt32(&fallback.calls), "it should not fall back")
+}
@@ -0,0 +1,99 @@
+package deepl
+
+import "math"
+
+// UnlimitedThreshold is the limit from which the quota is treated as "no limit".
+// The Pro accounts without the cost control report the limit as 1e12.
+const UnlimitedThreshold int64 = 1_000_000_000_000
+
+// ----------------------------------------------------------------------------
+//  Type: Usage
+// ----------------------------------------------------------------------------
+
+// Usage is the count and the limit of a quota. Such as characters or documents.
+type Usage struct {
+	Count int64
+	Limit int64
+}
+
+// Unlimited returns true if the quota has no limit. Which is when the limit is
+// not reported (zero) or it is UnlimitedThreshold or more.
+func (u Usage) Unlimited() bool {
+	return u.Limit <= 0 || u.Limit >= UnlimitedThreshold
+}
+
+// Remaining returns the remaining count of the quota. It returns math.MaxInt64
+// if unlimited and zero if the count exceeds the limit.
+func (u Usage) Remaining() int64 {
+	if u.Unlimited() {
+		return math.MaxInt64
+	}
+
+	if u.Count >= u.Limit {
+		return 0
+	}
+
+	return u.Limit - u.Count
+}
+
+// PercentUsed returns the used percentage of the quota. Such as 42.5 for 42.5%.
+// It returns zero if unlimited.
+func (u Usage) PercentUsed() float64 {
+	if u.Unlimited() {
+		return 0
+	}
+
+	return float64(u.Count) * 100 / float64(u.Limit)
+}
+
+// LimitReached returns true if the count reached the limit.
+func (u Usage) LimitReached() bool {
+	return !u.Unlimited() && u.Count >= u.Limit
+}
+
+// ----------------------------------------------------------------------------
+//  Methods of AccountStatus
+// ----------------------------------------------------------------------------
+
+// Characters returns the character usage of the account.
+func (a *AccountStatus) Characters() Usage {
+	return Usage{Count: a.CharacterCount, Limit: a.CharacterLimit}
+}
+
+// Documents returns the document usage of the account.
+func (a *AccountStatus) Documents() Usage {
+	return Usage{Count: a.DocumentCount, Limit: a.DocumentLimit}
+}
+
+// TeamDocuments returns the document usage of the team.
+func (a *AccountStatus) TeamDocuments() Usage {
+	return Usage{Count: a.TeamDocumentCount, Limit: a.TeamDocumentLimit}
+}
+
+// APIKeyCharacters returns the character usage of the API key. The limit is
+// unlimited if the key has no limit or the account is not Pro.
+func (a *AccountStatus) APIKeyCharacters() Usage {
+	return Usage{Count: a.APIKeyCharacterCount, Limit: a.APIKeyCharacterLimit}
+}
+
+// Remaining returns the remaining characters of the account. It returns
+// math.MaxInt64 if unlimited.
+func (a *AccountStatus) Remaining() int64 {
+	return a.Characters().Remaining()
+}
+
+// PercentUsed returns the used percentage of the characters of the account. It
+// returns zero if unlimited.
+func (a *AccountStatus) PercentUsed() float64 {
+	return a.Characters().PercentUsed()
+}
+
+// LimitReached returns true if any of the character, document, team document or
+// API key character limits is reached. Further requests of the kind will fail
+// with ErrQuotaExceeded.
+func (a *AccountStatus) LimitReached() bool {
+	return a.Characters().LimitReached() ||
+		a.Documents().LimitReached() ||
+		a.TeamDocuments().LimitReached() ||
+		a.APIKeyCharacters().LimitReached()
+}
@@ -0,0 +1,115 @@
+package deepl
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// timePtr returns the pointer of the given time.
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+// ----------------------------------------------------------------------------
+//  Usage
+// ----------------------------------------------------------------------------
+
+func TestUsage(t *testing.T) {
+	t.Parallel()
+
+	for index, test := range []struct {
+		usage        Usage
+		name         string
+		remaining    int64
+		percentUsed  float64
+		unlimited    bool
+		limitReached bool
+	}{
+		{name: "in use", usage: Usage{Count: 250, Limit: 1000}, remaining: 750, percentUsed: 25},
+		{name: "reached", usage: Usage{Count: 1000, Limit: 1000}, remaining: 0, percentUsed: 100, limitReached: true},
+		{name: "exceeded", usage: Usage{Count: 1001, Limit: 1000}, remaining: 0, percentUsed: 100.1, limitReached: true},
+		{name: "no limit reported", usage: Usage{Count: 12, Limit: 0}, remaining: math.MaxInt64, unlimited: true},
+		{
+			name:      "pro unlimited",
+			usage:     Usage{Count: 5947223, Limit: UnlimitedThreshold},
+			remaining: math.MaxInt64,
+			unlimited: true,
+		},
+	} {
+		test := test
+
+		t.Run(fmt.Sprintf("test #%d: %s", index+1, test.name), func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, test.unlimited, test.usage.Unlimited())
+			require.Equal(t, test.remaining, test.usage.Remaining())
+			require.InDelta(t, test.percentUsed, test.usage.PercentUsed(), 0.001)
+			require.Equal(t, test.limitReached, test.usage.LimitReached())
+		})
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  AccountStatus
+// ----------------------------------------------------------------------------
+
+func TestAccountStatus_helpers(t *testing.T) {
+	t.Parallel()
+
+	status := &AccountStatus{
+		CharacterCount:    30315,
+		CharacterLimit:    1000000,
+		DocumentCount:     5,
+		DocumentLimit:     10,
+		TeamDocumentCount: 3,
+		TeamDocumentLimit: 0,
+	}
+
+	require.Equal(t, int64(969685), status.Remaining())
+	require.InDelta(t, 3.0315, status.PercentUsed(), 0.0001)
+	require.False(t, status.LimitReached())
+	require.Equal(t, Usage{Count: 5, Limit: 10}, status.Documents())
+	require.InDelta(t, 50.0, status.Documents().PercentUsed(), 0.0001, "document quota should not be dropped")
+	require.True(t, status.TeamDocuments().Unlimited())
+	require.True(t, status.APIKeyCharacters().Unlimited())
+
+	status.DocumentCount = 10
+	require.True(t, status.LimitReached(), "document limit should count")
+}
+
+func TestAccountStatus_decode_pro_unlimited(t *testing.T) {
+	t.Parallel()
+
+	var status AccountStatus
+
+	// The limit overflows int on the 32-bit platforms
+	err := json.Unmarshal([]byte(`{"character_count":5947223,"character_limit":1000000000000}`), &status)
+
+	require.NoError(t, err)
+	require.Equal(t, UnlimitedThreshold, status.CharacterLimit)
+	require.True(t, status.Characters().Unlimited())
+}
+
+func TestAccountStatus_helpers_pro_unlimited(t *testing.T) {
+	t.Parallel()
+
+	status := &AccountStatus{
+		CharacterCount:       5947223,
+		CharacterLimit:       UnlimitedThreshold,
+		APIKeyCharacterCount: 900,
+		APIKeyCharacterLimit: 1000,
+	}
+
+	require.Equal(t, int64(math.MaxInt64), status.Remaining())
+	require.Zero(t, status.PercentUsed())
+	require.False(t, status.LimitReached())
+	require.Equal(t, int64(100), status.APIKeyCharacters().Remaining())
+
+	status.APIKeyCharacterCount = 1000
+	require.True(t, status.LimitReached(), "API key limit should count")
+}
@@ -0,0 +1,136 @@
+package xliff
+
+import (
+	"context"
+	"strings"
+
+	"github.com/KEINOS/go-deepl/deepl"
+	"github.com/KEINOS/go-deepl/deepl/placeholder"
+)
+
+// ignoreTagsV1 are the inline elements of XLIFF 1.2 whose content is the native
+// code. Such as "<ph id="1">&lt;br/&gt;</ph>".
+var ignoreTagsV1 = deepl.Tags{"ph", "bpt", "ept", "it"}
+
+// ----------------------------------------------------------------------------
+//  Type: Options
+// ----------------------------------------------------------------------------
+
+// Options are the options of Translate.
+type Options struct {
+	// TranslateOptions are the options of the translation. TargetLang is
+	// required. TagHandling is overridden since the inner XML of the sources is
+	// sent as is. The native code elements of XLIFF 1.2 are added to IgnoreTags.
+	TranslateOptions deepl.TranslateOptions
+}
+
+// Result is the result of Translate.
+type Result struct {
+	// Translated is the number of the units translated.
+	Translated int
+	// Skipped is the number of the units skipped. Such as the translated units
+	// and the units with translate="no".
+	Skipped int
+}
+
+// ----------------------------------------------------------------------------
+//  Functions
+// ----------------------------------------------------------------------------
+
+// Translate fills the empty targets of the units of the document in place with
+// the translator. The translated units are marked to be reviewed. Which is the
+// state="needs-review-translation" of the <target> for XLIFF 1.2, and the
+// state="translated" with the SubStateNeedsReview of the <segment> for XLIFF
+// 2.0. The units with any target are never modified.
+//
+// The inner XML of the <source> is sent with the XML tag handling so that the
+// inline elements are kept in the translation. The content of the native code
+// elements of XLIFF 1.2, such as <ph> and <bpt>, is not translated.
+func Translate(ctx context.Context, translator deepl.Translator, doc *Document, opts Options) (*Result, error) {
+	if doc == nil {
+		return nil, deepl.NewErr("XLIFF document is nil")
+	}
+
+	if opts.TranslateOptions.TargetLang == "" {
+		return nil, deepl.NewErr("target language is empty")
+	}
+
+	result := new(Result)
+
+	var (
+		pending []*Unit
+		texts   []string
+		affixes [][2]string
+	)
+
+	for _, unit := range doc.Units {
+		core := strings.TrimSpace(unit.Source)
+		if unit.NoTranslate || unit.IsTranslated() || core == "" {
+			result.Skipped++
+
+			continue
+		}
+
+		start := strings.Index(unit.Source, core)
+
+		pending = append(pending, unit)
+		texts = append(texts, core)
+		affixes = append(affixes, [2]string{unit.Source[:start], unit.Source[start+len(core):]})
+	}
+
+	if len(pending) == 0 {
+		return result, nil
+	}
+
+	transOpts := opts.TranslateOptions
+
+	if !doc.IsV2() {
+		transOpts.IgnoreTags = append(append(deepl.Tags(nil), transOpts.IgnoreTags...), ignoreTagsV1...)
+	}
+
+	// The sources are XML by themselves
+	translated, err := placeholder.Translate(ctx, translator, texts, nil, transOpts)
+	if err != nil {
+		return nil, deepl.WrapIfErr(err, "failed to translate XLIFF units")
+	}
+
+	for index, unit := range pending {
+		unit.Target = affixes[index][0] + strings.TrimSpace(translated[index]) + affixes[index][1]
+		unit.State = StateNeedsReviewTranslation
+		unit.SubState = StateQualifierMT
+
+		if doc.IsV2() {
+			unit.State = StateTranslated
+			unit.SubState = SubStateNeedsReview
+		}
+	}
+
+	result.Translated = len(pending)
+
+	return result, nil
+}
+
+// TranslateFile translates the XLIFF file and writes it to the target file.
+func TranslateFile(
+	ctx context.Context,
+	translator deepl.Translator,
+	sourcePath string,
+	targetPath string,
+	opts Options,
+) (*Result, error) {
+	doc, err := ParseFile(sourcePath)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := Translate(ctx, translator, doc, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := doc.WriteFile(targetPath); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,121 @@
+package xliff_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/KEINOS/go-deepl/deepl"
+	"github.com/KEINOS/go-deepl/deepl/deepltest"
+	"github.com/KEINOS/go-deepl/deepl/xliff"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslateFile(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		source     string
+		expected   string
+		targetLang string
+		ignoreTags deepl.Tags
+		translated int
+	}{
+		{
+			source:     "sample-v12.xlf",
+			expected:   "sample-v12.de.xlf",
+			targetLang: "DE",
+			ignoreTags: deepl.Tags{"ph", "bpt", "ept", "it"},
+			translated: 2,
+		},
+		{
+			source:     "sample-v12-alt-trans.xlf",
+			expected:   "sample-v12-alt-trans.de.xlf",
+			targetLang: "DE",
+			ignoreTags: deepl.Tags{"ph", "bpt", "ept", "it"},
+			translated: 1,
+		},
+		{
+			source:     "sample-v20.xlf",
+			expected:   "sample-v20.ja.xlf",
+			targetLang: "JA",
+			translated: 2,
+		},
+	} {
+		pathTarget := filepath.Join(t.TempDir(), test.expected)
+		fake := deepltest.NewFakeTranslator()
+
+		result, err := xliff.TranslateFile(context.Background(), fake, filepath.Join("testdata", test.source), pathTarget,
+			xliff.Options{
+				TranslateOptions: deepl.TranslateOptions{SourceLang: "EN", TargetLang: test.targetLang},
+			})
+		require.NoError(t, err)
+		require.Equal(t, test.translated, result.Translated)
+
+		expected, err := os.ReadFile(filepath.Join("testdata", test.expected))
+		require.NoError(t, err)
+
+		actual, err := os.ReadFile(pathTarget)
+		require.NoError(t, err)
+		require.Equal(t, string(expected), string(actual))
+
+		calls := fake.Calls()
+		require.Len(t, calls, 1)
+		require.Equal(t, deepl.TagHandlingXML, calls[0].Options.TagHandling)
+		require.Equal(t, test.ignoreTags, calls[0].Options.IgnoreTags)
+
+		// Nothing to translate on the second run
+		result, err = xliff.TranslateFile(context.Background(), fake, pathTarget, pathTarget, xliff.Options{
+			TranslateOptions: deepl.TranslateOptions{TargetLang: test.targetLang},
+		})
+		require.NoError(t, err)
+		require.Zero(t, result.Translated)
+		require.Len(t, fake.Calls(), 1)
+	}
+}
+
+func TestTranslate_errors(t *testing.T) {
+	t.Parallel()
+
+	fake := deepltest.NewFakeTranslator()
+	opts := xliff.Options{TranslateOptions: deepl.TranslateOptions{TargetLang: "DE"}}
+
+	_, err := xliff.Translate(context.Background(), fake, nil, opts)
+	require.Error(t, err, "nil document should be an error")
+
+	doc, err := xliff.ParseFile(filepath.Join("testdata", "sample-v12.xlf"))
+	require.NoError(t, err)
+
+	_, err = xliff.Translate(context.Background(), fake, doc, xliff.Options{})
+	require.Error(t, err, "empty target language should be an error")
+	require.Empty(t, fake.Calls())
+}
+
+func TestTranslate_ignore_tags(t *testing.T) {
+	t.Parallel()
+
+	doc, err := xliff.Parse([]byte(`<xliff version="1.2"><file><body>
+<trans-unit id="1"><source>
+  Run <ph id="1">ls</ph> in <code>/tmp</code>
+</source></trans-unit>
+</body></file></xliff>`))
+	require.NoError(t, err)
+
+	fake := deepltest.NewFakeTranslator()
+	ignoreTags := deepl.Tags{"code"}
+
+	_, err = xliff.Translate(context.Background(), fake, doc, xliff.Options{
+		TranslateOptions: deepl.TranslateOptions{TargetLang: "DE", IgnoreTags: ignoreTags},
+	})
+	require.NoError(t, err)
+
+	calls := fake.Calls()
+	require.Equal(t, deepl.Tags{"code", "ph", "bpt", "ept", "it"}, calls[0].Options.IgnoreTags,
+		"native code elements should be added to the tags of the user")
+	require.Equal(t, deepl.Tags{"code"}, ignoreTags, "tags of the user should not be changed")
+	require.Equal(t, []string{`Run <ph id="1">ls</ph> in <code>/tmp</code>`}, calls[0].Texts,
+		"spaces around the source should not be sent")
+	require.Equal(t, "\n  [DE] Run <ph id=\"1\">ls</ph> in <code>/tmp</code>\n", doc.Units[0].Target,
+		"spaces around the source should be kept in the target")
+}
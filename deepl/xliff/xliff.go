@@ -0,0 +1,459 @@
+/*
+Package xliff reads and writes the XLIFF 1.2 and 2.0 files and pre-translates
+the segments with DeepL before the human review.
+
+	doc, err := xliff.ParseFile("project.de.xlf")
+
+	result, err := xliff.Translate(ctx, cli, doc, xliff.Options{
+		TranslateOptions: deepl.TranslateOptions{SourceLang: "EN", TargetLang: "DE"},
+	})
+
+	err = doc.WriteFile("project.de.xlf")
+
+Only the translated <target> elements and the states are rewritten. The rest of
+the file, such as the namespaces, the comments and the skeletons, is written
+back byte for byte.
+*/
+package xliff
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/KEINOS/go-deepl/deepl"
+)
+
+// States of the machine-translated units.
+const (
+	// StateNeedsReviewTranslation is the state of XLIFF 1.2 set to the targets.
+	StateNeedsReviewTranslation = "needs-review-translation"
+	// StateQualifierMT is the state-qualifier of XLIFF 1.2 set to the targets.
+	StateQualifierMT = "mt-suggestion"
+	// StateTranslated is the state of XLIFF 2.0 set to the segments. Which means
+	// "translated but not reviewed yet".
+	StateTranslated = "translated"
+	// SubStateNeedsReview is the subState of XLIFF 2.0 set to the segments since
+	// XLIFF 2.0 has no state of "needs-review-translation".
+	SubStateNeedsReview = "deepl:" + StateNeedsReviewTranslation
+)
+
+// ----------------------------------------------------------------------------
+//  Type: Unit
+// ----------------------------------------------------------------------------
+
+// Unit is a translatable segment. Which is a <trans-unit> of XLIFF 1.2 or a
+// <segment> of XLIFF 2.0.
+type Unit struct {
+	// ID is the id of the <trans-unit>. For XLIFF 2.0, it is the id of the
+	// <unit> followed by "/" and the id of the <segment> if the segment has it.
+	ID string
+	// Source is the inner XML of the <source>. Including the inline elements.
+	Source string
+	// Target is the inner XML of the <target>.
+	Target string
+	// State is the state of the <target> for XLIFF 1.2 or the <segment> for
+	// XLIFF 2.0.
+	State string
+	// SubState is the subState of the <segment> of XLIFF 2.0 or the
+	// state-qualifier of the <target> of XLIFF 1.2.
+	SubState string
+	// HasTarget is true if the unit has the <target> element. Even if empty.
+	HasTarget bool
+	// NoTranslate is true if the unit or its parent has translate="no".
+	NoTranslate bool
+
+	orig unitOffsets
+}
+
+// unitOffsets are the original values and the byte offsets of the unit in the
+// data.
+type unitOffsets struct {
+	target   string
+	state    string
+	subState string
+	// stateTag is the range of the start tag with the state attribute. Which
+	// is the <target> for XLIFF 1.2 or the <segment> for XLIFF 2.0.
+	stateTagStart int
+	stateTagEnd   int
+	// the whole <source> element
+	sourceStart int
+	sourceEnd   int
+	// the whole <target> element
+	targetStart int
+	targetEnd   int
+	targetTag   string
+}
+
+// IsTranslated returns true if the target has any content.
+func (u *Unit) IsTranslated() bool {
+	return strings.TrimSpace(u.Target) != ""
+}
+
+// ----------------------------------------------------------------------------
+//  Type: Document
+// ----------------------------------------------------------------------------
+
+// Document is an XLIFF file.
+type Document struct {
+	// Version is the version of the file. Such as "1.2" and "2.0".
+	Version string
+	// SourceLanguage is the source language of the (first) file.
+	SourceLanguage string
+	// TargetLanguage is the target language of the (first) file.
+	TargetLanguage string
+	// Units are the translatable segments in order.
+	Units []*Unit
+
+	data []byte
+}
+
+// Parse parses the XLIFF data.
+func Parse(data []byte) (*Document, error) {
+	parser := &parser{
+		doc:     &Document{data: data},
+		decoder: xml.NewDecoder(bytes.NewReader(data)),
+	}
+
+	if err := parser.parse(); err != nil {
+		return nil, deepl.WrapIfErr(err, "failed to parse XLIFF")
+	}
+
+	if parser.doc.Version == "" {
+		return nil, deepl.NewErr("failed to parse XLIFF: no xliff element with the version")
+	}
+
+	return parser.doc, nil
+}
+
+// ParseFile reads and parses the XLIFF file.
+func ParseFile(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, deepl.WrapIfErr(err, "failed to read XLIFF file")
+	}
+
+	return Parse(data)
+}
+
+// IsV2 returns true if the document is XLIFF 2.x.
+func (d *Document) IsV2() bool {
+	return strings.HasPrefix(d.Version, "2")
+}
+
+// Encode returns the XLIFF data with the changes of the targets and the states
+// of the units.
+func (d *Document) Encode() []byte {
+	var edits []edit
+
+	for _, unit := range d.Units {
+		stateChanged := unit.State != unit.orig.state || unit.SubState != unit.orig.subState
+		targetChanged := unit.Target != unit.orig.target
+
+		if !stateChanged && !targetChanged {
+			continue
+		}
+
+		// The state attribute is in the <target> tag for XLIFF 1.2
+		if d.IsV2() && stateChanged {
+			tag := string(d.data[unit.orig.stateTagStart:unit.orig.stateTagEnd])
+			tag = setAttr(tag, "state", unit.State)
+			tag = setAttr(tag, "subState", unit.SubState)
+
+			edits = append(edits, edit{start: unit.orig.stateTagStart, end: unit.orig.stateTagEnd, text: tag})
+		}
+
+		if !targetChanged && (d.IsV2() || !unit.HasTarget) {
+			continue
+		}
+
+		edits = append(edits, d.targetEdit(unit))
+	}
+
+	if len(edits) == 0 {
+		return append([]byte(nil), d.data...)
+	}
+
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+
+	var buf bytes.Buffer
+
+	last := 0
+
+	for _, e := range edits {
+		buf.Write(d.data[last:e.start])
+		buf.WriteString(e.text)
+
+		last = e.end
+	}
+
+	buf.Write(d.data[last:])
+
+	return buf.Bytes()
+}
+
+// WriteFile writes the XLIFF data to the path.
+func (d *Document) WriteFile(path string) error {
+	return deepl.WrapIfErr(os.WriteFile(path, d.Encode(), 0o644), "failed to write XLIFF file")
+}
+
+// targetEdit returns the edit to replace or insert the <target> of the unit.
+func (d *Document) targetEdit(unit *Unit) edit {
+	tag := unit.orig.targetTag
+	if tag == "" {
+		tag = "<target>"
+	}
+
+	if !d.IsV2() {
+		tag = setAttr(tag, "state", unit.State)
+		tag = setAttr(tag, "state-qualifier", unit.SubState)
+	}
+
+	element := tag + unit.Target + "</target>"
+
+	if unit.orig.targetStart >= 0 {
+		return edit{start: unit.orig.targetStart, end: unit.orig.targetEnd, text: element}
+	}
+
+	// Insert after the <source> with the same indent
+	element = indentOf(d.data, unit.orig.sourceStart) + element
+
+	return edit{start: unit.orig.sourceEnd, end: unit.orig.sourceEnd, text: element}
+}
+
+// edit is a replacement of the range of the data.
+type edit struct {
+	text  string
+	start int
+	end   int
+}
+
+// ----------------------------------------------------------------------------
+//  Type: parser
+// ----------------------------------------------------------------------------
+
+// parser is the state of Parse.
+type parser struct {
+	doc     *Document
+	decoder *xml.Decoder
+	// unit is the unit being parsed.
+	unit *Unit
+	// unitDepth is the depth of the <trans-unit> or the <segment> of the unit.
+	// Only its direct children are captured as the source and the target. Such
+	// as not the ones in the <alt-trans>.
+	unitDepth int
+	// unitID and unitNoTranslate are of the <unit> of XLIFF 2.0.
+	unitID          string
+	unitNoTranslate bool
+	// capture is the name of the element whose inner XML is being captured.
+	// Such as "source" and "target".
+	capture      string
+	captureStart int
+	captureDepth int
+	depth        int
+}
+
+// parse parses the tokens of the data.
+func (p *parser) parse() error {
+	for {
+		start := int(p.decoder.InputOffset())
+
+		token, err := p.decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		end := int(p.decoder.InputOffset())
+
+		switch tok := token.(type) {
+		case xml.StartElement:
+			p.depth++
+			p.startElement(tok, start, end)
+		case xml.EndElement:
+			p.endElement(tok, start, end)
+			p.depth--
+		}
+	}
+}
+
+// startElement handles the start tag in the range of the data.
+func (p *parser) startElement(tok xml.StartElement, start, end int) {
+	if p.capture != "" {
+		return
+	}
+
+	switch tok.Name.Local {
+	case "xliff":
+		p.doc.Version = attr(tok, "version")
+		p.doc.SourceLanguage = attr(tok, "srcLang")
+		p.doc.TargetLanguage = attr(tok, "trgLang")
+	case "file":
+		if p.doc.SourceLanguage == "" {
+			p.doc.SourceLanguage = attr(tok, "source-language")
+			p.doc.TargetLanguage = attr(tok, "target-language")
+		}
+	case "trans-unit":
+		p.unit = p.newUnit(attr(tok, "id"), attr(tok, "translate") == "no")
+		p.unitDepth = p.depth
+	case "unit":
+		p.unitID = attr(tok, "id")
+		p.unitNoTranslate = attr(tok, "translate") == "no"
+	case "segment":
+		id := p.unitID
+		if segmentID := attr(tok, "id"); segmentID != "" {
+			id += "/" + segmentID
+		}
+
+		p.unit = p.newUnit(id, p.unitNoTranslate || attr(tok, "translate") == "no")
+		p.unitDepth = p.depth
+		p.unit.State = attr(tok, "state")
+		p.unit.SubState = attr(tok, "subState")
+		p.unit.orig.stateTagStart = start
+		p.unit.orig.stateTagEnd = end
+	case "source", "target":
+		if p.unit == nil || p.depth != p.unitDepth+1 {
+			return
+		}
+
+		p.capture = tok.Name.Local
+		p.captureStart = end
+		p.captureDepth = p.depth
+
+		if tok.Name.Local == "source" {
+			p.unit.orig.sourceStart = start
+
+			return
+		}
+
+		p.unit.HasTarget = true
+		p.unit.orig.targetStart = start
+		p.unit.orig.targetTag = strings.TrimSuffix(strings.TrimSuffix(string(p.doc.data[start:end]), ">"), "/") + ">"
+
+		if !p.doc.IsV2() {
+			p.unit.State = attr(tok, "state")
+			p.unit.SubState = attr(tok, "state-qualifier")
+			p.unit.orig.stateTagStart = start
+			p.unit.orig.stateTagEnd = end
+		}
+	}
+}
+
+// endElement handles the end tag in the range of the data.
+func (p *parser) endElement(tok xml.EndElement, start, end int) {
+	if p.capture != "" {
+		if p.depth != p.captureDepth || tok.Name.Local != p.capture {
+			return
+		}
+
+		inner := string(p.doc.data[p.captureStart:start])
+
+		if p.capture == "source" {
+			p.unit.Source = inner
+			p.unit.orig.sourceEnd = end
+		} else {
+			p.unit.Target = inner
+			p.unit.orig.targetEnd = end
+		}
+
+		p.capture = ""
+
+		return
+	}
+
+	switch tok.Name.Local {
+	case "trans-unit", "segment":
+		if p.unit != nil && p.depth == p.unitDepth {
+			p.unit.orig.target = p.unit.Target
+			p.unit.orig.state = p.unit.State
+			p.unit.orig.subState = p.unit.SubState
+			p.doc.Units = append(p.doc.Units, p.unit)
+			p.unit = nil
+		}
+	case "unit":
+		p.unitID = ""
+		p.unitNoTranslate = false
+	}
+}
+
+// newUnit returns a new unit without the target.
+func (p *parser) newUnit(id string, noTranslate bool) *Unit {
+	return &Unit{
+		ID:          id,
+		NoTranslate: noTranslate,
+		orig:        unitOffsets{targetStart: -1},
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  Private Functions
+// ----------------------------------------------------------------------------
+
+// attr returns the value of the attribute of the local name.
+func attr(tok xml.StartElement, name string) string {
+	for _, a := range tok.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+
+	return ""
+}
+
+// indentOf returns the newline and the indent of the line at the offset. It
+// returns an empty string if the line has other than the spaces before the
+// offset.
+func indentOf(data []byte, offset int) string {
+	lineStart := bytes.LastIndexByte(data[:offset], '\n') + 1
+
+	indent := string(data[lineStart:offset])
+	if strings.TrimSpace(indent) != "" {
+		return ""
+	}
+
+	return "\n" + indent
+}
+
+// setAttr returns the start tag with the attribute set. The attribute is removed
+// if the value is empty.
+func setAttr(tag string, name string, value string) string {
+	pattern := regexp.MustCompile(`\s` + regexp.QuoteMeta(name) + `\s*=\s*("[^"]*"|'[^']*')`)
+
+	escaped := xmlEscape(value)
+
+	if pattern.MatchString(tag) {
+		if value == "" {
+			return pattern.ReplaceAllString(tag, "")
+		}
+
+		return pattern.ReplaceAllLiteralString(tag, ` `+name+`="`+escaped+`"`)
+	}
+
+	if value == "" {
+		return tag
+	}
+
+	closing := ">"
+	if strings.HasSuffix(tag, "/>") {
+		closing = "/>"
+	}
+
+	return strings.TrimRight(strings.TrimSuffix(tag, closing), " \t\r\n") + ` ` + name + `="` + escaped + `"` + closing
+}
+
+// xmlEscape returns the text with the XML special characters escaped.
+func xmlEscape(text string) string {
+	var buf bytes.Buffer
+
+	_ = xml.EscapeText(&buf, []byte(text)) // never fails on bytes.Buffer
+
+	return buf.String()
+}
@@ -0,0 +1,122 @@
+package xliff_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/KEINOS/go-deepl/deepl/xliff"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFile_v12(t *testing.T) {
+	t.Parallel()
+
+	doc, err := xliff.ParseFile(filepath.Join("testdata", "sample-v12.xlf"))
+	require.NoError(t, err)
+
+	require.Equal(t, "1.2", doc.Version)
+	require.False(t, doc.IsV2())
+	require.Equal(t, "en", doc.SourceLanguage)
+	require.Equal(t, "de", doc.TargetLanguage)
+	require.Len(t, doc.Units, 4)
+
+	require.Equal(t, "greeting", doc.Units[0].ID)
+	require.Equal(t, `Hello, <g id="1">world</g>!`, doc.Units[0].Source)
+	require.False(t, doc.Units[0].HasTarget)
+
+	require.Equal(t, `Line<x id="2"/>break with <ph id="3">&lt;br/&gt;</ph> &amp; more`, doc.Units[1].Source)
+	require.True(t, doc.Units[1].HasTarget, "self-closing target should be detected")
+	require.False(t, doc.Units[1].IsTranslated())
+
+	require.Equal(t, "Fertig", doc.Units[2].Target)
+	require.Equal(t, "translated", doc.Units[2].State)
+	require.True(t, doc.Units[2].IsTranslated())
+
+	require.True(t, doc.Units[3].NoTranslate)
+}
+
+func TestParseFile_v12_alt_trans(t *testing.T) {
+	t.Parallel()
+
+	doc, err := xliff.ParseFile(filepath.Join("testdata", "sample-v12-alt-trans.xlf"))
+	require.NoError(t, err)
+	require.Len(t, doc.Units, 2)
+
+	require.Equal(t, "Hello", doc.Units[0].Source, "source of the alt-trans should be ignored")
+	require.False(t, doc.Units[0].HasTarget, "target of the alt-trans should be ignored")
+	require.Empty(t, doc.Units[0].Target)
+
+	require.Equal(t, "Done", doc.Units[1].Source)
+	require.Equal(t, "Fertig", doc.Units[1].Target)
+	require.True(t, doc.Units[1].IsTranslated())
+}
+
+func TestParseFile_v20(t *testing.T) {
+	t.Parallel()
+
+	doc, err := xliff.ParseFile(filepath.Join("testdata", "sample-v20.xlf"))
+	require.NoError(t, err)
+
+	require.True(t, doc.IsV2())
+	require.Equal(t, "en", doc.SourceLanguage)
+	require.Equal(t, "ja", doc.TargetLanguage)
+	require.Len(t, doc.Units, 3)
+
+	require.Equal(t, "u1/s1", doc.Units[0].ID)
+	require.Equal(t, "initial", doc.Units[0].State)
+	require.Equal(t, `Click <pc id="1">here</pc> to <ph id="2"/>continue.`, doc.Units[0].Source)
+
+	require.Equal(t, "u1/s2", doc.Units[1].ID)
+	require.True(t, doc.Units[1].HasTarget)
+
+	require.Equal(t, "u2", doc.Units[2].ID)
+	require.Equal(t, "reviewed", doc.Units[2].State)
+	require.Equal(t, "レビュー済み", doc.Units[2].Target)
+}
+
+func TestDocument_Encode_unchanged(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"sample-v12.xlf", "sample-v20.xlf"} {
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		require.NoError(t, err)
+
+		doc, err := xliff.Parse(data)
+		require.NoError(t, err)
+
+		require.Equal(t, string(data), string(doc.Encode()), "file: %s", name)
+	}
+}
+
+func TestDocument_Encode_state(t *testing.T) {
+	t.Parallel()
+
+	doc, err := xliff.Parse([]byte(`<xliff version="1.2"><file><body>` +
+		`<trans-unit id="1"><source>Done</source><target state='new' state-qualifier="x">Fertig</target></trans-unit>` +
+		`</body></file></xliff>`))
+	require.NoError(t, err)
+
+	doc.Units[0].State = "final"
+	doc.Units[0].SubState = ""
+
+	require.Equal(t, `<xliff version="1.2"><file><body>`+
+		`<trans-unit id="1"><source>Done</source><target state="final">Fertig</target></trans-unit>`+
+		`</body></file></xliff>`, string(doc.Encode()))
+}
+
+func TestParse_errors(t *testing.T) {
+	t.Parallel()
+
+	for _, input := range []string{
+		`<xliff version="1.2"><file>`,
+		`<xliff version="1.2"><file></body></xliff>`,
+		`<root/>`,
+	} {
+		_, err := xliff.Parse([]byte(input))
+		require.Error(t, err, "input: %s", input)
+	}
+
+	_, err := xliff.ParseFile(filepath.Join(t.TempDir(), "missing.xlf"))
+	require.Error(t, err)
+}